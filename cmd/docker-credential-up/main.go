@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/docker/docker-credential-helpers/credentials"
 
@@ -45,19 +46,22 @@ func main() {
 		os.Exit(0)
 	}
 
-	domain := ""
+	// UP_DOMAIN may contain a comma-separated list of domains.
+	var domains []string
 	if de, ok := os.LookupEnv(domainEnv); ok {
-		u, err := url.Parse(de)
-		if err != nil {
-			fmt.Fprintln(os.Stdout, errInvalidDomain)
-			os.Exit(1)
+		for _, d := range strings.Split(de, ",") {
+			u, err := url.Parse(strings.TrimSpace(d))
+			if err != nil {
+				fmt.Fprintln(os.Stdout, errInvalidDomain)
+				os.Exit(1)
+			}
+			domains = append(domains, u.Hostname())
 		}
-		domain = u.Hostname()
 	}
 
 	// Build credential helper and defer execution to Docker.
 	h := credhelper.New(
-		credhelper.WithDomain(domain),
+		credhelper.WithDomains(domains...),
 		credhelper.WithProfile(os.Getenv(profileEnv)),
 	)
 	credentials.Serve(h)
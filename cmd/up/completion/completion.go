@@ -0,0 +1,101 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completion
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/upbound/up/internal/completion"
+)
+
+const (
+	// predictTimeout bounds how long predictors that query the Upbound API
+	// may take, so that an unreachable API does not hang the shell.
+	predictTimeout = 5 * time.Second
+
+	// filesDirective is printed after the candidates when the shell should
+	// complete file names.
+	filesDirective = ":files"
+)
+
+// Cmd prints a shell completion script.
+type Cmd struct {
+	Shell string `arg:"" enum:"bash,zsh,fish" help:"Shell to print the completion script for. One of bash, zsh or fish."`
+}
+
+// Help prints how to load the completion script.
+func (c *Cmd) Help() string {
+	return `
+Load completions in the current bash shell with:
+
+  source <(up completion bash)
+
+Load completions in the current zsh shell with:
+
+  source <(up completion zsh)
+
+Load completions in the current fish shell with:
+
+  up completion fish | source
+
+Add the same command to the startup file of the shell to load completions in
+every session.`
+}
+
+// Run executes the completion command.
+func (c *Cmd) Run(kongCtx *kong.Context) error {
+	_, err := fmt.Fprint(kongCtx.Stdout, scripts[c.Shell])
+	return err
+}
+
+// CompleteCmd prints the completions of a command line. It is invoked by the
+// completion scripts.
+type CompleteCmd struct {
+	Words []string `arg:"" optional:"" help:"Words of the command line following up, ending with the word to complete."`
+}
+
+// Run executes the complete command. Each candidate is printed on its own
+// line, followed by a tab and its description if it has one.
+func (c *CompleteCmd) Run(kongCtx *kong.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), predictTimeout)
+	defer cancel()
+	r := completion.New(kongCtx.Model, predictors()...).Complete(ctx, c.Words)
+	for _, cd := range r.Candidates {
+		line := cd.Value
+		if d := description(cd.Description); d != "" {
+			line += "\t" + d
+		}
+		fmt.Fprintln(kongCtx.Stdout, line)
+	}
+	if r.Files {
+		fmt.Fprintln(kongCtx.Stdout, filesDirective)
+	}
+	return nil
+}
+
+// description returns the first sentence of the supplied help on a single
+// line.
+func description(help string) string {
+	help = strings.Join(strings.Fields(help), " ")
+	if i := strings.Index(help, ". "); i >= 0 {
+		help = help[:i]
+	}
+	return strings.TrimSuffix(help, ".")
+}
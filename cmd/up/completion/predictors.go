@@ -0,0 +1,225 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completion
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/upbound/up-sdk-go"
+	"github.com/upbound/up-sdk-go/service/accounts"
+	"github.com/upbound/up-sdk-go/service/common"
+	cp "github.com/upbound/up-sdk-go/service/controlplanes"
+	"github.com/upbound/up-sdk-go/service/organizations"
+	"github.com/upbound/up-sdk-go/service/repositories"
+
+	"github.com/upbound/up/internal/completion"
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+)
+
+const (
+	// defaultCacheDir is the default directory of the package cache.
+	defaultCacheDir = "~/.up/cache/"
+
+	cacheDirFlag = "cache-dir"
+)
+
+// predictors returns the predictors of the values of positional arguments
+// and flags tagged with a predictor.
+func predictors() []completion.Option {
+	return []completion.Option{
+		completion.WithPredictor("profiles", profiles),
+		completion.WithPredictor("control-planes", controlPlanes),
+		completion.WithPredictor("repositories", repos),
+		completion.WithPredictor("robots", robots),
+		completion.WithPredictor("packages", packages),
+		completion.WithPredictor("setting-keys", settingKeys),
+		completion.WithPredictor("output-formats", func(context.Context, completion.Args) ([]string, error) {
+			return upterm.Formats(), nil
+		}),
+	}
+}
+
+// upboundClient builds an Upbound context and SDK config from the Upbound
+// flags supplied on the command line. It is only used by predictors that call
+// the Upbound API. Completion must neither write config nor prompt, so the
+// config is read without being migrated on disk, and a nil SDK config is
+// returned if the session of the profile cannot be resolved without
+// prompting, i.e. for its secret store passphrase.
+func upboundClient(a completion.Args) (*upbound.Context, *up.Config, error) {
+	f := upbound.Flags{
+		Profile: a.Flags["profile"],
+		Account: a.Flags["account"],
+	}
+	if d, ok := a.Flags["domain"]; ok {
+		u, err := url.Parse(d)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.Domain = u
+	}
+	upCtx, err := upbound.NewFromFlags(f, upbound.AllowMissingProfile(), upbound.SkipSessionWarning(), upbound.ReadOnly())
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := upCtx.Session(); err != nil {
+		return nil, nil, nil
+	}
+	cfg, err := upCtx.BuildSDKConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return upCtx, cfg, nil
+}
+
+// profiles predicts the names of Upbound profiles. The config file is only
+// read, such that completion neither resolves sessions nor writes config.
+func profiles(context.Context, completion.Args) ([]string, error) {
+	p, err := config.GetDefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	conf, err := config.Extract(config.NewFSSource(config.WithPath(p)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ps, err := conf.GetUpboundProfiles()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ps))
+	for n := range ps {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// settingKeys predicts the keys of settings in the base config of profiles.
+func settingKeys(context.Context, completion.Args) ([]string, error) {
+	ks, err := upbound.SettingKeys()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(ks))
+	for i, k := range ks {
+		names[i] = k.Name
+	}
+	return names, nil
+}
+
+// controlPlanes predicts the names of control planes in the account.
+func controlPlanes(ctx context.Context, a completion.Args) ([]string, error) {
+	upCtx, cfg, err := upboundClient(a)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	cc := cp.NewClient(cfg)
+	cps, err := upbound.ListAll(ctx, upbound.DefaultPageSize, func(ctx context.Context, opts ...common.ListOption) ([]cp.ControlPlaneResponse, int, int, error) {
+		l, err := cc.List(ctx, upCtx.Account, opts...)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return l.ControlPlanes, l.Page, l.Count, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(cps))
+	for i, c := range cps {
+		names[i] = c.ControlPlane.Name
+	}
+	return names, nil
+}
+
+// repos predicts the names of repositories in the account.
+func repos(ctx context.Context, a completion.Args) ([]string, error) {
+	upCtx, cfg, err := upboundClient(a)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	rc := repositories.NewClient(cfg)
+	rs, err := upbound.ListAll(ctx, upbound.DefaultPageSize, func(ctx context.Context, opts ...common.ListOption) ([]repositories.Repository, int, int, error) {
+		l, err := rc.List(ctx, upCtx.Account, opts...)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return l.Repositories, l.Page, l.Count, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(rs))
+	for i, r := range rs {
+		names[i] = r.Name
+	}
+	return names, nil
+}
+
+// robots predicts the names of robots in the account.
+func robots(ctx context.Context, a completion.Args) ([]string, error) {
+	upCtx, cfg, err := upboundClient(a)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	acc, err := accounts.NewClient(cfg).Get(ctx, upCtx.Account)
+	if err != nil {
+		return nil, err
+	}
+	if acc.Account.Type != accounts.AccountOrganization {
+		return nil, nil
+	}
+	rs, err := organizations.NewClient(cfg).ListRobots(ctx, acc.Organization.ID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(rs))
+	for i, r := range rs {
+		names[i] = r.Name
+	}
+	return names, nil
+}
+
+// packages predicts references to packages in the local package cache.
+func packages(_ context.Context, a completion.Args) ([]string, error) {
+	dir := defaultCacheDir
+	if d, ok := a.Flags[cacheDirFlag]; ok {
+		dir = d
+	}
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	c, err := cache.NewLocal(dir)
+	if err != nil {
+		return nil, err
+	}
+	return c.References()
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completion
+
+// scripts are the completion scripts of each supported shell. Each script
+// completes command lines by invoking up __complete with the words of the
+// command line.
+var scripts = map[string]string{
+	"bash": bashScript,
+	"zsh":  zshScript,
+	"fish": fishScript,
+}
+
+const bashScript = `# bash completion for up
+
+__up_complete() {
+    local cur words cword
+    if declare -F _get_comp_words_by_ref >/dev/null 2>&1; then
+        _get_comp_words_by_ref -n =: cur words cword
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+
+    local out line
+    out=$(up __complete -- "${words[@]:1:$cword}" 2>/dev/null) || return
+    COMPREPLY=()
+    while IFS='' read -r line; do
+        case "$line" in
+            '') ;;
+            :files) compopt -o default 2>/dev/null ;;
+            *) COMPREPLY+=("${line%%$'\t'*}") ;;
+        esac
+    done <<< "$out"
+
+    if declare -F __ltrim_colon_completions >/dev/null 2>&1; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+
+complete -F __up_complete up
+`
+
+const zshScript = `#compdef up
+
+__up_complete() {
+    local -a candidates
+    local out line value desc files=0
+    out=$(up __complete -- "${(@)words[2,$CURRENT]}" 2>/dev/null) || return
+    for line in "${(@f)out}"; do
+        case "$line" in
+            '') ;;
+            :files) files=1 ;;
+            *)
+                value="${line%%$'\t'*}"
+                desc=""
+                [[ "$line" == *$'\t'* ]] && desc="${line#*$'\t'}"
+                candidates+=("${value//:/\\:}${desc:+:$desc}")
+                ;;
+        esac
+    done
+
+    if (( ${#candidates} )); then
+        _describe -t up 'up' candidates
+    fi
+    if (( files )); then
+        _files
+    fi
+}
+
+if [[ "${funcstack[1]}" == "_up" ]]; then
+    __up_complete "$@"
+else
+    compdef __up_complete up
+fi
+`
+
+const fishScript = `# fish completion for up
+
+function __up_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    set -l cur (commandline -ct)
+    for line in (up __complete -- $words "$cur" 2>/dev/null)
+        switch $line
+            case ''
+            case ':files'
+                __fish_complete_path "$cur"
+            case '*'
+                echo $line
+        end
+    end
+end
+
+complete -c up -f -a '(__up_complete)'
+`
@@ -0,0 +1,42 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/alecthomas/kong"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+// Cmd contains commands for inspecting layered up configuration.
+type Cmd struct {
+	View viewCmd `cmd:"" help:"View the settings resolved from each layer of configuration."`
+
+	Flags upbound.Flags `embed:""`
+}
+
+// AfterApply constructs and binds Upbound-specific context to any subcommands
+// that have Run() methods that receive it.
+func (c *Cmd) AfterApply(kongCtx *kong.Context) error {
+	// A missing profile is reported rather than failing, as viewing the
+	// configuration is how a misconfigured profile is diagnosed.
+	upCtx, err := upbound.NewFromFlags(c.Flags, upbound.AllowMissingProfile(), upbound.SkipSessionWarning())
+	if err != nil {
+		return err
+	}
+
+	kongCtx.Bind(upCtx)
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	noSettings = "No settings are set"
+)
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *viewCmd) AfterApply(kongCtx *kong.Context) error {
+	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
+	return nil
+}
+
+// viewCmd views the settings resolved from layered configuration.
+type viewCmd struct {
+	Effective bool `help:"View the effective value of every setting, including defaults, and the layer it was resolved from."`
+}
+
+// Run executes the view command.
+func (c *viewCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, upCtx *upbound.Context) error {
+	if c.Effective {
+		return pt.WithHasHeader().WithData(effectiveData(upCtx.Settings)).Render()
+	}
+	data := setData(upCtx.Settings)
+	if len(data) == 1 {
+		p.Println(noSettings)
+		return nil
+	}
+	return pt.WithHasHeader().WithData(data).Render()
+}
+
+// setData returns the table of the supplied settings that are not defaults.
+func setData(settings []upbound.Setting) [][]string {
+	data := [][]string{{"NAME", "VALUE"}}
+	for _, s := range settings {
+		if s.Layer == config.DefaultLayer {
+			continue
+		}
+		data = append(data, []string{s.Name, s.Value})
+	}
+	return data
+}
+
+// effectiveData returns the table of the supplied settings and their origins.
+func effectiveData(settings []upbound.Setting) [][]string {
+	data := [][]string{{"NAME", "VALUE", "ORIGIN", "SOURCE", "ENV"}}
+	for _, s := range settings {
+		data = append(data, []string{s.Name, s.Value, string(s.Layer), s.Source, s.Env})
+	}
+	return data
+}
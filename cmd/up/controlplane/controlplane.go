@@ -38,7 +38,7 @@ func (c *Cmd) AfterApply(kongCtx *kong.Context) error {
 	if err != nil {
 		return err
 	}
-	cfg, err := upCtx.BuildSDKConfig(upCtx.Profile.Session)
+	cfg, err := upCtx.BuildSDKConfig()
 	if err != nil {
 		return err
 	}
@@ -16,46 +16,53 @@ package controlplane
 
 import (
 	"context"
+	"time"
 
 	"github.com/alecthomas/kong"
-	"github.com/pterm/pterm"
+	"k8s.io/apimachinery/pkg/util/duration"
 
 	"github.com/upbound/up-sdk-go/service/common"
 	cp "github.com/upbound/up-sdk-go/service/controlplanes"
 
 	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
 )
 
-const (
-	maxItems = 100
-)
-
-// AfterApply sets default values in command after assignment and validation.
-func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
-	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
-	return nil
-}
-
 // listCmd list control planes in an account on Upbound.
-type listCmd struct{}
+type listCmd struct {
+	upterm.OutputFlags `embed:""`
+}
 
 // Run executes the list command.
-func (c *listCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, cc *cp.Client, upCtx *upbound.Context) error {
-	// TODO(hasheddan): we currently just max out single page size, but we
-	// may opt to support limiting page size and iterating through pages via
-	// flags in the future.
-	cpList, err := cc.List(context.Background(), upCtx.Account, common.WithSize(maxItems))
+func (c *listCmd) Run(kongCtx *kong.Context, cc *cp.Client, upCtx *upbound.Context) error {
+	cps, err := upbound.ListAll(context.Background(), upbound.DefaultPageSize, func(ctx context.Context, opts ...common.ListOption) ([]cp.ControlPlaneResponse, int, int, error) {
+		l, err := cc.List(ctx, upCtx.Account, opts...)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return l.ControlPlanes, l.Page, l.Count, nil
+	})
 	if err != nil {
 		return err
 	}
-	if len(cpList.ControlPlanes) == 0 {
-		p.Printfln("No control planes found in %s", upCtx.Account)
-		return nil
-	}
-	data := make([][]string, len(cpList.ControlPlanes)+1)
-	data[0] = []string{"NAME", "ID", "STATUS"}
-	for i, cp := range cpList.ControlPlanes {
-		data[i+1] = []string{cp.ControlPlane.Name, cp.ControlPlane.ID.String(), string(cp.Status)}
+	return upterm.PrintList(kongCtx.Stdout, c.OutputFlags, cps, upterm.Table[cp.ControlPlaneResponse]{
+		Columns: []upterm.Column[cp.ControlPlaneResponse]{
+			{Header: "NAME", Value: func(r cp.ControlPlaneResponse) string { return r.ControlPlane.Name }},
+			{Header: "ID", Value: func(r cp.ControlPlaneResponse) string { return r.ControlPlane.ID.String() }},
+			{Header: "STATUS", Value: func(r cp.ControlPlaneResponse) string { return string(r.Status) }},
+			{Header: "PERMISSION", Wide: true, Value: func(r cp.ControlPlaneResponse) string { return string(r.Permission) }},
+			{Header: "DESCRIPTION", Wide: true, Value: func(r cp.ControlPlaneResponse) string { return r.ControlPlane.Description }},
+			{Header: "CREATED", Wide: true, Value: func(r cp.ControlPlaneResponse) string { return since(r.ControlPlane.CreatedAt) }},
+		},
+		Name:  func(r cp.ControlPlaneResponse) string { return r.ControlPlane.Name },
+		Empty: "No control planes found in " + upCtx.Account,
+	})
+}
+
+// since returns how long ago the supplied time was, or n/a if it is not set.
+func since(t *time.Time) string {
+	if t == nil {
+		return "n/a"
 	}
-	return pt.WithHasHeader().WithData(data).Render()
+	return duration.HumanDuration(time.Since(*t))
 }
@@ -92,7 +92,7 @@ type installCmd struct {
 
 	r dynamic.NamespaceableResourceInterface
 
-	Package string `arg:"" help:"Reference to the ${package_type}."`
+	Package string `arg:"" predictor:"packages" help:"Reference to the ${package_type}."`
 
 	// NOTE(hasheddan): kong automatically cleans paths tagged with existingfile.
 	Kubeconfig         string        `type:"existingfile" help:"Override default kubeconfig path."`
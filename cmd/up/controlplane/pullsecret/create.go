@@ -16,6 +16,8 @@ package pullsecret
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/pkg/errors"
@@ -53,11 +55,19 @@ func (c *createCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) er
 		c.user, c.pass = tf.AccessID, tf.Token
 	}
 	if c.user == "" || c.pass == "" {
-		if upCtx.Profile.Session == "" {
+		session, err := upCtx.Session()
+		if err != nil {
+			return err
+		}
+		if session == "" {
 			return errors.New(errMissingProfileCreds)
 		}
-		c.user, c.pass = defaultUsername, upCtx.Profile.Session
-		pterm.Warning.WithWriter(kongCtx.Stdout).Printfln("Using temporary user credentials that will expire within 30 days.")
+		c.user, c.pass = defaultUsername, session
+		expiry := fmt.Sprintf("within %s", upbound.HumanDuration(upbound.DefaultSessionLifetime))
+		if e := upCtx.Profile.SessionExpiresAt; e != nil {
+			expiry = fmt.Sprintf("on %s", e.Local().Format(time.RFC1123))
+		}
+		pterm.Warning.WithWriter(kongCtx.Stdout).Printfln("Using temporary user credentials that will expire %s.", expiry)
 	}
 	return nil
 }
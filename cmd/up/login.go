@@ -15,13 +15,15 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
 	"time"
 
@@ -38,26 +40,27 @@ import (
 
 const (
 	defaultTimeout     = 30 * time.Second
+	ssoTimeout         = 5 * time.Minute
 	defaultProfileName = "default"
-	loginPath          = "/v1/login"
 
 	errLoginFailed    = "unable to login"
-	errReadBody       = "unable to read response body"
-	errParseCookieFmt = "unable to parse session cookie: %s"
 	errNoUserOrToken  = "either username or token must be provided"
 	errNoIDInToken    = "token is missing ID"
 	errUpdateConfig   = "unable to update config file"
+	errSecretStoreFmt = "unknown secret store: %s"
+	errNoSecretHelper = "a secret helper must be provided for the helper secret store"
 )
 
 // BeforeApply sets default values in login before assignment and validation.
 func (c *loginCmd) BeforeApply() error { //nolint:unparam
 	c.stdin = os.Stdin
 	c.prompter = input.NewPrompter()
+	c.openURL = openBrowser(os.Stderr)
 	return nil
 }
 
 func (c *loginCmd) AfterApply(kongCtx *kong.Context) error {
-	upCtx, err := upbound.NewFromFlags(c.Flags, upbound.AllowMissingProfile())
+	upCtx, err := upbound.NewFromFlags(c.Flags, upbound.AllowMissingProfile(), upbound.SkipSessionWarning())
 	if err != nil {
 		return err
 	}
@@ -73,7 +76,7 @@ func (c *loginCmd) AfterApply(kongCtx *kong.Context) error {
 		},
 	}
 	kongCtx.Bind(upCtx)
-	if c.Token != "" {
+	if c.Token != "" || c.TokenFile != "" || c.SSO {
 		return nil
 	}
 	if c.Username == "" {
@@ -99,10 +102,23 @@ type loginCmd struct {
 	client   uphttp.Client
 	stdin    io.Reader
 	prompter input.Prompter
+	openURL  func(string) error
 
 	Username string `short:"u" env:"UP_USER" xor:"identifier" help:"Username used to execute command."`
 	Password string `short:"p" env:"UP_PASSWORD" help:"Password for specified user. '-' to read from stdin."`
 	Token    string `short:"t" env:"UP_TOKEN" xor:"identifier" help:"Token used to execute command. '-' to read from stdin."`
+	// NOTE(hasheddan): kong automatically cleans paths tagged with existingfile.
+	TokenFile string `type:"existingfile" xor:"identifier" help:"Path to a token credentials file. The file is used to re-authenticate when the session expires."`
+	SSO       bool   `name:"sso" xor:"identifier" help:"Login with single sign-on in the browser."`
+
+	// Hidden
+	SSOClientID string   `hidden:"" name:"sso-client-id" env:"UP_SSO_CLIENT_ID" help:"OAuth2 client ID used for single sign-on."`
+	SSOIssuer   *url.URL `hidden:"" name:"override-sso-issuer" env:"OVERRIDE_SSO_ISSUER" help:"Overrides the default OpenID provider used for single sign-on."`
+	SSOAuthURL  *url.URL `hidden:"" name:"override-sso-auth-endpoint" env:"OVERRIDE_SSO_AUTH_ENDPOINT" help:"Overrides the default single sign-on authorization endpoint."`
+	SSOTokenURL *url.URL `hidden:"" name:"override-sso-token-endpoint" env:"OVERRIDE_SSO_TOKEN_ENDPOINT" help:"Overrides the default single sign-on token endpoint."`
+
+	SecretStore  string `env:"UP_SECRET_STORE" help:"Where to store the session token. One of plaintext, file or helper. Defaults to the store already used by the profile, or plaintext."`
+	SecretHelper string `env:"UP_SECRET_HELPER" help:"Name of the credential helper used by the helper secret store, i.e. 'pass' for docker-credential-pass."`
 
 	// Common Upbound API configuration
 	Flags upbound.Flags `embed:""`
@@ -110,6 +126,13 @@ type loginCmd struct {
 
 // Run executes the login command.
 func (c *loginCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { // nolint:gocyclo
+	if c.TokenFile != "" {
+		tf, err := upbound.TokenFromPath(c.TokenFile)
+		if err != nil {
+			return errors.Wrap(err, errLoginFailed)
+		}
+		c.Token = tf.Token
+	}
 	if c.Token == "-" {
 		b, err := io.ReadAll(c.stdin)
 		if err != nil {
@@ -124,36 +147,19 @@ func (c *loginCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { // n
 		}
 		c.Password = strings.TrimSpace(string(b))
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
-	auth, profType, err := constructAuth(c.Username, c.Token, c.Password)
-	if err != nil {
-		return errors.Wrap(err, errLoginFailed)
-	}
-	jsonStr, err := json.Marshal(auth)
-	if err != nil {
-		return errors.Wrap(err, errLoginFailed)
-	}
-	upCtx.APIEndpoint.Path = loginPath
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upCtx.APIEndpoint.String(), bytes.NewReader(jsonStr))
-	if err != nil {
-		return errors.Wrap(err, errLoginFailed)
+	login := c.login
+	if c.SSO {
+		login = c.loginSSO
 	}
-	req.Header.Set("Content-Type", "application/json")
-	res, err := c.client.Do(req)
-	if err != nil {
-		return errors.Wrap(err, errLoginFailed)
-	}
-	defer res.Body.Close() // nolint:errcheck
-	session, err := extractSession(res, upbound.CookieName)
+	id, profType, cookie, err := login(upCtx)
 	if err != nil {
 		return errors.Wrap(err, errLoginFailed)
 	}
 	// If no account is specified and profile type is user, set profile account
 	// to user ID if not an email address. This is for convenience if a user is
 	// using a personal account.
-	if upCtx.Account == "" && profType == config.UserProfileType && !isEmail(auth.ID) {
-		upCtx.Account = auth.ID
+	if upCtx.Account == "" && profType == config.UserProfileType && !isEmail(id) {
+		upCtx.Account = id
 	}
 
 	// If profile name was not provided and no default exists, set name to 'default'.
@@ -161,10 +167,18 @@ func (c *loginCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { // n
 		upCtx.ProfileName = defaultProfileName
 	}
 
-	upCtx.Profile.ID = auth.ID
+	if err := c.setSecretStore(upCtx); err != nil {
+		return errors.Wrap(err, errLoginFailed)
+	}
+
+	upCtx.Profile.ID = id
 	upCtx.Profile.Type = profType
-	upCtx.Profile.Session = session
 	upCtx.Profile.Account = upCtx.Account
+	upbound.SetSession(&upCtx.Profile, cookie, time.Now())
+	upCtx.Profile.TokenSource = nil
+	if c.TokenFile != "" {
+		upCtx.Profile.TokenSource = &config.TokenSource{File: c.TokenFile}
+	}
 
 	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(upCtx.ProfileName, upCtx.Profile); err != nil {
 		return errors.Wrap(err, errLoginFailed)
@@ -175,15 +189,89 @@ func (c *loginCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error { // n
 	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
 		return errors.Wrap(err, errUpdateConfig)
 	}
-	p.Printfln("%s logged in", auth.ID)
+	p.Printfln("%s logged in", id)
+	return nil
+}
+
+// login authenticates with the supplied username and password or token.
+func (c *loginCmd) login(upCtx *upbound.Context) (string, config.ProfileType, *http.Cookie, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	auth, profType, err := constructAuth(c.Username, c.Token, c.Password)
+	if err != nil {
+		return "", "", nil, err
+	}
+	cookie, err := upbound.Login(ctx, c.client, upCtx.APIEndpoint, auth.ID, auth.Password)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return auth.ID, profType, cookie, nil
+}
+
+// loginSSO authenticates a user with single sign-on in the browser. The user
+// is identified by the verified ID token, and the access token that is
+// obtained is used as the session.
+func (c *loginCmd) loginSSO(upCtx *upbound.Context) (string, config.ProfileType, *http.Cookie, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ssoTimeout)
+	defer cancel()
+	clientID := c.SSOClientID
+	if clientID == "" {
+		clientID = upbound.DefaultSSOClientID
+	}
+	res, err := upCtx.LoginSSO(ctx, upbound.SSOConfig{
+		ClientID: clientID,
+		Issuer:   c.SSOIssuer,
+		AuthURL:  c.SSOAuthURL,
+		TokenURL: c.SSOTokenURL,
+		OpenURL:  c.openURL,
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+	return res.ID, config.UserProfileType, &http.Cookie{
+		Name:    upbound.CookieName,
+		Value:   res.Token.AccessToken,
+		Expires: res.Token.Expiry,
+	}, nil
+}
+
+// setSecretStore sets the secret store that the session of the profile is
+// written to if one was supplied. A session held by a store the profile no
+// longer uses is erased.
+func (c *loginCmd) setSecretStore(upCtx *upbound.Context) error {
+	if c.SecretStore == "" {
+		return nil
+	}
+	ref := &config.SecretRef{Store: config.SecretStoreType(c.SecretStore)}
+	switch ref.Store {
+	case config.PlaintextSecretStoreType, config.FileSecretStoreType:
+	case config.HelperSecretStoreType:
+		if c.SecretHelper == "" {
+			return errors.New(errNoSecretHelper)
+		}
+		ref.Helper = c.SecretHelper
+	default:
+		return errors.Errorf(errSecretStoreFmt, c.SecretStore)
+	}
+	old := upCtx.Profile.SessionRef
+	if old != nil && old.Store == ref.Store && old.Helper == ref.Helper {
+		return nil
+	}
+	if old != nil {
+		prof, err := upCtx.CfgSrc.RemoveSession(upCtx.Profile)
+		if err != nil {
+			return err
+		}
+		upCtx.Profile = prof
+	}
+	upCtx.Profile.SessionRef = ref
 	return nil
 }
 
-// auth is the request body sent to authenticate a user or token.
+// auth is the set of credentials used to authenticate a user or token.
 type auth struct {
-	ID       string `json:"id"`
-	Password string `json:"password"`
-	Remember bool   `json:"remember"`
+	ID       string
+	Password string
 }
 
 // constructAuth constructs the body of an Upbound Cloud authentication request
@@ -202,7 +290,6 @@ func constructAuth(username, token, password string) (*auth, config.ProfileType,
 	return &auth{
 		ID:       id,
 		Password: password,
-		Remember: true,
 	}, profType, nil
 }
 
@@ -223,22 +310,27 @@ func parseID(user, token string) (string, config.ProfileType, error) {
 	return user, config.UserProfileType, nil
 }
 
-// extractSession extracts the specified cookie from an HTTP response. The
-// caller is responsible for closing the response body.
-func extractSession(res *http.Response, cookieName string) (string, error) {
-	for _, cook := range res.Cookies() {
-		if cook.Name == cookieName {
-			return cook.Value, nil
-		}
-	}
-	b, err := io.ReadAll(res.Body)
-	if err != nil {
-		return "", errors.Wrap(err, errReadBody)
-	}
-	return "", errors.Errorf(errParseCookieFmt, string(b))
-}
-
 // isEmail determines if the specified username is an email address.
 func isEmail(user string) bool {
 	return strings.Contains(user, "@")
 }
+
+// openBrowser returns a function that prints the supplied URL to the writer
+// and attempts to open it in the default browser of the user. Failing to open
+// the browser is not an error as the user may visit the URL themselves.
+func openBrowser(w io.Writer) func(string) error {
+	return func(u string) error {
+		fmt.Fprintf(w, "Opening %s in your browser to login.\n", u)
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", u)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+		default:
+			cmd = exec.Command("xdg-open", u)
+		}
+		_ = cmd.Start()
+		return nil
+	}
+}
@@ -36,12 +36,12 @@ const (
 
 // AfterApply sets default values in login after assignment and validation.
 func (c *logoutCmd) AfterApply(kongCtx *kong.Context) error {
-	upCtx, err := upbound.NewFromFlags(c.Flags)
+	upCtx, err := upbound.NewFromFlags(c.Flags, upbound.SkipSessionWarning())
 	if err != nil {
 		return err
 	}
 	kongCtx.Bind(upCtx)
-	cfg, err := upCtx.BuildSDKConfig(upCtx.Profile.Session)
+	cfg, err := upCtx.BuildSDKConfig()
 	if err != nil {
 		return err
 	}
@@ -69,7 +69,11 @@ func (c *logoutCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
 		return errors.Wrap(err, errLogoutFailed)
 	}
 	// Logout is successful, remove token from config and update.
-	upCtx.Profile.Session = ""
+	prof, err := upCtx.CfgSrc.RemoveSession(upCtx.Profile)
+	if err != nil {
+		return errors.Wrap(err, errRemoveTokenFailed)
+	}
+	upCtx.Profile = prof
 	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(upCtx.ProfileName, upCtx.Profile); err != nil {
 		return errors.Wrap(err, errRemoveTokenFailed)
 	}
@@ -23,9 +23,12 @@ import (
 	"github.com/alecthomas/kong"
 	"github.com/pterm/pterm"
 
+	"github.com/upbound/up/cmd/up/completion"
+	upconfig "github.com/upbound/up/cmd/up/config"
 	"github.com/upbound/up/cmd/up/controlplane"
 	"github.com/upbound/up/cmd/up/organization"
 	"github.com/upbound/up/cmd/up/profile"
+	"github.com/upbound/up/cmd/up/registry"
 	"github.com/upbound/up/cmd/up/repository"
 	"github.com/upbound/up/cmd/up/robot"
 	"github.com/upbound/up/cmd/up/upbound"
@@ -87,18 +90,23 @@ type cli struct {
 
 	License licenseCmd `cmd:"" help:"Print Up license information."`
 
+	Completion completion.Cmd         `cmd:"" help:"Print a shell completion script."`
+	Complete   completion.CompleteCmd `cmd:"" name:"__complete" hidden:"" help:"Complete a command line."`
+
 	Login        loginCmd         `cmd:"" help:"Login to Upbound."`
 	Logout       logoutCmd        `cmd:"" help:"Logout of Upbound."`
+	Config       upconfig.Cmd     `cmd:"" help:"Interact with layered up configuration."`
 	ControlPlane controlplane.Cmd `cmd:"" name:"controlplane" aliases:"ctp" help:"Interact with control planes."`
 	Organization organization.Cmd `cmd:"" name:"organization" aliases:"org" help:"Interact with organizations."`
 	Profile      profile.Cmd      `cmd:"" help:"Interact with Upbound profiles."`
+	Registry     registry.Cmd     `cmd:"" help:"Interact with package registries."`
 	Repository   repository.Cmd   `cmd:"" name:"repository" aliases:"repo" help:"Interact with repositories."`
 	Robot        robot.Cmd        `cmd:"" name:"robot" help:"Interact with robots."`
 	Upbound      upbound.Cmd      `cmd:"" maturity:"alpha" help:"Interact with Upbound."`
 	UXP          uxp.Cmd          `cmd:"" help:"Interact with UXP."`
 	XPKG         xpkg.Cmd         `cmd:"" help:"Interact with UXP packages."`
 	XPLS         xpls.Cmd         `cmd:"" help:"Start xpls language server."`
-	Alpha        alpha            `cmd:"" help:"Alpha features. Commands may be removed in future releases."`
+	Alpha        alpha            `cmd:"" selects-maturity:"alpha" help:"Alpha features. Commands may be removed in future releases."`
 }
 
 // BeforeReset runs before all other hooks. If command has alpha as an ancestor,
@@ -16,38 +16,34 @@ package organization
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/alecthomas/kong"
-	"github.com/pterm/pterm"
 
 	"github.com/upbound/up-sdk-go/service/organizations"
 
-	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
 )
 
-// AfterApply sets default values in command after assignment and validation.
-func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
-	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
-	return nil
-}
-
 // listCmd lists organizations on Upbound.
-type listCmd struct{}
+type listCmd struct {
+	upterm.OutputFlags `embed:""`
+}
 
 // Run executes the list command.
-func (c *listCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, oc *organizations.Client, upCtx *upbound.Context) error {
+func (c *listCmd) Run(kongCtx *kong.Context, oc *organizations.Client) error {
 	orgs, err := oc.List(context.Background())
 	if err != nil {
 		return err
 	}
-	if len(orgs) == 0 {
-		p.Printfln("No organizations found.")
-		return nil
-	}
-	data := make([][]string, len(orgs)+1)
-	data[0] = []string{"NAME", "ROLE"}
-	for i, o := range orgs {
-		data[i+1] = []string{o.Name, string(o.Role)}
-	}
-	return pt.WithHasHeader().WithData(data).Render()
+	return upterm.PrintList(kongCtx.Stdout, c.OutputFlags, orgs, upterm.Table[organizations.Organization]{
+		Columns: []upterm.Column[organizations.Organization]{
+			{Header: "NAME", Value: func(o organizations.Organization) string { return o.Name }},
+			{Header: "ROLE", Value: func(o organizations.Organization) string { return string(o.Role) }},
+			{Header: "DISPLAY NAME", Wide: true, Value: func(o organizations.Organization) string { return o.DisplayName }},
+			{Header: "ID", Wide: true, Value: func(o organizations.Organization) string { return strconv.FormatUint(uint64(o.ID), 10) }},
+		},
+		Name:  func(o organizations.Organization) string { return o.Name },
+		Empty: "No organizations found.",
+	})
 }
@@ -18,4 +18,5 @@ package config
 type Cmd struct {
 	Set   setCmd   `cmd:"" help:"Set base configuration key, value pair in the Upbound Profile."`
 	UnSet unsetCmd `cmd:"" name:"unset" help:"Unset base configuration key, value pair in the Upbound Profile."`
+	Keys  keysCmd  `cmd:"" help:"List the keys that may be set in the base configuration of an Upbound Profile."`
 }
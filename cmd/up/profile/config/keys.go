@@ -0,0 +1,55 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *keysCmd) AfterApply(kongCtx *kong.Context) error {
+	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
+	return nil
+}
+
+// keysCmd lists the keys that may be set in the base config of a profile.
+type keysCmd struct {
+	NameOnly bool `name:"name-only" help:"Only print the names of the keys, i.e. for shell completion."`
+}
+
+// Run executes the keys command.
+func (c *keysCmd) Run(pt *pterm.TablePrinter, ctx *kong.Context) error {
+	keys, err := upbound.SettingKeys()
+	if err != nil {
+		return err
+	}
+	if c.NameOnly {
+		for _, k := range keys {
+			fmt.Fprintln(ctx.Stdout, k.Name)
+		}
+		return nil
+	}
+	data := make([][]string, len(keys)+1)
+	data[0] = []string{"NAME", "KEY", "TYPE", "DESCRIPTION"}
+	for i, k := range keys {
+		data[i+1] = []string{k.Name, k.Key, k.Type, k.Help}
+	}
+	return pt.WithHasHeader().WithData(data).Render()
+}
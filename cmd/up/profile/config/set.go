@@ -32,7 +32,7 @@ const (
 )
 
 type setCmd struct {
-	Key   string `arg:"" optional:"" help:"Configuration Key."`
+	Key   string `arg:"" optional:"" predictor:"setting-keys" help:"Configuration Key. See 'up profile config keys' for valid keys."`
 	Value string `arg:"" optional:"" help:"Configuration Value."`
 
 	File *os.File `short:"f" help:"Configuration File. Must be in JSON format."`
@@ -90,9 +90,19 @@ func mapFromFile(f *os.File) (map[string]any, error) {
 	return cfg, nil
 }
 
+// addConfigs adds the supplied settings to the base config of the profile
+// under their canonical keys. No settings are added if any are invalid.
 func (c *setCmd) addConfigs(upCtx *upbound.Context, profile string, config map[string]any) error {
+	values := make(map[string]string, len(config))
 	for k, v := range config {
-		if err := upCtx.Cfg.AddToBaseConfig(profile, k, fmt.Sprintf("%v", v)); err != nil {
+		values[k] = fmt.Sprintf("%v", v)
+	}
+	settings, err := upbound.CanonicalSettings(values)
+	if err != nil {
+		return err
+	}
+	for k, v := range settings {
+		if err := upCtx.Cfg.AddToBaseConfig(profile, k, v); err != nil {
 			return err
 		}
 	}
@@ -21,7 +21,11 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
 )
 
 func TestSetValidateInput(t *testing.T) {
@@ -95,3 +99,71 @@ func TestSetValidateInput(t *testing.T) {
 		})
 	}
 }
+
+func TestSetAddConfigs(t *testing.T) {
+	type want struct {
+		base map[string]string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		config map[string]any
+		want   want
+	}{
+		"CanonicalKeys": {
+			reason: "Settings should be stored under their canonical keys.",
+			config: map[string]any{
+				"account":                  "my-org",
+				"insecure_skip_tls_verify": true,
+			},
+			want: want{
+				base: map[string]string{
+					"UP_ACCOUNT":                  "my-org",
+					"UP_INSECURE_SKIP_TLS_VERIFY": "true",
+				},
+			},
+		},
+		"UnknownKey": {
+			reason: "No settings should be stored if a key is not a setting.",
+			config: map[string]any{
+				"account": "my-org",
+				"acount":  "my-org",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"InvalidValue": {
+			reason: "No settings should be stored if a value is not valid for its setting.",
+			config: map[string]any{
+				"account":                  "my-org",
+				"insecure-skip-tls-verify": "maybe",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			upCtx := &upbound.Context{
+				Cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"default": {}},
+					},
+				},
+			}
+
+			err := (&setCmd{}).addConfigs(upCtx, "default", tc.config)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAddConfigs(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.base, upCtx.Cfg.Upbound.Profiles["default"].BaseConfig); diff != "" {
+				t.Errorf("\n%s\nAddConfigs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
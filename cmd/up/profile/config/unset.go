@@ -23,7 +23,7 @@ import (
 )
 
 type unsetCmd struct {
-	Key string `arg:"" optional:"" help:"Configuration Key."`
+	Key string `arg:"" optional:"" predictor:"setting-keys" help:"Configuration Key. See 'up profile config keys' for valid keys."`
 
 	File *os.File `short:"f" help:"Configuration File. Must be in JSON format."`
 }
@@ -65,8 +65,22 @@ func (c *unsetCmd) validateInput() error {
 	return errors.New(errOnlyKVFileXOR)
 }
 
+// removeConfigs removes the supplied settings from the base config of the
+// profile. Keys that are not settings may have been set by earlier versions of
+// up, so they are removed as is if they are present.
 func (c *unsetCmd) removeConfigs(upCtx *upbound.Context, profile string, config map[string]any) error {
+	base, err := upCtx.Cfg.GetBaseConfig(profile)
+	if err != nil {
+		return err
+	}
 	for k := range config {
+		key, err := upbound.ResolveSettingKey(k)
+		if err == nil {
+			k = key.Key
+		}
+		if _, ok := base[k]; err != nil && !ok {
+			return err
+		}
 		if err := upCtx.Cfg.RemoveFromBaseConfig(profile, k); err != nil {
 			return err
 		}
@@ -21,7 +21,11 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
 )
 
 func TestUnsetValidateInput(t *testing.T) {
@@ -82,3 +86,78 @@ func TestUnsetValidateInput(t *testing.T) {
 		})
 	}
 }
+
+func TestUnsetRemoveConfigs(t *testing.T) {
+	type want struct {
+		base map[string]string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		config map[string]any
+		want   want
+	}{
+		"CanonicalKey": {
+			reason: "A setting should be removed by any of its keys.",
+			config: map[string]any{
+				"account": 0,
+			},
+			want: want{
+				base: map[string]string{
+					"acount": "typo",
+				},
+			},
+		},
+		"LegacyKey": {
+			reason: "A key that is not a setting should be removed if it is present.",
+			config: map[string]any{
+				"acount": 0,
+			},
+			want: want{
+				base: map[string]string{
+					"UP_ACCOUNT": "my-org",
+				},
+			},
+		},
+		"UnknownKey": {
+			reason: "A key that is neither a setting nor present should return an error.",
+			config: map[string]any{
+				"acct": 0,
+			},
+			want: want{
+				base: map[string]string{
+					"UP_ACCOUNT": "my-org",
+					"acount":     "typo",
+				},
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			upCtx := &upbound.Context{
+				Cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"default": {
+							BaseConfig: map[string]string{
+								"UP_ACCOUNT": "my-org",
+								"acount":     "typo",
+							},
+						}},
+					},
+				},
+			}
+
+			err := (&unsetCmd{}).removeConfigs(upCtx, "default", tc.config)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRemoveConfigs(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.base, upCtx.Cfg.Upbound.Profiles["default"].BaseConfig); diff != "" {
+				t.Errorf("\n%s\nRemoveConfigs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errProfileExistsFmt = "profile already exists with identifier: %s"
+)
+
+// createCmd creates a profile without logging in, i.e. so that its domain and
+// account are set before its first login.
+type createCmd struct {
+	Name string             `arg:"" required:"" help:"Name of the Profile to create."`
+	ID   string             `required:"" help:"Username, email, or token ID that the Profile authenticates as."`
+	Type config.ProfileType `default:"user" enum:"user,token" help:"Type of the Profile."`
+	Set  map[string]string  `help:"Base configuration key, value pairs of the Profile, i.e. domain=https://local.upbound.io. See 'up profile config keys' for valid keys."`
+	Use  bool               `help:"Set the Profile as the default."`
+}
+
+// Run executes the create command.
+func (c *createCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	if _, err := upCtx.Cfg.GetUpboundProfile(c.Name); err == nil {
+		return errors.Errorf(errProfileExistsFmt, c.Name)
+	}
+	base, err := upbound.CanonicalSettings(c.Set)
+	if err != nil {
+		return err
+	}
+	prof := config.Profile{
+		ID:   c.ID,
+		Type: c.Type,
+	}
+	if len(base) > 0 {
+		prof.BaseConfig = base
+	}
+	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(c.Name, prof); err != nil {
+		return err
+	}
+	if c.Use {
+		if err := upCtx.Cfg.SetDefaultUpboundProfile(c.Name); err != nil {
+			return err
+		}
+	}
+	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
+		return errors.Wrap(err, errUpdateProfile)
+	}
+	p.Printfln("%s created", c.Name)
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errEraseSession = "unable to erase session of deleted profile"
+)
+
+type deleteCmd struct {
+	Name string `arg:"" required:"" predictor:"profiles" help:"Name of the Profile to delete."`
+}
+
+// Run executes the delete command.
+func (c *deleteCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	prof, err := upCtx.Cfg.GetUpboundProfile(c.Name)
+	if err != nil {
+		return err
+	}
+	if err := upCtx.Cfg.DeleteUpboundProfile(c.Name); err != nil {
+		return err
+	}
+	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
+		return errors.Wrap(err, errUpdateProfile)
+	}
+	// The session is only erased from its secret store once the profile no
+	// longer references it.
+	if _, err := upCtx.CfgSrc.RemoveSession(prof); err != nil {
+		return errors.Wrap(err, errEraseSession)
+	}
+	p.Printfln("%s deleted", c.Name)
+	return nil
+}
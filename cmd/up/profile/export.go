@@ -0,0 +1,122 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/alecthomas/kong"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errWriteExport = "unable to write exported profiles"
+)
+
+// profileExport is the format that profiles are exported in. It is a subset of
+// the format of the config file, so that either may be imported.
+type profileExport struct {
+	Version int            `json:"version"`
+	Upbound exportedConfig `json:"upbound"`
+}
+
+type exportedConfig struct {
+	Default  string         `json:"default,omitempty"`
+	Profiles map[string]any `json:"profiles"`
+}
+
+// AfterApply sets default values in command after assignment and validation.
+func (c *exportCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+	return nil
+}
+
+type exportCmd struct {
+	fs afero.Fs
+
+	Names  []string `arg:"" optional:"" predictor:"profiles" help:"Names of the Profiles to export. All Profiles are exported if none are supplied."`
+	File   string   `short:"f" type:"path" help:"File to write the exported Profiles to. They are written to stdout if it is not supplied."`
+	Redact bool     `help:"Redact the sessions of the exported Profiles."`
+}
+
+// Run executes the export command.
+func (c *exportCmd) Run(ctx *kong.Context, upCtx *upbound.Context) error {
+	exp, err := c.export(upCtx)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(exp, "", "    ")
+	if err != nil {
+		return err
+	}
+	if c.File == "" {
+		fmt.Fprintln(ctx.Stdout, string(b))
+		return nil
+	}
+	// Exported sessions may be used to authenticate, so the file is only
+	// readable by its owner.
+	return errors.Wrap(afero.WriteFile(c.fs, c.File, append(b, '\n'), 0600), errWriteExport)
+}
+
+// export exports the selected profiles. Sessions held in secret stores are
+// resolved, as references to a local store are not portable, unless they are
+// redacted.
+func (c *exportCmd) export(upCtx *upbound.Context) (*profileExport, error) {
+	names := c.Names
+	if len(names) == 0 {
+		for name := range upCtx.Cfg.Upbound.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	exp := &profileExport{
+		Version: config.CurrentVersion,
+		Upbound: exportedConfig{
+			Profiles: make(map[string]any, len(names)),
+		},
+	}
+	for _, name := range names {
+		prof, err := upCtx.Cfg.GetUpboundProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		if name == upCtx.Cfg.Upbound.Default {
+			exp.Upbound.Default = name
+		}
+		if c.Redact {
+			// Sessions are not resolved from their secret stores, which may
+			// prompt for a passphrase, only to be redacted.
+			if prof.HasSession() {
+				prof.Session = config.RedactedSession
+			}
+			prof.SessionRef = nil
+			exp.Upbound.Profiles[name] = config.RedactedProfile{Profile: prof}
+			continue
+		}
+		prof.Session, err = upCtx.CfgSrc.ResolveSession(prof)
+		if err != nil {
+			return nil, err
+		}
+		prof.SessionRef = nil
+		exp.Upbound.Profiles[name] = prof
+	}
+	return exp, nil
+}
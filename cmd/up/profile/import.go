@@ -0,0 +1,153 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errReadImport         = "unable to read profiles to import"
+	errImportExistsFmt    = "profile already exists with identifier: %s: use --force to overwrite it"
+	errEraseReplacedFmt   = "unable to erase session of replaced profile: %s"
+	errNoProfilesToImport = "no profiles found to import"
+	errImportNotInFileFmt = "profile not found in file with identifier: %s"
+)
+
+type importCmd struct {
+	File  *os.File `arg:"" help:"File to import Profiles from, or - for stdin. Both exported Profiles and config files may be imported."`
+	Names []string `arg:"" optional:"" help:"Names of the Profiles to import. All Profiles in the file are imported if none are supplied."`
+	Force bool     `help:"Overwrite existing Profiles with the same name."`
+}
+
+// Run executes the import command.
+func (c *importCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	defer c.File.Close() // nolint:errcheck
+	b, err := io.ReadAll(c.File)
+	if err != nil {
+		return errors.Wrap(err, errReadImport)
+	}
+	in := &config.Config{}
+	if err := json.Unmarshal(b, in); err != nil {
+		return errors.Wrap(err, errReadImport)
+	}
+	// Profiles may have been exported by an earlier version of up.
+	if _, err := config.Migrate(in, upbound.Migrations()...); err != nil {
+		return err
+	}
+	replaced, err := c.importProfiles(upCtx.Cfg, in)
+	if err != nil {
+		return err
+	}
+	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
+		return errors.Wrap(err, errUpdateProfile)
+	}
+	for name, prof := range replaced {
+		if _, err := upCtx.CfgSrc.RemoveSession(prof); err != nil {
+			return errors.Wrapf(err, errEraseReplacedFmt, name)
+		}
+	}
+	for _, name := range c.names(in) {
+		p.Printfln("%s imported", name)
+	}
+	return nil
+}
+
+// names returns the names of the profiles to import from the supplied config.
+func (c *importCmd) names(in *config.Config) []string {
+	if len(c.Names) > 0 {
+		return c.Names
+	}
+	names := make([]string, 0, len(in.Upbound.Profiles))
+	for name := range in.Upbound.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// importProfiles imports profiles from the supplied config into the current
+// config. It returns the profiles that were replaced, whose sessions may need
+// to be erased from their secret stores. Replaced profiles keep storing their
+// sessions in the secret store they referenced. The default profile of the supplied
+// config is only used if there is no default profile.
+func (c *importCmd) importProfiles(cfg, in *config.Config) (map[string]config.Profile, error) {
+	names := c.names(in)
+	if len(names) == 0 {
+		return nil, errors.New(errNoProfilesToImport)
+	}
+	// Every profile is checked before any is imported so that an import is
+	// never partially applied.
+	replaced := map[string]config.Profile{}
+	for _, name := range names {
+		if _, ok := in.Upbound.Profiles[name]; !ok {
+			return nil, errors.Errorf(errImportNotInFileFmt, name)
+		}
+		existing, err := cfg.GetUpboundProfile(name)
+		if err != nil {
+			continue
+		}
+		if !c.Force {
+			return nil, errors.Errorf(errImportExistsFmt, name)
+		}
+		replaced[name] = existing
+	}
+	for _, name := range names {
+		p := importable(in.Upbound.Profiles[name])
+		if existing, ok := replaced[name]; ok && existing.SessionRef != nil {
+			// The session of a replaced profile is held in the same secret
+			// store as before, rather than in plaintext. An imported session
+			// overwrites the replaced one under the same key, while the
+			// replaced session is erased if none was imported.
+			ref := *existing.SessionRef
+			if p.Session == "" {
+				ref.Key = ""
+			} else {
+				delete(replaced, name)
+			}
+			p.SessionRef = &ref
+		}
+		if err := cfg.AddOrUpdateUpboundProfile(name, p); err != nil {
+			return nil, err
+		}
+		if name == in.Upbound.Default && cfg.Upbound.Default == "" {
+			cfg.Upbound.Default = name
+		}
+	}
+	return replaced, nil
+}
+
+// importable returns the supplied profile without a session if its session was
+// redacted or is held in a secret store, which is not portable.
+func importable(p config.Profile) config.Profile {
+	if p.Session == config.RedactedSession || p.Session == config.NoSession {
+		p.Session = ""
+	}
+	p.SessionRef = nil
+	if p.Session == "" {
+		p.SessionIssuedAt = nil
+		p.SessionExpiresAt = nil
+	}
+	return p
+}
@@ -0,0 +1,275 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/upbound/up/internal/config"
+	"github.com/upbound/up/internal/upbound"
+)
+
+func TestExportImport(t *testing.T) {
+	dev := config.Profile{
+		ID:         "someone@upbound.io",
+		Type:       config.UserProfileType,
+		SessionRef: &config.SecretRef{Store: config.HelperSecretStoreType, Helper: "pass", Key: "up/profiles/dev"},
+		BaseConfig: map[string]string{"UP_DOMAIN": "https://dev.upbound.io"},
+	}
+	prod := config.Profile{
+		ID:      "someone@upbound.io",
+		Type:    config.UserProfileType,
+		Session: "prod-session",
+		Account: "prod-org",
+	}
+	staging := config.Profile{
+		ID:      "someone@upbound.io",
+		Type:    config.UserProfileType,
+		Session: "staging-session",
+	}
+
+	type args struct {
+		export exportCmd
+		imp    importCmd
+		cfg    *config.Config
+	}
+	type want struct {
+		cfg      *config.Config
+		replaced map[string]config.Profile
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Sessions": {
+			reason: "Sessions should be resolved from secret stores when exported and imported in plaintext.",
+			args: args{
+				cfg: &config.Config{},
+			},
+			want: want{
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Default: "dev",
+						Profiles: map[string]config.Profile{
+							"dev": {
+								ID:         "someone@upbound.io",
+								Type:       config.UserProfileType,
+								Session:    "dev-session",
+								BaseConfig: map[string]string{"UP_DOMAIN": "https://dev.upbound.io"},
+							},
+							"prod": prod,
+						},
+					},
+				},
+				replaced: map[string]config.Profile{},
+			},
+		},
+		"Redacted": {
+			reason: "Redacted sessions should not be imported.",
+			args: args{
+				export: exportCmd{Names: []string{"prod"}, Redact: true},
+				cfg: &config.Config{
+					Upbound: config.Upbound{Default: "staging"},
+				},
+			},
+			want: want{
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Default: "staging",
+						Profiles: map[string]config.Profile{
+							"prod": {
+								ID:      "someone@upbound.io",
+								Type:    config.UserProfileType,
+								Account: "prod-org",
+							},
+						},
+					},
+				},
+				replaced: map[string]config.Profile{},
+			},
+		},
+		"ErrorExists": {
+			reason: "Existing profiles should not be overwritten unless forced.",
+			args: args{
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": dev},
+					},
+				},
+			},
+			want: want{
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": dev},
+					},
+				},
+				err: errors.Errorf(errImportExistsFmt, "prod"),
+			},
+		},
+		"Forced": {
+			reason: "Existing profiles should be overwritten and returned if forced.",
+			args: args{
+				imp: importCmd{Names: []string{"prod"}, Force: true},
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": staging},
+					},
+				},
+			},
+			want: want{
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": prod},
+					},
+				},
+				replaced: map[string]config.Profile{"prod": staging},
+			},
+		},
+		"ForcedSecretStore": {
+			reason: "The session of a profile that is overwritten should be stored in the secret store it referenced, under the same key, rather than in plaintext.",
+			args: args{
+				imp: importCmd{Names: []string{"prod"}, Force: true},
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": dev},
+					},
+				},
+			},
+			want: want{
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": {
+							ID:         "someone@upbound.io",
+							Type:       config.UserProfileType,
+							Session:    "prod-session",
+							SessionRef: &config.SecretRef{Store: config.HelperSecretStoreType, Helper: "pass", Key: "up/profiles/dev"},
+							Account:    "prod-org",
+						}},
+					},
+				},
+				replaced: map[string]config.Profile{},
+			},
+		},
+		"ForcedSecretStoreRedacted": {
+			reason: "A profile that is overwritten without a session should keep referencing its secret store, and be returned so that its session is erased.",
+			args: args{
+				export: exportCmd{Names: []string{"prod"}, Redact: true},
+				imp:    importCmd{Names: []string{"prod"}, Force: true},
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": dev},
+					},
+				},
+			},
+			want: want{
+				cfg: &config.Config{
+					Upbound: config.Upbound{
+						Profiles: map[string]config.Profile{"prod": {
+							ID:         "someone@upbound.io",
+							Type:       config.UserProfileType,
+							SessionRef: &config.SecretRef{Store: config.HelperSecretStoreType, Helper: "pass"},
+							Account:    "prod-org",
+						}},
+					},
+				},
+				replaced: map[string]config.Profile{"prod": dev},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			upCtx := &upbound.Context{
+				Cfg: &config.Config{
+					Upbound: config.Upbound{
+						Default:  "dev",
+						Profiles: map[string]config.Profile{"dev": dev, "prod": prod},
+					},
+				},
+				CfgSrc: &config.MockSource{
+					ResolveSessionFn: func(p config.Profile) (string, error) {
+						if p.SessionRef != nil {
+							return "dev-session", nil
+						}
+						return p.Session, nil
+					},
+				},
+			}
+			exp, err := tc.args.export.export(upCtx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := json.Marshal(exp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := &config.Config{}
+			if err := json.Unmarshal(b, in); err != nil {
+				t.Fatal(err)
+			}
+
+			replaced, err := tc.args.imp.importProfiles(tc.args.cfg, in)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nImportProfiles(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.replaced, replaced); diff != "" {
+				t.Errorf("\n%s\nImportProfiles(...): -want replaced, +got replaced:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cfg, tc.args.cfg); diff != "" {
+				t.Errorf("\n%s\nImportProfiles(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExportRedactedNotResolved(t *testing.T) {
+	upCtx := &upbound.Context{
+		Cfg: &config.Config{
+			Upbound: config.Upbound{
+				Profiles: map[string]config.Profile{"dev": {
+					ID:         "someone@upbound.io",
+					Type:       config.UserProfileType,
+					SessionRef: &config.SecretRef{Store: config.FileSecretStoreType, Key: "up/profiles/dev"},
+				}},
+			},
+		},
+		CfgSrc: &config.MockSource{
+			ResolveSessionFn: func(config.Profile) (string, error) {
+				return "", errors.New("sessions should not be resolved to be redacted")
+			},
+		},
+	}
+	exp, err := (&exportCmd{Redact: true}).export(upCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":1,"upbound":{"profiles":{"dev":{"id":"someone@upbound.io","type":"user","session":"REDACTED"}}}}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("\nexport(...): -want, +got:\n%s", diff)
+	}
+}
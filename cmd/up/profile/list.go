@@ -15,65 +15,97 @@
 package profile
 
 import (
+	"encoding/json"
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/pterm/pterm"
 
 	"github.com/upbound/up/internal/config"
 	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
 )
 
-// AfterApply sets default values in command after assignment and validation.
-func (c *listCmd) AfterApply(kongCtx *kong.Context) error {
-	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
-	return nil
+type listCmd struct {
+	upterm.OutputFlags `embed:""`
 }
 
-type listCmd struct{}
+// profileItem is a profile as it is listed. The session is always redacted.
+type profileItem struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+	config.RedactedProfile
+}
+
+// MarshalJSON inlines the redacted profile alongside the name and whether it
+// is the current profile.
+func (i profileItem) MarshalJSON() ([]byte, error) {
+	b, err := i.RedactedProfile.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	m["name"] = i.Name
+	m["current"] = i.Current
+	return json.Marshal(m)
+}
 
 // Run executes the list command.
-func (c *listCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, ctx *kong.Context, upCtx *upbound.Context) error {
+func (c *listCmd) Run(p pterm.TextPrinter, ctx *kong.Context, upCtx *upbound.Context) error {
 	profiles, err := upCtx.Cfg.GetUpboundProfiles()
 	if err != nil {
 		p.Println(errNoProfiles)
 		return nil // nolint:nilerr
 	}
 
-	redacted := make(map[string]config.RedactedProfile)
-	for k, v := range profiles {
-		redacted[k] = config.RedactedProfile{Profile: v}
-	}
-	if len(redacted) == 0 {
-		p.Println(errNoProfiles)
-		return nil
-	}
-
-	// sort the redacted profiles by name so that we have a consistent listing
-	profileNames := make([]string, 0, len(redacted))
-	for k := range redacted {
-		profileNames = append(profileNames, k)
-	}
-	sort.Strings(profileNames)
+	// profiles can exist without a default, in which case none is current.
+	dprofile, _, _ := upCtx.Cfg.GetDefaultUpboundProfile()
 
-	dprofile, _, err := upCtx.Cfg.GetDefaultUpboundProfile()
-	if err != nil {
-		return err
+	items := make([]profileItem, 0, len(profiles))
+	for name, prof := range profiles {
+		items = append(items, profileItem{
+			Name:            name,
+			Current:         name == dprofile,
+			RedactedProfile: config.RedactedProfile{Profile: prof},
+		})
 	}
+	// sort the profiles by name so that we have a consistent listing
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
 
-	data := make([][]string, len(redacted)+1)
-	cursor := ""
-
-	data[0] = []string{"CURRENT", "NAME", "TYPE", "ACCOUNT"}
-	for i, name := range profileNames {
-		if name == dprofile {
-			cursor = "*"
-		}
-		prof := redacted[name]
-		data[i+1] = []string{cursor, name, string(prof.Type), prof.Account}
+	now := time.Now()
+	return upterm.PrintList(ctx.Stdout, c.OutputFlags, items, upterm.Table[profileItem]{
+		Columns: []upterm.Column[profileItem]{
+			{Header: "CURRENT", Value: func(i profileItem) string {
+				if i.Current {
+					return "*"
+				}
+				return ""
+			}},
+			{Header: "NAME", Value: func(i profileItem) string { return i.Name }},
+			{Header: "TYPE", Value: func(i profileItem) string { return string(i.Type) }},
+			{Header: "ACCOUNT", Value: func(i profileItem) string { return i.Account }},
+			{Header: "SESSION", Value: func(i profileItem) string { return sessionValidity(i.Profile, now) }},
+			{Header: "ID", Wide: true, Value: func(i profileItem) string { return i.ID }},
+		},
+		Name:  func(i profileItem) string { return i.Name },
+		Empty: errNoProfiles,
+	})
+}
 
-		cursor = "" // reset cursor
+// sessionValidity describes the validity of the session of the supplied
+// profile at the supplied time, including how long remains until it expires.
+func sessionValidity(p config.Profile, now time.Time) string {
+	status, remaining := p.SessionStatus(now)
+	switch status { // nolint:exhaustive
+	case config.SessionValid, config.SessionExpiring:
+		return fmt.Sprintf("%s (%s)", status, upbound.HumanDuration(remaining))
+	case config.SessionExpired:
+		return fmt.Sprintf("%s (%s ago)", status, upbound.HumanDuration(-remaining))
 	}
-
-	return pt.WithHasHeader().WithData(data).Render()
+	return string(status)
 }
@@ -28,6 +28,11 @@ type Cmd struct {
 	Use     useCmd     `cmd:"" help:"Set the default Upbound Profile to the given Profile."`
 	View    viewCmd    `cmd:"" help:"View the Upbound Profile settings across profiles."`
 	Config  config.Cmd `cmd:"" help:"Interact with the current Upbound Profile's config."`
+	Create  createCmd  `cmd:"" help:"Create an Upbound Profile without logging in."`
+	Rename  renameCmd  `cmd:"" help:"Rename an Upbound Profile."`
+	Delete  deleteCmd  `cmd:"" help:"Delete an Upbound Profile and its session."`
+	Export  exportCmd  `cmd:"" help:"Export Upbound Profiles."`
+	Import  importCmd  `cmd:"" help:"Import Upbound Profiles."`
 
 	Flags upbound.Flags `embed:""`
 }
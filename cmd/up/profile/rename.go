@@ -0,0 +1,67 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errEraseOldSession = "unable to erase session stored under previous profile name"
+)
+
+type renameCmd struct {
+	Name    string `arg:"" required:"" predictor:"profiles" help:"Name of the Profile to rename."`
+	NewName string `arg:"" required:"" help:"New name of the Profile."`
+}
+
+// Run executes the rename command.
+func (c *renameCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	old, err := upCtx.Cfg.GetUpboundProfile(c.Name)
+	if err != nil {
+		return err
+	}
+	if err := upCtx.Cfg.RenameUpboundProfile(c.Name, c.NewName); err != nil {
+		return err
+	}
+
+	// Sessions are held in secret stores under the name of their profile, so
+	// the session is stored again under the new name before the session
+	// stored under the old name is erased.
+	prof := old
+	prof.Session, err = upCtx.CfgSrc.ResolveSession(old)
+	if err != nil {
+		return err
+	}
+	if prof.SessionRef != nil {
+		ref := *prof.SessionRef
+		ref.Key = ""
+		prof.SessionRef = &ref
+	}
+	if err := upCtx.Cfg.AddOrUpdateUpboundProfile(c.NewName, prof); err != nil {
+		return err
+	}
+	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
+		return errors.Wrap(err, errUpdateProfile)
+	}
+	if _, err := upCtx.CfgSrc.RemoveSession(old); err != nil {
+		return errors.Wrap(err, errEraseOldSession)
+	}
+	p.Printfln("%s renamed to %s", c.Name, c.NewName)
+	return nil
+}
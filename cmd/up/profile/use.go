@@ -15,6 +15,11 @@
 package profile
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/kong"
 	"github.com/pkg/errors"
 
 	"github.com/upbound/up/internal/upbound"
@@ -22,17 +27,40 @@ import (
 
 const (
 	errUpdateProfile = "unable to update profile"
+
+	// profileEnv is the environment variable that selects the profile.
+	profileEnv = "UP_PROFILE"
 )
 
+// shellSafe matches values that do not need to be quoted in a shell.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@-]+$`)
+
 type useCmd struct {
-	Name string `arg:"" required:"" help:"Name of the Profile to use."`
+	Name string `arg:"" required:"" predictor:"profiles" help:"Name of the Profile to use."`
+
+	Shell bool `help:"Print a command that uses the Profile in the current shell only, rather than setting the default, i.e. eval \"$(up profile use dev --shell)\"."`
 }
 
 // Run executes the Use command.
-func (c *useCmd) Run(upCtx *upbound.Context) error {
+func (c *useCmd) Run(ctx *kong.Context, upCtx *upbound.Context) error {
+	if c.Shell {
+		if _, err := upCtx.Cfg.GetUpboundProfile(c.Name); err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Stdout, "export %s=%s\n", profileEnv, shellQuote(c.Name))
+		return nil
+	}
 	if err := upCtx.Cfg.SetDefaultUpboundProfile(c.Name); err != nil {
 		return err
 	}
 
 	return errors.Wrap(upCtx.CfgSrc.UpdateConfig(upCtx.Cfg), errUpdateProfile)
 }
+
+// shellQuote quotes the supplied value for a POSIX shell if it is necessary.
+func shellQuote(v string) string {
+	if shellSafe.MatchString(v) {
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
@@ -0,0 +1,39 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]struct {
+		value string
+		want  string
+	}{
+		"Safe":        {value: "dev-us_east.1", want: "dev-us_east.1"},
+		"Space":       {value: "my prod", want: "'my prod'"},
+		"SingleQuote": {value: "dan's", want: `'dan'\''s'`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, shellQuote(tc.value)); diff != "" {
+				t.Errorf("\nshellQuote(%q): -want, +got:\n%s", tc.value, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	dockerconfig "github.com/docker/cli/cli/config"
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	// credHelper is the name of the Docker credential helper for Upbound,
+	// which Docker invokes as docker-credential-up.
+	credHelper = "up"
+
+	errNoProfile          = "no profile is configured: run up login first"
+	errMapRegistry        = "unable to map registry to profile"
+	errUpdateConfig       = "unable to update config file"
+	errLoadDockerConfig   = "unable to load Docker config"
+	errUpdateDockerConfig = "unable to update Docker config"
+)
+
+// loginCmd configures Docker to use the Upbound credential helper for
+// registries, and maps the registries to the current profile.
+type loginCmd struct {
+	Registries []string `arg:"" optional:"" help:"Hosts of the registries to login to. Defaults to the registry of the Upbound domain."`
+}
+
+// Run executes the login command.
+func (c *loginCmd) Run(p pterm.TextPrinter, upCtx *upbound.Context) error {
+	if _, err := upCtx.Cfg.GetUpboundProfile(upCtx.ProfileName); err != nil {
+		return errors.Wrap(err, errNoProfile)
+	}
+	hosts := c.Registries
+	if len(hosts) == 0 {
+		hosts = []string{upCtx.RegistryEndpoint.Host}
+	}
+	for _, h := range hosts {
+		if err := upCtx.Cfg.AddUpboundProfileRegistry(upCtx.ProfileName, h); err != nil {
+			return errors.Wrap(err, errMapRegistry)
+		}
+	}
+	if err := upCtx.CfgSrc.UpdateConfig(upCtx.Cfg); err != nil {
+		return errors.Wrap(err, errUpdateConfig)
+	}
+
+	dc, err := dockerconfig.Load(dockerconfig.Dir())
+	if err != nil {
+		return errors.Wrap(err, errLoadDockerConfig)
+	}
+	if dc.CredentialHelpers == nil {
+		dc.CredentialHelpers = map[string]string{}
+	}
+	for _, h := range hosts {
+		dc.CredentialHelpers[h] = credHelper
+	}
+	if err := dc.Save(); err != nil {
+		return errors.Wrap(err, errUpdateDockerConfig)
+	}
+	for _, h := range hosts {
+		p.Printfln("%s configured to use profile %s", h, upCtx.ProfileName)
+	}
+	return nil
+}
@@ -20,50 +20,58 @@ import (
 	"time"
 
 	"github.com/alecthomas/kong"
-	"github.com/pterm/pterm"
 	"k8s.io/apimachinery/pkg/util/duration"
 
 	"github.com/upbound/up-sdk-go/service/common"
 	"github.com/upbound/up-sdk-go/service/repositories"
 
 	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
 )
 
-const (
-	maxItems = 100
-)
-
-// AfterApply sets default values in command after assignment and validation.
-func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
-	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
-	return nil
-}
-
 // listCmd lists repositories in an account on Upbound.
-type listCmd struct{}
+type listCmd struct {
+	upterm.OutputFlags `embed:""`
+}
 
 // Run executes the list command.
-func (c *listCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, rc *repositories.Client, upCtx *upbound.Context) error {
-	rList, err := rc.List(context.Background(), upCtx.Account, common.WithSize(maxItems))
+func (c *listCmd) Run(kongCtx *kong.Context, rc *repositories.Client, upCtx *upbound.Context) error {
+	rs, err := upbound.ListAll(context.Background(), upbound.DefaultPageSize, func(ctx context.Context, opts ...common.ListOption) ([]repositories.Repository, int, int, error) {
+		l, err := rc.List(ctx, upCtx.Account, opts...)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return l.Repositories, l.Page, l.Count, nil
+	})
 	if err != nil {
 		return err
 	}
-	if len(rList.Repositories) == 0 {
-		p.Printfln("No repositories found in %s", upCtx.Account)
-		return nil
-	}
-	data := make([][]string, len(rList.Repositories)+1)
-	data[0] = []string{"NAME", "TYPE", "PUBLIC", "UPDATED"}
-	for i, r := range rList.Repositories {
-		rt := "unknown"
-		if r.Type != nil {
-			rt = string(*r.Type)
-		}
-		u := "n/a"
-		if r.UpdatedAt != nil {
-			u = duration.HumanDuration(time.Since(*r.UpdatedAt))
-		}
-		data[i+1] = []string{r.Name, rt, strconv.FormatBool(r.Public), u}
-	}
-	return pt.WithHasHeader().WithData(data).Render()
+	return upterm.PrintList(kongCtx.Stdout, c.OutputFlags, rs, upterm.Table[repositories.Repository]{
+		Columns: []upterm.Column[repositories.Repository]{
+			{Header: "NAME", Value: func(r repositories.Repository) string { return r.Name }},
+			{Header: "TYPE", Value: func(r repositories.Repository) string {
+				if r.Type == nil {
+					return "unknown"
+				}
+				return string(*r.Type)
+			}},
+			{Header: "PUBLIC", Value: func(r repositories.Repository) string { return strconv.FormatBool(r.Public) }},
+			{Header: "UPDATED", Value: func(r repositories.Repository) string {
+				if r.UpdatedAt == nil {
+					return "n/a"
+				}
+				return duration.HumanDuration(time.Since(*r.UpdatedAt))
+			}},
+			{Header: "VERSION", Wide: true, Value: func(r repositories.Repository) string {
+				if r.CurrentVersion == nil {
+					return "n/a"
+				}
+				return *r.CurrentVersion
+			}},
+			{Header: "OFFICIAL", Wide: true, Value: func(r repositories.Repository) string { return strconv.FormatBool(r.Official) }},
+			{Header: "CREATED", Wide: true, Value: func(r repositories.Repository) string { return duration.HumanDuration(time.Since(r.CreatedAt)) }},
+		},
+		Name:  func(r repositories.Repository) string { return r.Name },
+		Empty: "No repositories found in " + upCtx.Account,
+	})
 }
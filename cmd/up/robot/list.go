@@ -16,30 +16,27 @@ package robot
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/pkg/errors"
-	"github.com/pterm/pterm"
 	"k8s.io/apimachinery/pkg/util/duration"
 
 	"github.com/upbound/up-sdk-go/service/accounts"
 	"github.com/upbound/up-sdk-go/service/organizations"
 
 	"github.com/upbound/up/internal/upbound"
+	"github.com/upbound/up/internal/upterm"
 )
 
-// AfterApply sets default values in command after assignment and validation.
-func (c *listCmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
-	kongCtx.Bind(pterm.DefaultTable.WithWriter(kongCtx.Stdout).WithSeparator("   "))
-	return nil
+// listCmd lists robots in an account on Upbound.
+type listCmd struct {
+	upterm.OutputFlags `embed:""`
 }
 
-// listCmd creates a robot on Upbound.
-type listCmd struct{}
-
-// Run executes the create command.
-func (c *listCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, ac *accounts.Client, oc *organizations.Client, upCtx *upbound.Context) error {
+// Run executes the list command.
+func (c *listCmd) Run(kongCtx *kong.Context, ac *accounts.Client, oc *organizations.Client, upCtx *upbound.Context) error {
 	a, err := ac.Get(context.Background(), upCtx.Account)
 	if err != nil {
 		return err
@@ -51,14 +48,16 @@ func (c *listCmd) Run(p pterm.TextPrinter, pt *pterm.TablePrinter, ac *accounts.
 	if err != nil {
 		return err
 	}
-	if len(rs) == 0 {
-		p.Printfln("No robots found in %s", upCtx.Account)
-		return nil
-	}
-	data := make([][]string, len(rs)+1)
-	data[0] = []string{"NAME", "ID", "DESCRIPTION", "CREATED"}
-	for i, r := range rs {
-		data[i+1] = []string{r.Name, r.ID.String(), r.Description, duration.HumanDuration(time.Since(r.CreatedAt))}
-	}
-	return pt.WithHasHeader().WithData(data).Render()
+	return upterm.PrintList(kongCtx.Stdout, c.OutputFlags, rs, upterm.Table[organizations.Robot]{
+		Columns: []upterm.Column[organizations.Robot]{
+			{Header: "NAME", Value: func(r organizations.Robot) string { return r.Name }},
+			{Header: "ID", Value: func(r organizations.Robot) string { return r.ID.String() }},
+			{Header: "DESCRIPTION", Value: func(r organizations.Robot) string { return r.Description }},
+			{Header: "CREATED", Value: func(r organizations.Robot) string { return duration.HumanDuration(time.Since(r.CreatedAt)) }},
+			{Header: "TOKENS", Wide: true, Value: func(r organizations.Robot) string { return strconv.Itoa(len(r.TokenIDs)) }},
+			{Header: "TEAMS", Wide: true, Value: func(r organizations.Robot) string { return strconv.Itoa(len(r.TeamIDs)) }},
+		},
+		Name:  func(r organizations.Robot) string { return r.Name },
+		Empty: "No robots found in " + upCtx.Account,
+	})
 }
@@ -0,0 +1,89 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/upbound/up-sdk-go"
+	"github.com/upbound/up-sdk-go/service/tokens"
+)
+
+const (
+	// tokensPath is the path of the tokens API, which is not exported by the
+	// SDK.
+	tokensPath = "v1/tokens"
+
+	// tokenType is the type of the body of token requests.
+	tokenType = "tokens"
+)
+
+// tokenClient extends the SDK tokens client to create tokens that expire,
+// which the SDK's TokenCreateParameters cannot express. Requests are built and
+// sent by the SDK's client, so they are authenticated, sent to the API
+// endpoint, and have their errors decoded as any other SDK request.
+type tokenClient struct {
+	*tokens.Client
+}
+
+// newTokenClient constructs a tokenClient from the supplied SDK config.
+func newTokenClient(cfg *up.Config) *tokenClient {
+	return &tokenClient{Client: tokens.NewClient(cfg)}
+}
+
+// tokenCreateParameters are the SDK's TokenCreateParameters with attributes
+// that may carry an expiry.
+type tokenCreateParameters struct {
+	Attributes    tokenAttributes           `json:"attributes"`
+	Relationships tokens.TokenRelationships `json:"relationships,omitempty"`
+}
+
+// tokenAttributes are the SDK's TokenAttributes with an optional expiry.
+type tokenAttributes struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// tokenCreateRequest is the body of a token create request.
+type tokenCreateRequest struct {
+	Data tokenCreateData `json:"data"`
+}
+
+// tokenCreateData is the data of a tokenCreateRequest.
+type tokenCreateData struct {
+	Type string `json:"type"`
+	tokenCreateParameters
+}
+
+// Create creates a token. It sends the same request as the SDK's Create, with
+// the expiry of the token if it has one.
+func (c *tokenClient) Create(ctx context.Context, params *tokenCreateParameters) (*tokens.TokenResponse, error) {
+	req, err := c.Client.Client.NewRequest(ctx, http.MethodPost, tokensPath, "", &tokenCreateRequest{
+		Data: tokenCreateData{
+			Type:                  tokenType,
+			tokenCreateParameters: *params,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	t := &tokens.TokenResponse{}
+	if err := c.Client.Client.Do(req, &t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
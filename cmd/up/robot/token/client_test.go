@@ -0,0 +1,112 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/upbound/up-sdk-go"
+	"github.com/upbound/up-sdk-go/fake"
+)
+
+func TestTokenClientCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+	robot := uuid.MustParse("4654b8b5-c01d-4fbe-8800-22c347c21383")
+	expiresAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type args struct {
+		expiresAt *time.Time
+		do        func(req *http.Request, obj interface{}) error
+	}
+	type want struct {
+		path string
+		body string
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoExpiry": {
+			reason: "A token without an expiry should be created with the same request as the SDK sends.",
+			args: args{
+				do: fake.NewMockDoFn(nil),
+			},
+			want: want{
+				path: tokensPath,
+				body: `{"data":{"type":"tokens","attributes":{"name":"cool-token"},"relationships":{"Owner":{"data":{"type":"robots","id":"4654b8b5-c01d-4fbe-8800-22c347c21383"}}}}}`,
+			},
+		},
+		"Expiry": {
+			reason: "A token with an expiry should be created with its expiry as an attribute.",
+			args: args{
+				expiresAt: &expiresAt,
+				do:        fake.NewMockDoFn(nil),
+			},
+			want: want{
+				path: tokensPath,
+				body: `{"data":{"type":"tokens","attributes":{"name":"cool-token","expiresAt":"2023-01-02T03:04:05Z"},"relationships":{"Owner":{"data":{"type":"robots","id":"4654b8b5-c01d-4fbe-8800-22c347c21383"}}}}}`,
+			},
+		},
+		"DoFailed": {
+			reason: "Errors sending the request should be returned.",
+			args: args{
+				do: fake.NewMockDoFn(errBoom),
+			},
+			want: want{
+				path: tokensPath,
+				body: `{"data":{"type":"tokens","attributes":{"name":"cool-token"},"relationships":{"Owner":{"data":{"type":"robots","id":"4654b8b5-c01d-4fbe-8800-22c347c21383"}}}}}`,
+				err:  errBoom,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var path, body string
+			c := newTokenClient(&up.Config{
+				Client: &fake.MockClient{
+					MockNewRequest: func(_ context.Context, _, prefix, _ string, b interface{}) (*http.Request, error) {
+						path = prefix
+						j, err := json.Marshal(b)
+						body = string(j)
+						return nil, err
+					},
+					MockDo: tc.args.do,
+				},
+			})
+
+			_, err := createToken(context.Background(), c, robot, "cool-token", tc.args.expiresAt)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncreateToken(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.path, path); diff != "" {
+				t.Errorf("\n%s\ncreateToken(...): -want path, +got path:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.body, body); diff != "" {
+				t.Errorf("\n%s\ncreateToken(...): -want body, +got body:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
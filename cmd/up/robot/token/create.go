@@ -16,103 +16,72 @@ package token
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"time"
 
-	"github.com/google/uuid"
+	"github.com/alecthomas/kong"
 	"github.com/pkg/errors"
 	"github.com/pterm/pterm"
 
 	"github.com/upbound/up-sdk-go/service/accounts"
 	"github.com/upbound/up-sdk-go/service/organizations"
-	"github.com/upbound/up-sdk-go/service/robots"
 	"github.com/upbound/up-sdk-go/service/tokens"
 
 	"github.com/upbound/up/internal/upbound"
 )
 
-// createCmd creates a robot on Upbound.
+const errNoExpiryFmt = "Upbound did not record an expiry for token %s. It will not expire."
+
+// createCmd creates a robot token on Upbound.
 type createCmd struct {
-	RobotName string `arg:"" required:"" help:"Name of robot."`
+	RobotName string `arg:"" required:"" predictor:"robots" help:"Name of robot."`
 	TokenName string `arg:"" required:"" help:"Name of token."`
 
-	Output string `type:"path" short:"o" required:"" help:"Path to write JSON file containing access ID and token."`
+	ExpiresIn time.Duration `help:"Duration after which the token expires, e.g. 2160h. Tokens do not expire by default."`
+
+	tokenOutput `embed:""`
 }
 
 // Run executes the create command.
-func (c *createCmd) Run(p pterm.TextPrinter, ac *accounts.Client, oc *organizations.Client, rc *robots.Client, tc *tokens.Client, upCtx *upbound.Context) error { //nolint:gocyclo
-	a, err := ac.Get(context.Background(), upCtx.Account)
-	if err != nil {
-		return err
+func (c *createCmd) Run(kongCtx *kong.Context, p pterm.TextPrinter, ac *accounts.Client, oc *organizations.Client, tc *tokenClient, upCtx *upbound.Context) error {
+	if c.empty() {
+		return errors.New(errNoOutput)
 	}
-	if a.Account.Type != accounts.AccountOrganization {
-		return errors.New(errUserAccount)
-	}
-	rs, err := oc.ListRobots(context.Background(), a.Organization.ID)
+	ctx := context.Background()
+	id, err := findRobot(ctx, ac, oc, upCtx.Account, c.RobotName)
 	if err != nil {
 		return err
 	}
-	if len(rs) == 0 {
-		return errors.Errorf(errFindRobotFmt, c.RobotName, upCtx.Account)
-	}
-	// TODO(hasheddan): because this API does not guarantee name uniqueness, we
-	// must guarantee that exactly one robot exists in the specified account
-	// with the provided name. Logic should be simplified when the API is
-	// updated.
-	var id uuid.UUID
-	found := false
-	for _, r := range rs {
-		if r.Name == c.RobotName {
-			if found {
-				return errors.Errorf(errMultipleRobotFmt, c.RobotName, upCtx.Account)
-			}
-			id = r.ID
-			found = true
-		}
-	}
-	if !found {
-		return errors.Errorf(errFindRobotFmt, c.RobotName, upCtx.Account)
-	}
-	res, err := tc.Create(context.Background(), &tokens.TokenCreateParameters{
-		Attributes: tokens.TokenAttributes{
-			Name: c.TokenName,
-		},
-		Relationships: tokens.TokenRelationships{
-			Owner: tokens.TokenOwner{
-				Data: tokens.TokenOwnerData{
-					Type: tokens.TokenOwnerRobot,
-					ID:   id,
-				},
-			},
-		},
-	})
+	res, err := createToken(ctx, tc, id, c.TokenName, expiry(c.ExpiresIn, time.Now()))
 	if err != nil {
 		return err
 	}
 	p.Printfln("%s/%s/%s created", upCtx.Account, c.RobotName, c.TokenName)
-	if c.Output == "" {
-		p.Printfln("Refusing to emit sensitive output. Please specify output location.")
-		return nil
-	}
+	warnExpiry(kongCtx, res, c.ExpiresIn)
+
+	return c.write(ctx, kongCtx.Stdout, upbound.TokenFile{
+		AccessID: res.ID.String(),
+		Token:    fmt.Sprint(res.DataSet.Meta["jwt"]),
+	}, upCtx.RegistryEndpoint.Hostname())
+}
 
-	access := res.ID.String()
-	token := fmt.Sprint(res.DataSet.Meta["jwt"])
-	if c.Output == "-" {
-		pterm.Println()
-		p.Printfln(pterm.LightMagenta("Access ID: ") + access)
-		p.Printfln(pterm.LightMagenta("Token: ") + token)
+// expiry returns when a token created at the supplied time with the supplied
+// lifetime expires, or nil if it does not expire.
+func expiry(lifetime time.Duration, now time.Time) *time.Time {
+	if lifetime <= 0 {
 		return nil
 	}
+	e := now.Add(lifetime).UTC().Truncate(time.Second)
+	return &e
+}
 
-	f, err := os.OpenFile(filepath.Clean(c.Output), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return err
+// warnExpiry warns if an expiry was requested for the supplied token but
+// Upbound did not record one.
+func warnExpiry(kongCtx *kong.Context, res *tokens.TokenResponse, lifetime time.Duration) {
+	if lifetime <= 0 {
+		return
+	}
+	if _, ok := tokenExpiry(res.DataSet); !ok {
+		pterm.Warning.WithWriter(kongCtx.Stdout).Printfln(errNoExpiryFmt, tokenName(res.DataSet))
 	}
-	defer f.Close() //nolint:errcheck,gosec
-	return json.NewEncoder(f).Encode(&upbound.TokenFile{
-		AccessID: access,
-		Token:    token,
-	})
 }
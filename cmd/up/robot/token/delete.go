@@ -60,48 +60,20 @@ func (c *deleteCmd) AfterApply(p pterm.TextPrinter, upCtx *upbound.Context) erro
 type deleteCmd struct {
 	prompter input.Prompter
 
-	RobotName string `arg:"" required:"" help:"Name of robot."`
+	RobotName string `arg:"" required:"" predictor:"robots" help:"Name of robot."`
 	TokenName string `arg:"" required:"" help:"Name of token."`
 
 	Force bool `help:"Force delete token even if conflicts exist." default:"false"`
 }
 
 // Run executes the delete command.
-func (c *deleteCmd) Run(p pterm.TextPrinter, ac *accounts.Client, oc *organizations.Client, rc *robots.Client, tc *tokens.Client, upCtx *upbound.Context) error { //nolint:gocyclo
-	a, err := ac.Get(context.Background(), upCtx.Account)
+func (c *deleteCmd) Run(p pterm.TextPrinter, ac *accounts.Client, oc *organizations.Client, rc *robots.Client, tc *tokens.Client, upCtx *upbound.Context) error {
+	rid, err := findRobot(context.Background(), ac, oc, upCtx.Account, c.RobotName)
 	if err != nil {
 		return err
 	}
-	if a.Account.Type != accounts.AccountOrganization {
-		return errors.New(errUserAccount)
-	}
-	rs, err := oc.ListRobots(context.Background(), a.Organization.ID)
-	if err != nil {
-		return err
-	}
-	if len(rs) == 0 {
-		return errors.Errorf(errFindRobotFmt, c.RobotName, upCtx.Account)
-	}
-	// TODO(hasheddan): because this API does not guarantee name uniqueness, we
-	// must guarantee that exactly one robot exists in the specified account
-	// with the provided name. Logic should be simplified when the API is
-	// updated.
-	var rid *uuid.UUID
-	for _, r := range rs {
-		if r.Name == c.RobotName {
-			if rid != nil {
-				return errors.Errorf(errMultipleRobotFmt, c.RobotName, upCtx.Account)
-			}
-			// Pin range variable so that we can take address.
-			r := r
-			rid = &r.ID
-		}
-	}
-	if rid == nil {
-		return errors.Errorf(errFindRobotFmt, c.RobotName, upCtx.Account)
-	}
 
-	ts, err := rc.ListTokens(context.Background(), *rid)
+	ts, err := rc.ListTokens(context.Background(), rid)
 	if err != nil {
 		return err
 	}
@@ -115,7 +87,7 @@ func (c *deleteCmd) Run(p pterm.TextPrinter, ac *accounts.Client, oc *organizati
 	// when the API is updated.
 	var tid *uuid.UUID
 	for _, t := range ts.DataSet {
-		if fmt.Sprint(t.AttributeSet["name"]) == c.TokenName {
+		if tokenName(t) == c.TokenName {
 			if tid != nil && !c.Force {
 				return errors.Errorf(errMultipleTokenFmt, c.TokenName, c.RobotName, upCtx.Account)
 			}
@@ -0,0 +1,151 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/upbound/up/internal/kube"
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	// jsonFormat writes the access ID and token of a token.
+	jsonFormat = "json"
+	// dockerConfigFormat writes a docker config that authenticates to the
+	// Upbound registry with a token.
+	dockerConfigFormat = "docker-config"
+
+	accessIDKey = "accessId"
+	tokenKey    = "token"
+
+	errNoOutput    = "refusing to emit sensitive output: please specify --output or --secret"
+	errWriteSecret = "failed to write token to secret"
+	errWriteFile   = "failed to write token to file"
+)
+
+// tokenOutput configures where the credentials of a new token are written.
+type tokenOutput struct {
+	newKube func(kubeconfig string) (kubernetes.Interface, error)
+
+	Output     string `type:"path" short:"o" help:"Path to write the token to. Use - to write to stdout."`
+	Format     string `enum:"json,docker-config" default:"json" help:"Format of the written token. One of json, which contains the access ID and token, or docker-config, which authenticates to the Upbound registry."`
+	Secret     string `help:"Name of a Kubernetes Secret to write the token to."`
+	Namespace  string `short:"n" env:"UPBOUND_NAMESPACE" default:"upbound-system" help:"Kubernetes namespace of the Secret."`
+	Kubeconfig string `type:"existingfile" help:"Override default kubeconfig path."`
+}
+
+// empty returns true if the token is not written anywhere.
+func (o *tokenOutput) empty() bool {
+	return o.Output == "" && o.Secret == ""
+}
+
+// write writes the supplied token to each configured destination. stdout is
+// used when the output path is -. The Secret is written last, so that if
+// writing the output fails and the token is deleted again, the Secret still
+// holds the token that it held before.
+func (o *tokenOutput) write(ctx context.Context, stdout io.Writer, tf upbound.TokenFile, registry string) error {
+	if err := o.writeOutput(stdout, tf, registry); err != nil {
+		return err
+	}
+	if o.Secret == "" {
+		return nil
+	}
+	return errors.Wrap(o.writeSecret(ctx, tf, registry), errWriteSecret)
+}
+
+// writeOutput writes the supplied token to the configured output path, if
+// any.
+func (o *tokenOutput) writeOutput(stdout io.Writer, tf upbound.TokenFile, registry string) error {
+	switch o.Output {
+	case "":
+		return nil
+	case "-":
+		if o.Format == jsonFormat {
+			pterm.Fprintln(stdout)
+			pterm.Fprintln(stdout, pterm.LightMagenta("Access ID: ")+tf.AccessID)
+			pterm.Fprintln(stdout, pterm.LightMagenta("Token: ")+tf.Token)
+			return nil
+		}
+		b, err := o.encode(tf, registry)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(b)
+		return err
+	}
+	b, err := o.encode(tf, registry)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.WriteFile(filepath.Clean(o.Output), b, 0600), errWriteFile)
+}
+
+// encode encodes the supplied token in the configured format.
+func (o *tokenOutput) encode(tf upbound.TokenFile, registry string) ([]byte, error) {
+	if o.Format == dockerConfigFormat {
+		b, err := kube.DockerConfigJSON(tf.AccessID, tf.Token, registry)
+		return append(b, '\n'), err
+	}
+	b, err := json.Marshal(&tf)
+	return append(b, '\n'), err
+}
+
+// writeSecret creates or replaces the configured Secret with the supplied
+// token. Docker configs are written as image pull secrets.
+func (o *tokenOutput) writeSecret(ctx context.Context, tf upbound.TokenFile, registry string) error {
+	newKube := o.newKube
+	if newKube == nil {
+		newKube = kubeClient
+	}
+	client, err := newKube(o.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	if o.Format == dockerConfigFormat {
+		return kube.NewImagePullApplicator(kube.NewSecretApplicator(client)).
+			Apply(ctx, o.Secret, o.Namespace, tf.AccessID, tf.Token, registry)
+	}
+	return kube.NewSecretApplicator(client).Apply(ctx, o.Namespace, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Secret,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			accessIDKey: []byte(tf.AccessID),
+			tokenKey:    []byte(tf.Token),
+		},
+	})
+}
+
+// kubeClient builds a Kubernetes client from the supplied kubeconfig, or the
+// default kubeconfig if it is empty.
+func kubeClient(kubeconfig string) (kubernetes.Interface, error) {
+	cfg, err := kube.GetKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(cfg)
+}
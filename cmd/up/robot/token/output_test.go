@@ -0,0 +1,151 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+func TestTokenOutputWrite(t *testing.T) {
+	tf := upbound.TokenFile{AccessID: "cool-id", Token: "cool-token"}
+	registry := "xpkg.upbound.io"
+
+	type want struct {
+		file   string
+		secret *corev1.Secret
+	}
+	cases := map[string]struct {
+		reason string
+		o      tokenOutput
+		want   want
+	}{
+		"JSONFile": {
+			reason: "A JSON token file should contain the access ID and token.",
+			o:      tokenOutput{Format: jsonFormat},
+			want: want{
+				file: "{\"accessId\":\"cool-id\",\"token\":\"cool-token\"}\n",
+			},
+		},
+		"DockerConfigFile": {
+			reason: "A docker config should authenticate to the registry with the access ID and token.",
+			o:      tokenOutput{Format: dockerConfigFormat},
+			want: want{
+				file: "{\"auths\":{\"xpkg.upbound.io\":{\"username\":\"cool-id\",\"password\":\"cool-token\",\"auth\":\"Y29vbC1pZDpjb29sLXRva2Vu\"}}}\n",
+			},
+		},
+		"OpaqueSecret": {
+			reason: "A JSON token should be written to an opaque Secret.",
+			o:      tokenOutput{Format: jsonFormat, Secret: "robot", Namespace: "ci"},
+			want: want{
+				secret: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "robot", Namespace: "ci"},
+					Type:       corev1.SecretTypeOpaque,
+					Data: map[string][]byte{
+						accessIDKey: []byte("cool-id"),
+						tokenKey:    []byte("cool-token"),
+					},
+				},
+			},
+		},
+		"ImagePullSecret": {
+			reason: "A docker config should be written to an image pull Secret.",
+			o:      tokenOutput{Format: dockerConfigFormat, Secret: "robot", Namespace: "ci"},
+			want: want{
+				secret: &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "robot", Namespace: "ci"},
+					Type:       corev1.SecretTypeDockerConfigJson,
+					Data: map[string][]byte{
+						corev1.DockerConfigJsonKey: []byte("{\"auths\":{\"xpkg.upbound.io\":{\"username\":\"cool-id\",\"password\":\"cool-token\",\"auth\":\"Y29vbC1pZDpjb29sLXRva2Vu\"}}}"),
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			tc.o.newKube = func(string) (kubernetes.Interface, error) { return client, nil }
+			if tc.o.Secret == "" {
+				tc.o.Output = filepath.Join(t.TempDir(), "token")
+			}
+
+			if err := tc.o.write(context.Background(), nil, tf, registry); err != nil {
+				t.Fatalf("\n%s\nwrite(...): unexpected error: %v", tc.reason, err)
+			}
+
+			if tc.want.secret != nil {
+				got, err := client.CoreV1().Secrets(tc.want.secret.Namespace).Get(context.Background(), tc.want.secret.Name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("\n%s\nwrite(...): secret not written: %v", tc.reason, err)
+				}
+				if diff := cmp.Diff(tc.want.secret, got); diff != "" {
+					t.Errorf("\n%s\nwrite(...): -want secret, +got secret:\n%s", tc.reason, diff)
+				}
+				return
+			}
+			b, err := os.ReadFile(tc.o.Output)
+			if err != nil {
+				t.Fatalf("\n%s\nwrite(...): file not written: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.file, string(b)); diff != "" {
+				t.Errorf("\n%s\nwrite(...): -want file, +got file:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTokenOutputWriteFileFails(t *testing.T) {
+	old := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "robot", Namespace: "ci"},
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			accessIDKey: []byte("old-id"),
+			tokenKey:    []byte("old-token"),
+		},
+	}
+	client := fake.NewSimpleClientset(old.DeepCopy())
+	o := tokenOutput{
+		newKube:   func(string) (kubernetes.Interface, error) { return client, nil },
+		Output:    filepath.Join(t.TempDir(), "missing", "token"),
+		Format:    jsonFormat,
+		Secret:    "robot",
+		Namespace: "ci",
+	}
+
+	if err := o.write(context.Background(), nil, upbound.TokenFile{AccessID: "cool-id", Token: "cool-token"}, "xpkg.upbound.io"); err == nil {
+		t.Fatal("write(...): expected an error writing to a missing directory")
+	}
+
+	// The new token is deleted when writing it fails, so the Secret must
+	// still hold the old token.
+	got, err := client.CoreV1().Secrets("ci").Get(context.Background(), "robot", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(old, got); diff != "" {
+		t.Errorf("write(...): -want secret, +got secret:\n%s", diff)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+
+	"github.com/upbound/up-sdk-go/service/accounts"
+	"github.com/upbound/up-sdk-go/service/organizations"
+	"github.com/upbound/up-sdk-go/service/robots"
+
+	"github.com/upbound/up/internal/upbound"
+)
+
+const (
+	errWriteRotatedFmt    = "failed to write new token, %s/%s/%s was left in place"
+	errRollbackRotatedFmt = "failed to write new token and to delete it again, %s/%s/%s now has two tokens"
+	errDeleteRotatedFmt   = "new token was written, but failed to delete old token %s"
+)
+
+// rotateCmd replaces a robot token on Upbound with a new one of the same name.
+type rotateCmd struct {
+	RobotName string `arg:"" required:"" predictor:"robots" help:"Name of robot."`
+	TokenName string `arg:"" required:"" help:"Name of token."`
+
+	ExpiresIn time.Duration `help:"Duration after which the new token expires, e.g. 2160h. Tokens do not expire by default."`
+
+	tokenOutput `embed:""`
+}
+
+// Run executes the rotate command. The old token is only deleted once the new
+// one has been written to each output. If writing the new token fails, the new
+// token is deleted instead and the old one remains valid. The Secret is written
+// last, so it still holds the old token if writing to the output path fails.
+func (c *rotateCmd) Run(kongCtx *kong.Context, p pterm.TextPrinter, ac *accounts.Client, oc *organizations.Client, rc *robots.Client, tc *tokenClient, upCtx *upbound.Context) error {
+	if c.empty() {
+		return errors.New(errNoOutput)
+	}
+	ctx := context.Background()
+	rid, err := findRobot(ctx, ac, oc, upCtx.Account, c.RobotName)
+	if err != nil {
+		return err
+	}
+	ts, err := rc.ListTokens(ctx, rid)
+	if err != nil {
+		return err
+	}
+	// TODO(hasheddan): because this API does not guarantee name uniqueness, we
+	// must guarantee that exactly one token exists for the specified robot in
+	// the specified account with the provided name. Logic should be simplified
+	// when the API is updated.
+	var old *uuid.UUID
+	for _, t := range ts.DataSet {
+		if tokenName(t) == c.TokenName {
+			if old != nil {
+				return errors.Errorf(errMultipleTokenFmt, c.TokenName, c.RobotName, upCtx.Account)
+			}
+			// Pin range variable so that we can take address.
+			t := t
+			old = &t.ID
+		}
+	}
+	if old == nil {
+		return errors.Errorf(errFindTokenFmt, c.TokenName, c.RobotName, upCtx.Account)
+	}
+
+	res, err := createToken(ctx, tc, rid, c.TokenName, expiry(c.ExpiresIn, time.Now()))
+	if err != nil {
+		return err
+	}
+	if err := c.write(ctx, kongCtx.Stdout, upbound.TokenFile{
+		AccessID: res.ID.String(),
+		Token:    fmt.Sprint(res.DataSet.Meta["jwt"]),
+	}, upCtx.RegistryEndpoint.Hostname()); err != nil {
+		if derr := tc.Delete(ctx, res.ID); derr != nil {
+			return errors.Wrapf(err, errRollbackRotatedFmt, upCtx.Account, c.RobotName, c.TokenName)
+		}
+		return errors.Wrapf(err, errWriteRotatedFmt, upCtx.Account, c.RobotName, c.TokenName)
+	}
+	if err := tc.Delete(ctx, *old); err != nil {
+		return errors.Wrapf(err, errDeleteRotatedFmt, old.String())
+	}
+	p.Printfln("%s/%s/%s rotated", upCtx.Account, c.RobotName, c.TokenName)
+	warnExpiry(kongCtx, res, c.ExpiresIn)
+	return nil
+}
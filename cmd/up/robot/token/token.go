@@ -15,8 +15,17 @@
 package token
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/alecthomas/kong"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 
+	"github.com/upbound/up-sdk-go/service/accounts"
+	"github.com/upbound/up-sdk-go/service/common"
+	"github.com/upbound/up-sdk-go/service/organizations"
 	"github.com/upbound/up-sdk-go/service/tokens"
 
 	"github.com/upbound/up/internal/upbound"
@@ -30,14 +39,20 @@ const (
 	errFindTokenFmt     = "could not find token %s for robot %s in %s"
 )
 
+const (
+	// expiresAtKey is the attribute in which the expiry of a token is
+	// recorded.
+	expiresAtKey = "expiresAt"
+)
+
 // AfterApply constructs and binds a robots client to any subcommands
 // that have Run() methods that receive it.
 func (c *Cmd) AfterApply(kongCtx *kong.Context, upCtx *upbound.Context) error {
-	cfg, err := upCtx.BuildSDKConfig(upCtx.Profile.Session)
+	cfg, err := upCtx.BuildSDKConfig()
 	if err != nil {
 		return err
 	}
-	kongCtx.Bind(tokens.NewClient(cfg))
+	kongCtx.Bind(tokens.NewClient(cfg), newTokenClient(cfg))
 	return nil
 }
 
@@ -46,4 +61,77 @@ type Cmd struct {
 	Create createCmd `cmd:"" help:"Create a token for the robot."`
 	Delete deleteCmd `cmd:"" help:"Delete a token for the robot."`
 	List   listCmd   `cmd:"" help:"List a token for the robot."`
+	Rotate rotateCmd `cmd:"" help:"Replace a token for the robot with a new one."`
+}
+
+// findRobot returns the ID of the robot with the supplied name in the supplied
+// account.
+func findRobot(ctx context.Context, ac *accounts.Client, oc *organizations.Client, account, name string) (uuid.UUID, error) {
+	a, err := ac.Get(ctx, account)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if a.Account.Type != accounts.AccountOrganization {
+		return uuid.Nil, errors.New(errUserAccount)
+	}
+	rs, err := oc.ListRobots(ctx, a.Organization.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	// TODO(hasheddan): because this API does not guarantee name uniqueness, we
+	// must guarantee that exactly one robot exists in the specified account
+	// with the provided name. Logic should be simplified when the API is
+	// updated.
+	var id uuid.UUID
+	found := false
+	for _, r := range rs {
+		if r.Name == name {
+			if found {
+				return uuid.Nil, errors.Errorf(errMultipleRobotFmt, name, account)
+			}
+			id = r.ID
+			found = true
+		}
+	}
+	if !found {
+		return uuid.Nil, errors.Errorf(errFindRobotFmt, name, account)
+	}
+	return id, nil
+}
+
+// createToken creates a token with the supplied name for the supplied robot.
+// The token expires at the supplied time if it is not nil.
+func createToken(ctx context.Context, tc *tokenClient, robot uuid.UUID, name string, expiresAt *time.Time) (*tokens.TokenResponse, error) {
+	return tc.Create(ctx, &tokenCreateParameters{
+		Attributes: tokenAttributes{Name: name, ExpiresAt: expiresAt},
+		Relationships: tokens.TokenRelationships{
+			Owner: tokens.TokenOwner{
+				Data: tokens.TokenOwnerData{
+					Type: tokens.TokenOwnerRobot,
+					ID:   robot,
+				},
+			},
+		},
+	})
+}
+
+// tokenName returns the name attribute of a token.
+func tokenName(t common.DataSet) string {
+	return fmt.Sprint(t.AttributeSet["name"])
+}
+
+// tokenExpiry returns when a token expires, if Upbound recorded an expiry for
+// it.
+func tokenExpiry(t common.DataSet) (time.Time, bool) {
+	for _, s := range []map[string]any{t.AttributeSet, t.Meta} {
+		v, ok := s[expiresAtKey]
+		if !ok || v == nil {
+			continue
+		}
+		e, err := time.Parse(time.RFC3339, fmt.Sprint(v))
+		if err == nil {
+			return e, true
+		}
+	}
+	return time.Time{}, false
 }
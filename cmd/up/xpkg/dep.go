@@ -100,7 +100,7 @@ type depCmd struct {
 	CacheDir   string `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
 	CleanCache bool   `short:"c" help:"Clean dep cache."`
 
-	Package string `arg:"" optional:"" help:"Package to be added."`
+	Package string `arg:"" optional:"" predictor:"packages" help:"Package to be added."`
 }
 
 // Run executes the dep command.
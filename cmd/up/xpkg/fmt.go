@@ -0,0 +1,129 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/xpkg/format"
+)
+
+const (
+	errFormatFileFmt  = "failed to format %s"
+	errWriteFileFmt   = "failed to write %s"
+	errUnformattedFmt = "%d file(s) are not formatted"
+)
+
+// AfterApply constructs and binds context to any subcommands that have Run()
+// methods that receive it.
+func (c *fmtCmd) AfterApply() error {
+	c.fs = afero.NewOsFs()
+
+	root, err := filepath.Abs(c.PackageRoot)
+	if err != nil {
+		return err
+	}
+	c.root = root
+	return nil
+}
+
+// fmtCmd formats the YAML files in a package.
+type fmtCmd struct {
+	fs   afero.Fs
+	root string
+
+	PackageRoot string   `short:"f" help:"Path to package directory." default:"."`
+	Check       bool     `help:"List files that are not formatted and exit with an error instead of writing them."`
+	Ignore      []string `help:"Paths, specified relative to --package-root, to exclude from formatting."`
+}
+
+// Run executes the fmt command.
+func (c *fmtCmd) Run(p pterm.TextPrinter) error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	unformatted := 0
+	for _, f := range files {
+		b, err := afero.ReadFile(c.fs, f)
+		if err != nil {
+			return err
+		}
+		out, err := format.Format(b)
+		if err != nil {
+			return errors.Wrapf(err, errFormatFileFmt, f)
+		}
+		if bytes.Equal(b, out) {
+			continue
+		}
+		unformatted++
+		rel, err := filepath.Rel(c.root, f)
+		if err != nil {
+			rel = f
+		}
+		p.Printfln("%s", rel)
+		if c.Check {
+			continue
+		}
+		if err := afero.WriteFile(c.fs, f, out, os.ModePerm); err != nil {
+			return errors.Wrapf(err, errWriteFileFmt, f)
+		}
+	}
+	if c.Check && unformatted > 0 {
+		return errors.Errorf(errUnformattedFmt, unformatted)
+	}
+	return nil
+}
+
+// files returns the YAML files in the package, skipping hidden and ignored
+// paths.
+func (c *fmtCmd) files() ([]string, error) {
+	skips := make([]string, len(c.Ignore))
+	for i, s := range c.Ignore {
+		skips[i] = filepath.Join(c.root, s)
+	}
+	files := []string{}
+	err := afero.Walk(c.fs, c.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, s := range skips {
+			if path == s || strings.HasPrefix(path, s+string(os.PathSeparator)) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if info.IsDir() {
+			if path != c.root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
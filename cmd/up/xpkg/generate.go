@@ -0,0 +1,147 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpkg
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/alecthomas/kong"
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/xpkg/dep/cache"
+	"github.com/upbound/up/internal/xpkg/dep/manager"
+	"github.com/upbound/up/internal/xpkg/dep/resolver/image"
+	"github.com/upbound/up/internal/xpkg/snapshot"
+)
+
+const (
+	errLoadWorkspace = "failed to load package workspace"
+)
+
+// AfterApply constructs and binds a snapshot of the package workspace to any
+// subcommands that have Run() methods that receive it.
+func (c *generateCmd) AfterApply(kongCtx *kong.Context) error {
+	root, err := filepath.Abs(c.PackageRoot)
+	if err != nil {
+		return err
+	}
+
+	cache, err := cache.NewLocal(c.CacheDir)
+	if err != nil {
+		return err
+	}
+	m, err := manager.New(
+		manager.WithCache(cache),
+		manager.WithResolver(image.NewResolver()),
+	)
+	if err != nil {
+		return err
+	}
+
+	factory, err := snapshot.NewFactory(root, snapshot.WithDepManager(m))
+	if err != nil {
+		return errors.Wrap(err, errLoadWorkspace)
+	}
+	snap, err := factory.New()
+	if err != nil {
+		return errors.Wrap(err, errLoadWorkspace)
+	}
+	kongCtx.Bind(snap)
+	return nil
+}
+
+// generateCmd generates resources from the definitions in a package.
+type generateCmd struct {
+	PackageRoot string `short:"f" help:"Path to package directory." default:"."`
+	CacheDir    string `short:"d" help:"Directory used for caching package images." default:"~/.up/cache/" env:"CACHE_DIR" type:"path"`
+
+	Claim   generateClaimCmd   `cmd:"" help:"Generate a claim for a CompositeResourceDefinition."`
+	Example generateExampleCmd `cmd:"" help:"Generate a composite resource for a CompositeResourceDefinition."`
+	Types   generateTypesCmd   `cmd:"" help:"Generate types for the composite resources and claims defined by CompositeResourceDefinitions."`
+}
+
+// generateClaimCmd generates a claim for an XRD.
+type generateClaimCmd struct {
+	XRD    string `arg:"" help:"Name of the CompositeResourceDefinition, or kind of the composite resource or claim it defines."`
+	Output string `short:"o" type:"path" help:"File to write the claim to. Written to stdout if not supplied."`
+}
+
+// Run executes the generate claim command.
+func (c *generateClaimCmd) Run(kongCtx *kong.Context, snap *snapshot.Snapshot) error {
+	b, err := snap.GenerateClaim(c.XRD)
+	if err != nil {
+		return err
+	}
+	return writeGenerated(kongCtx, c.Output, b)
+}
+
+// generateExampleCmd generates a composite resource for an XRD.
+type generateExampleCmd struct {
+	XRD    string `arg:"" help:"Name of the CompositeResourceDefinition, or kind of the composite resource or claim it defines."`
+	Output string `short:"o" type:"path" help:"File to write the composite resource to. Written to stdout if not supplied."`
+}
+
+// Run executes the generate example command.
+func (c *generateExampleCmd) Run(kongCtx *kong.Context, snap *snapshot.Snapshot) error {
+	b, err := snap.GenerateExample(c.XRD)
+	if err != nil {
+		return err
+	}
+	return writeGenerated(kongCtx, c.Output, b)
+}
+
+// writeGenerated writes the supplied generated resource to the supplied path,
+// or to stdout if no path is supplied.
+func writeGenerated(kongCtx *kong.Context, path string, b []byte) error {
+	if path == "" {
+		_, err := kongCtx.Stdout.Write(b)
+		return err
+	}
+	fs := afero.NewOsFs()
+	if err := fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return errors.Wrapf(afero.WriteFile(fs, path, b, os.ModePerm), errWriteFileFmt, path)
+}
+
+// generateTypesCmd generates types for the XRDs in a package.
+type generateTypesCmd struct {
+	Lang         string `enum:"go,jsonschema" default:"go" help:"Language to generate types in. One of go or jsonschema."`
+	Output       string `short:"o" type:"path" default:"types" help:"Directory to write the generated types to."`
+	Dependencies bool   `help:"Also generate types for the CompositeResourceDefinitions of resolved dependencies."`
+}
+
+// Run executes the generate types command.
+func (c *generateTypesCmd) Run(p pterm.TextPrinter, snap *snapshot.Snapshot) error {
+	files, err := snap.GenerateTypes(c.Lang, c.Dependencies)
+	if err != nil {
+		return err
+	}
+	fs := afero.NewOsFs()
+	for _, f := range files {
+		path := filepath.Join(c.Output, f.Path)
+		if err := fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		if err := afero.WriteFile(fs, path, f.Content, os.ModePerm); err != nil {
+			return errors.Wrapf(err, errWriteFileFmt, path)
+		}
+		p.Printfln("%s", path)
+	}
+	return nil
+}
@@ -123,7 +123,7 @@ type xpExtractCmd struct {
 	name  name.Reference
 	fetch fetchFn
 
-	Package    string `arg:"" optional:"" help:"Name of the package to extract. Must be a valid OCI image tag or a path if using --from-xpkg."`
+	Package    string `arg:"" optional:"" predictor:"packages" help:"Name of the package to extract. Must be a valid OCI image tag or a path if using --from-xpkg."`
 	FromDaemon bool   `xor:"xp-extract-from" help:"Indicates that the image should be fetched from the Docker daemon."`
 	FromXpkg   bool   `xor:"xp-extract-from" help:"Indicates that the image should be fetched from a local xpkg. If package is not specified and only one exists in current directory it will be used."`
 	Output     string `short:"o" help:"Package output file path. Extension must be .gz or will be replaced." default:"out.gz"`
@@ -31,5 +31,7 @@ type Cmd struct {
 	XPExtract xpExtractCmd `cmd:"" maturity:"alpha" help:"Extract package contents into a Crossplane cache compatible format. Fetches from a remote registry by default."`
 	Init      initCmd      `cmd:"" help:"Initialize a package."`
 	Dep       depCmd       `cmd:"" help:"Manage package dependencies."`
+	Fmt       fmtCmd       `cmd:"" help:"Format package YAML files."`
+	Generate  generateCmd  `cmd:"" help:"Generate resources from the definitions in a package."`
 	Push      pushCmd      `cmd:"" help:"Push a package."`
 }
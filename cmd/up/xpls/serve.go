@@ -16,8 +16,13 @@ package xpls
 
 import (
 	"context"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/pkg/errors"
 	"github.com/sourcegraph/jsonrpc2"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -25,6 +30,12 @@ import (
 	"github.com/upbound/up/internal/xpls/handler"
 )
 
+const (
+	errExitWithoutShutdown = "client exited without requesting shutdown"
+	errCreateHandler       = "failed to create handler for client"
+	errAcceptClient        = "failed to accept client"
+)
+
 // serveCmd starts the language server.
 type serveCmd struct {
 	// TODO(@tnthornton) cache dir doesn't seem to be the responsibility of the
@@ -32,6 +43,7 @@ type serveCmd struct {
 	// if someone specifies config element from the command line. We should move
 	// this to the config.
 	Cache   string `default:"~/.up/cache" help:"Directory path for dependency schema cache." type:"path"`
+	Listen  string `help:"Serve clients connecting to the supplied address rather than a single client over stdio. Addresses prefixed with unix:// are unix sockets, all others are TCP. Clients are not authenticated, so TCP addresses without a host listen on 127.0.0.1 only, and any client that can reach the address can read the workspace." placeholder:"ADDRESS"`
 	Verbose bool   `help:"Run server with verbose logging."`
 }
 
@@ -42,14 +54,65 @@ func (c *serveCmd) Run() error {
 
 	// TODO(hasheddan): move to AfterApply.
 	zl := zap.New(zap.UseDevMode(c.Verbose))
+	log := logging.NewLogrLogger(zl.WithName("xpls"))
+
+	if c.Listen != "" {
+		return c.listen(log)
+	}
+
 	h, err := handler.New(
-		handler.WithLogger(logging.NewLogrLogger(zl.WithName("xpls"))),
+		handler.WithLogger(log),
 	)
 	if err != nil {
-		return err
+		return errors.Wrap(err, errCreateHandler)
 	}
 
-	// TODO(hasheddan): handle graceful shutdown.
 	<-jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(xpls.StdRWC{}, jsonrpc2.VSCodeObjectCodec{}), h).DisconnectNotify()
+	if !h.ShutdownRequested() {
+		return errors.New(errExitWithoutShutdown)
+	}
 	return nil
 }
+
+// listen serves each client that connects to the listen address with its own
+// handler until the process is interrupted.
+func (c *serveCmd) listen(log logging.Logger) error {
+	ln, err := xpls.Listen(c.Listen)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	log.Info("Listening for clients", "address", ln.Addr().String())
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				// the listener was closed because we were interrupted.
+				return nil
+			}
+			return errors.Wrap(err, errAcceptClient)
+		}
+		go serveConn(ctx, log, conn)
+	}
+}
+
+// serveConn serves a single client until it disconnects.
+func serveConn(ctx context.Context, log logging.Logger, conn net.Conn) {
+	defer conn.Close() // nolint:errcheck
+
+	h, err := handler.New(
+		handler.WithLogger(log.WithValues("client", conn.RemoteAddr().String())),
+	)
+	if err != nil {
+		log.Info(errCreateHandler, "error", err)
+		return
+	}
+	<-jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(conn, jsonrpc2.VSCodeObjectCodec{}), h).DisconnectNotify()
+}
@@ -0,0 +1,276 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package completion completes command lines of kong applications.
+package completion
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/upbound/up/internal/feature"
+)
+
+const (
+	// predictorTag names the Predictor used to complete the values of a
+	// positional argument or flag.
+	predictorTag = "predictor"
+
+	// pathType and existingFileType are kong types whose values are
+	// completed with file names by the shell.
+	pathType         = "path"
+	existingFileType = "existingfile"
+)
+
+// fileType is the type of values that kong reads from a file, which are
+// completed with file names by the shell.
+var fileType = reflect.TypeOf((*os.File)(nil))
+
+// A Predictor predicts the values of a positional argument or flag.
+type Predictor func(ctx context.Context, a Args) ([]string, error)
+
+// Args describe the command line being completed.
+type Args struct {
+	// Flags are the values of the flags that have been supplied so far,
+	// keyed by flag name.
+	Flags map[string]string
+}
+
+// A Candidate is a possible completion of a word.
+type Candidate struct {
+	Value       string
+	Description string
+}
+
+// A Result of completing a command line.
+type Result struct {
+	Candidates []Candidate
+
+	// Files indicates that the word should be completed with file names.
+	Files bool
+}
+
+// Completer completes command lines of a kong application.
+type Completer struct {
+	app        *kong.Application
+	predictors map[string]Predictor
+}
+
+// An Option modifies a Completer.
+type Option func(c *Completer)
+
+// WithPredictor adds a Predictor that is used to complete the values of
+// positional arguments and flags tagged with the supplied name.
+func WithPredictor(name string, p Predictor) Option {
+	return func(c *Completer) {
+		c.predictors[name] = p
+	}
+}
+
+// New constructs a Completer for the supplied application.
+func New(app *kong.Application, opts ...Option) *Completer {
+	c := &Completer{
+		app:        app,
+		predictors: map[string]Predictor{},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Complete completes the last of the supplied words, which follow the name of
+// the application on the command line. Commands that are hidden, including
+// those hidden because of their maturity, are not completed. Predictors that
+// fail do not contribute candidates.
+func (c *Completer) Complete(ctx context.Context, words []string) Result { //nolint:gocyclo
+	cur := ""
+	if len(words) > 0 {
+		cur, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	node := c.app.Node
+	maturity := feature.Stable
+	_ = feature.HideMaturity(&kong.Path{App: c.app}, maturity)
+	a := Args{Flags: map[string]string{}}
+	positional := 0
+	argsOnly := false
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		switch {
+		case w == "--" && !argsOnly:
+			argsOnly = true
+		case strings.HasPrefix(w, "-") && len(w) > 1 && !argsOnly:
+			f, value, ok := lookupFlag(node, w)
+			if f == nil {
+				continue
+			}
+			if !ok && !f.IsBool() && !f.IsCounter() && i+1 < len(words) {
+				i++
+				value = words[i]
+			}
+			a.Flags[f.Name] = value
+		default:
+			if child := findChild(node, w); child != nil && positional == 0 {
+				node = child
+				maturity = feature.SelectedMaturity(node, maturity)
+				_ = feature.HideMaturity(&kong.Path{Command: node}, maturity)
+				continue
+			}
+			positional++
+		}
+	}
+
+	// The previous word may be a flag that requires a value.
+	if len(words) > 0 && !argsOnly {
+		prev := words[len(words)-1]
+		if f, _, ok := lookupFlag(node, prev); f != nil && !ok && !f.IsBool() && !f.IsCounter() && strings.HasPrefix(prev, "-") {
+			return c.values(ctx, f.Value, a, "", cur)
+		}
+	}
+
+	if strings.HasPrefix(cur, "-") && !argsOnly {
+		if f, _, ok := lookupFlag(node, cur); f != nil && ok {
+			name := cur[:strings.Index(cur, "=")+1]
+			return c.values(ctx, f.Value, a, name, cur[len(name):])
+		}
+		return Result{Candidates: filter(flags(node), cur)}
+	}
+
+	var r Result
+	if positional == 0 {
+		r.Candidates = commands(node)
+	}
+	if p := positionalAt(node, positional); p != nil {
+		pr := c.values(ctx, p, a, "", cur)
+		r.Candidates = append(r.Candidates, pr.Candidates...)
+		r.Files = pr.Files
+	}
+	r.Candidates = filter(r.Candidates, cur)
+	return r
+}
+
+// values completes the value of the supplied positional argument or flag.
+// Each candidate is prefixed with the supplied prefix.
+func (c *Completer) values(ctx context.Context, v *kong.Value, a Args, prefix, cur string) Result {
+	var r Result
+	if v.Enum != "" {
+		for _, e := range v.EnumSlice() {
+			r.Candidates = append(r.Candidates, Candidate{Value: prefix + e})
+		}
+	}
+	if p, ok := c.predictors[v.Tag.Get(predictorTag)]; ok {
+		// Predictions are best effort, so errors are not surfaced.
+		vals, _ := p(ctx, a)
+		for _, val := range vals {
+			r.Candidates = append(r.Candidates, Candidate{Value: prefix + val})
+		}
+	}
+	if t := v.Tag.Type; t == pathType || t == existingFileType || v.Target.Type() == fileType {
+		r.Files = true
+	}
+	r.Candidates = filter(r.Candidates, prefix+cur)
+	return r
+}
+
+// lookupFlag returns the flag of the supplied node or its ancestors that the
+// supplied word sets, the value it sets inline, and whether a value was set
+// inline. Combined short flags are not supported.
+func lookupFlag(node *kong.Node, word string) (*kong.Flag, string, bool) {
+	name, value, inline := strings.Cut(strings.TrimLeft(word, "-"), "=")
+	long := strings.HasPrefix(word, "--")
+	if !long && len([]rune(name)) != 1 {
+		return nil, "", false
+	}
+	for _, group := range node.AllFlags(false) {
+		for _, f := range group {
+			if (long && f.Name == name) || (!long && f.Short == []rune(name)[0]) {
+				return f, value, inline
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// findChild returns the child command of the supplied node with the supplied
+// name or alias.
+func findChild(node *kong.Node, name string) *kong.Node {
+	for _, c := range node.Children {
+		if c.Type != kong.CommandNode {
+			continue
+		}
+		if c.Name == name {
+			return c
+		}
+		for _, a := range c.Aliases {
+			if a == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// positionalAt returns the positional argument of the supplied node at the
+// supplied position. The last positional argument completes all positions
+// after it if it accepts multiple values.
+func positionalAt(node *kong.Node, i int) *kong.Value {
+	if n := len(node.Positional); n > 0 && i >= n && node.Positional[n-1].IsSlice() {
+		return node.Positional[n-1]
+	}
+	if i < len(node.Positional) {
+		return node.Positional[i]
+	}
+	return nil
+}
+
+// commands returns the visible child commands of the supplied node.
+func commands(node *kong.Node) []Candidate {
+	var cs []Candidate
+	for _, c := range node.Children {
+		if c.Hidden || c.Type != kong.CommandNode {
+			continue
+		}
+		cs = append(cs, Candidate{Value: c.Name, Description: c.Help})
+	}
+	return cs
+}
+
+// flags returns the visible flags of the supplied node and its ancestors.
+func flags(node *kong.Node) []Candidate {
+	var cs []Candidate
+	for _, group := range node.AllFlags(true) {
+		for _, f := range group {
+			cs = append(cs, Candidate{Value: "--" + f.Name, Description: f.Help})
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Value < cs[j].Value })
+	return cs
+}
+
+// filter returns the candidates that complete the supplied prefix.
+func filter(cs []Candidate, prefix string) []Candidate {
+	out := make([]Candidate, 0, len(cs))
+	for _, c := range cs {
+		if strings.HasPrefix(c.Value, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
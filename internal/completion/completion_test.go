@@ -0,0 +1,152 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type testGetCmd struct {
+	Name string `arg:"" predictor:"names" help:"Name of the thing."`
+
+	Output string `short:"o" enum:"json,yaml" default:"json" help:"Output format."`
+	File   string `type:"path" help:"Path to a file."`
+}
+
+type testNewCmd struct{}
+
+type testThingCmd struct {
+	Get testGetCmd `cmd:"" help:"Get a thing."`
+	New testNewCmd `cmd:"" maturity:"alpha" help:"Create a thing."`
+
+	Profile string `help:"Profile to use."`
+}
+
+type testAlpha struct {
+	Thing testThingCmd `cmd:"" maturity:"alpha" help:"Things."`
+}
+
+type testCLI struct {
+	Quiet bool `short:"q" help:"Quiet."`
+
+	Thing  testThingCmd `cmd:"" aliases:"t" help:"Things."`
+	Secret testNewCmd   `cmd:"" hidden:"" help:"Secret."`
+	Alpha  testAlpha    `cmd:"" selects-maturity:"alpha" help:"Alpha things."`
+}
+
+func TestComplete(t *testing.T) {
+	names := func(_ context.Context, a Args) ([]string, error) {
+		return []string{"one", "two", a.Flags["profile"]}, nil
+	}
+
+	cases := map[string]struct {
+		reason string
+		words  []string
+		want   Result
+	}{
+		"Commands": {
+			reason: "Visible commands should be completed.",
+			words:  []string{""},
+			want: Result{Candidates: []Candidate{
+				{Value: "thing", Description: "Things."},
+				{Value: "alpha", Description: "Alpha things."},
+			}},
+		},
+		"CommandPrefix": {
+			reason: "Only commands that complete the word should be completed.",
+			words:  []string{"th"},
+			want: Result{Candidates: []Candidate{
+				{Value: "thing", Description: "Things."},
+			}},
+		},
+		"StableMaturity": {
+			reason: "Alpha commands should be hidden unless alpha is selected.",
+			words:  []string{"t", ""},
+			want: Result{Candidates: []Candidate{
+				{Value: "get", Description: "Get a thing."},
+			}},
+		},
+		"AlphaMaturity": {
+			reason: "Only alpha commands should be completed once alpha is selected.",
+			words:  []string{"alpha", "thing", ""},
+			want: Result{Candidates: []Candidate{
+				{Value: "new", Description: "Create a thing."},
+			}},
+		},
+		"Flags": {
+			reason: "Visible flags of the command and its ancestors should be completed.",
+			words:  []string{"thing", "get", "--"},
+			want: Result{Candidates: []Candidate{
+				{Value: "--file", Description: "Path to a file."},
+				{Value: "--help", Description: "Show context-sensitive help."},
+				{Value: "--output", Description: "Output format."},
+				{Value: "--profile", Description: "Profile to use."},
+				{Value: "--quiet", Description: "Quiet."},
+			}},
+		},
+		"EnumFlagValue": {
+			reason: "The values of an enum flag should be completed.",
+			words:  []string{"thing", "get", "-o", ""},
+			want: Result{Candidates: []Candidate{
+				{Value: "json"},
+				{Value: "yaml"},
+			}},
+		},
+		"InlineFlagValue": {
+			reason: "Values supplied inline with a flag should be completed with the flag.",
+			words:  []string{"thing", "get", "--output=y"},
+			want: Result{Candidates: []Candidate{
+				{Value: "--output=yaml"},
+			}},
+		},
+		"PathFlagValue": {
+			reason: "The values of path flags should be completed with file names.",
+			words:  []string{"thing", "get", "--file", ""},
+			want:   Result{Files: true},
+		},
+		"PredictedArgument": {
+			reason: "Positional arguments should be completed by their predictor, which receives the supplied flags.",
+			words:  []string{"thing", "--profile", "three", "get", "-q", ""},
+			want: Result{Candidates: []Candidate{
+				{Value: "one"},
+				{Value: "two"},
+				{Value: "three"},
+			}},
+		},
+		"ExtraArgument": {
+			reason: "Arguments beyond those of the command should not be completed.",
+			words:  []string{"thing", "get", "one", ""},
+			want:   Result{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parser, err := kong.New(&testCLI{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := New(parser.Model, WithPredictor("names", names)).Complete(context.Background(), tc.words)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nComplete(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
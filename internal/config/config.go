@@ -20,6 +20,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -36,6 +38,7 @@ const (
 	errInvalidProfile     = "profile is not valid"
 
 	errProfileNotFoundFmt = "profile not found with identifier: %s"
+	errProfileExistsFmt   = "profile already exists with identifier: %s"
 	errNoProfilesFound    = "no profiles found"
 )
 
@@ -44,6 +47,10 @@ type QuietFlag bool
 
 // Config is format for the up configuration file.
 type Config struct {
+	// Version is the version of the format of the configuration file. Files
+	// written before the format was versioned have no version.
+	Version int `json:"version,omitempty"`
+
 	Upbound Upbound `json:"upbound"`
 }
 
@@ -92,12 +99,31 @@ type Profile struct {
 	// Type is the type of the profile.
 	Type ProfileType `json:"type"`
 
-	// Session is a session token used to authenticate to Upbound.
+	// Session is a session token used to authenticate to Upbound. It is only
+	// persisted if the profile does not reference a secret store.
 	Session string `json:"session,omitempty"`
 
+	// SessionRef references the secret store that holds the session token,
+	// if it is not persisted in plaintext.
+	SessionRef *SecretRef `json:"sessionRef,omitempty"`
+
+	// SessionIssuedAt is when the session token was issued.
+	SessionIssuedAt *time.Time `json:"sessionIssuedAt,omitempty"`
+
+	// SessionExpiresAt is when the session token expires.
+	SessionExpiresAt *time.Time `json:"sessionExpiresAt,omitempty"`
+
+	// TokenSource is where the token of a token profile can be read from to
+	// re-authenticate when its session expires.
+	TokenSource *TokenSource `json:"tokenSource,omitempty"`
+
 	// Account is the default account to use when this profile is selected.
 	Account string `json:"account,omitempty"`
 
+	// Registries are the hosts of the package registries that this profile
+	// provides credentials for.
+	Registries []string `json:"registries,omitempty"`
+
 	// BaseConfig represent persisted settings for this profile.
 	// For example:
 	// * flags
@@ -105,6 +131,68 @@ type Profile struct {
 	BaseConfig map[string]string `json:"base,omitempty"`
 }
 
+// TokenSource is a source from which the token of a token profile can be read.
+type TokenSource struct {
+	// File is the path to a token credentials file, in the format generated
+	// by up robot token create.
+	File string `json:"file,omitempty"`
+}
+
+// SessionExpiryWarning is how long before a session expires that users are
+// warned about it.
+const SessionExpiryWarning = 72 * time.Hour
+
+// SessionStatus is the validity of the session of a profile.
+type SessionStatus string
+
+// Session statuses.
+const (
+	// SessionNone indicates that the profile does not have a session.
+	SessionNone SessionStatus = "None"
+	// SessionUnknown indicates that the expiry of the session is not known.
+	SessionUnknown SessionStatus = "Unknown"
+	// SessionValid indicates that the session is valid.
+	SessionValid SessionStatus = "Valid"
+	// SessionExpiring indicates that the session expires within the expiry
+	// warning period.
+	SessionExpiring SessionStatus = "Expiring"
+	// SessionExpired indicates that the session has expired.
+	SessionExpired SessionStatus = "Expired"
+)
+
+// HasSession indicates whether the profile has a session, either in plaintext
+// or in a secret store.
+func (p Profile) HasSession() bool {
+	return p.Session != "" || (p.SessionRef != nil && p.SessionRef.Key != "")
+}
+
+// SessionStatus returns the status of the session of the profile at the
+// supplied time, and how long remains until it expires.
+func (p Profile) SessionStatus(now time.Time) (SessionStatus, time.Duration) {
+	switch {
+	case !p.HasSession():
+		return SessionNone, 0
+	case p.SessionExpiresAt == nil:
+		return SessionUnknown, 0
+	}
+	remaining := p.SessionExpiresAt.Sub(now)
+	switch {
+	case remaining <= 0:
+		return SessionExpired, remaining
+	case remaining <= SessionExpiryWarning:
+		return SessionExpiring, remaining
+	}
+	return SessionValid, remaining
+}
+
+// Values that the session of a RedactedProfile is replaced with.
+const (
+	// RedactedSession replaces the session of a profile that has one.
+	RedactedSession = "REDACTED"
+	// NoSession replaces the session of a profile that does not have one.
+	NoSession = "NONE"
+)
+
 // RedactedProfile embeds a Upbound Profile for the sole purpose of redacting
 // sensitive information.
 type RedactedProfile struct {
@@ -118,9 +206,9 @@ type RedactedProfile struct {
 func (p RedactedProfile) MarshalJSON() ([]byte, error) {
 	type profile RedactedProfile
 	pc := profile(p)
-	s := "NONE"
-	if pc.Session != "" {
-		s = "REDACTED"
+	s := NoSession
+	if pc.HasSession() {
+		s = RedactedSession
 	}
 	pc.Session = s
 	return json.Marshal(&pc)
@@ -192,6 +280,101 @@ func (c *Config) SetDefaultUpboundProfile(name string) error {
 	return nil
 }
 
+// RenameUpboundProfile renames the profile that corresponds to the given name.
+// If it is the default profile the default is renamed too. An error is
+// returned if the profile does not exist or a profile with the new name
+// already exists.
+func (c *Config) RenameUpboundProfile(name, newName string) error {
+	p, ok := c.Upbound.Profiles[name]
+	if !ok {
+		return errors.Errorf(errProfileNotFoundFmt, name)
+	}
+	if _, ok := c.Upbound.Profiles[newName]; ok {
+		return errors.Errorf(errProfileExistsFmt, newName)
+	}
+	delete(c.Upbound.Profiles, name)
+	c.Upbound.Profiles[newName] = p
+	if c.Upbound.Default == name {
+		c.Upbound.Default = newName
+	}
+	return nil
+}
+
+// DeleteUpboundProfile deletes the profile that corresponds to the given
+// name. If it is the default profile there is no longer a default. An error is
+// returned if the profile does not exist.
+func (c *Config) DeleteUpboundProfile(name string) error {
+	if _, ok := c.Upbound.Profiles[name]; !ok {
+		return errors.Errorf(errProfileNotFoundFmt, name)
+	}
+	delete(c.Upbound.Profiles, name)
+	if c.Upbound.Default == name {
+		c.Upbound.Default = ""
+	}
+	return nil
+}
+
+// GetUpboundProfileForRegistry gets the profile that provides credentials for
+// the supplied registry host. If multiple profiles do, the first by name is
+// returned. False is returned if no profile provides credentials for the host.
+func (c *Config) GetUpboundProfileForRegistry(host string) (string, Profile, bool) {
+	names := make([]string, 0, len(c.Upbound.Profiles))
+	for name := range c.Upbound.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := c.Upbound.Profiles[name]
+		for _, r := range p.Registries {
+			if r == host {
+				return name, p, true
+			}
+		}
+	}
+	return "", Profile{}, false
+}
+
+// AddUpboundProfileRegistry sets the profile that corresponds to the given
+// name as the profile that provides credentials for the supplied registry
+// host. The host is removed from any other profile. If the supplied name does
+// not match an existing Profile an error is returned.
+func (c *Config) AddUpboundProfileRegistry(name, host string) error {
+	if _, ok := c.Upbound.Profiles[name]; !ok {
+		return errors.Errorf(errProfileNotFoundFmt, name)
+	}
+	c.RemoveUpboundProfileRegistry(host)
+	p := c.Upbound.Profiles[name]
+	p.Registries = append(p.Registries, host)
+	sort.Strings(p.Registries)
+	c.Upbound.Profiles[name] = p
+	return nil
+}
+
+// RemoveUpboundProfileRegistry removes the supplied registry host from all
+// profiles. It returns false if no profile provided credentials for the host.
+func (c *Config) RemoveUpboundProfileRegistry(host string) bool {
+	removed := false
+	for name, p := range c.Upbound.Profiles {
+		rs := make([]string, 0, len(p.Registries))
+		for _, r := range p.Registries {
+			if r == host {
+				removed = true
+				continue
+			}
+			rs = append(rs, r)
+		}
+		if len(rs) == len(p.Registries) {
+			continue
+		}
+		p.Registries = rs
+		if len(rs) == 0 {
+			p.Registries = nil
+		}
+		c.Upbound.Profiles[name] = p
+	}
+	return removed
+}
+
 // GetBaseConfig returns the persisted base configuration associated with the
 // provided Profile. If the supplied name does not match an existing Profile
 // an error is returned.
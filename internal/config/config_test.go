@@ -17,6 +17,7 @@ package config
 import (
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
@@ -236,6 +237,153 @@ func TestSetDefaultUpboundProfile(t *testing.T) {
 	}
 }
 
+func TestRenameUpboundProfile(t *testing.T) {
+	profOne := Profile{
+		ID:      "cool-user",
+		Type:    UserProfileType,
+		Account: "cool-org",
+	}
+
+	type args struct {
+		name    string
+		newName string
+		cfg     *Config
+	}
+	type want struct {
+		cfg *Config
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ErrorProfileNotExist": {
+			reason: "If profile does not exist an error should be returned.",
+			args: args{
+				name:    "dev",
+				newName: "staging",
+				cfg:     &Config{},
+			},
+			want: want{
+				cfg: &Config{},
+				err: errors.Errorf(errProfileNotFoundFmt, "dev"),
+			},
+		},
+		"ErrorNewNameExists": {
+			reason: "If a profile with the new name exists an error should be returned.",
+			args: args{
+				name:    "dev",
+				newName: "staging",
+				cfg: &Config{
+					Upbound: Upbound{
+						Profiles: map[string]Profile{"dev": profOne, "staging": profOne},
+					},
+				},
+			},
+			want: want{
+				cfg: &Config{
+					Upbound: Upbound{
+						Profiles: map[string]Profile{"dev": profOne, "staging": profOne},
+					},
+				},
+				err: errors.Errorf(errProfileExistsFmt, "staging"),
+			},
+		},
+		"RenamedDefault": {
+			reason: "If the default profile is renamed the default should be renamed too.",
+			args: args{
+				name:    "dev",
+				newName: "staging",
+				cfg: &Config{
+					Upbound: Upbound{
+						Default:  "dev",
+						Profiles: map[string]Profile{"dev": profOne},
+					},
+				},
+			},
+			want: want{
+				cfg: &Config{
+					Upbound: Upbound{
+						Default:  "staging",
+						Profiles: map[string]Profile{"staging": profOne},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.args.cfg.RenameUpboundProfile(tc.args.name, tc.args.newName)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRenameUpboundProfile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cfg, tc.args.cfg); diff != "" {
+				t.Errorf("\n%s\nRenameUpboundProfile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteUpboundProfile(t *testing.T) {
+	profOne := Profile{
+		ID:      "cool-user",
+		Type:    UserProfileType,
+		Account: "cool-org",
+	}
+
+	type want struct {
+		cfg *Config
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		name   string
+		cfg    *Config
+		want   want
+	}{
+		"ErrorProfileNotExist": {
+			reason: "If profile does not exist an error should be returned.",
+			name:   "dev",
+			cfg:    &Config{},
+			want: want{
+				cfg: &Config{},
+				err: errors.Errorf(errProfileNotFoundFmt, "dev"),
+			},
+		},
+		"DeletedDefault": {
+			reason: "If the default profile is deleted there should no longer be a default.",
+			name:   "dev",
+			cfg: &Config{
+				Upbound: Upbound{
+					Default:  "dev",
+					Profiles: map[string]Profile{"dev": profOne, "prod": profOne},
+				},
+			},
+			want: want{
+				cfg: &Config{
+					Upbound: Upbound{
+						Profiles: map[string]Profile{"prod": profOne},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.DeleteUpboundProfile(tc.name)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDeleteUpboundProfile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cfg, tc.cfg); diff != "" {
+				t.Errorf("\n%s\nDeleteUpboundProfile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestGetUpboundProfiles(t *testing.T) {
 	nameOne := "cool-user"
 	profOne := Profile{
@@ -516,3 +664,129 @@ func TestBaseToJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestUpboundProfileRegistries(t *testing.T) {
+	type args struct {
+		profile string
+		host    string
+		cfg     *Config
+	}
+	type want struct {
+		err      error
+		profiles map[string]Profile
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ErrorProfileNotExist": {
+			reason: "If the profile does not exist an error should be returned.",
+			args: args{
+				profile: "cool-profile",
+				host:    "xpkg.upbound.io",
+				cfg:     &Config{},
+			},
+			want: want{
+				err: errors.Errorf(errProfileNotFoundFmt, "cool-profile"),
+			},
+		},
+		"MovedFromOtherProfile": {
+			reason: "A registry should only be provided for by a single profile.",
+			args: args{
+				profile: "cool-profile",
+				host:    "xpkg.upbound.io",
+				cfg: &Config{
+					Upbound: Upbound{
+						Profiles: map[string]Profile{
+							"cool-profile":  {Registries: []string{"registry.acme.io"}},
+							"other-profile": {Registries: []string{"xpkg.upbound.io"}},
+						},
+					},
+				},
+			},
+			want: want{
+				profiles: map[string]Profile{
+					"cool-profile":  {Registries: []string{"registry.acme.io", "xpkg.upbound.io"}},
+					"other-profile": {},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.args.cfg.AddUpboundProfileRegistry(tc.args.profile, tc.args.host)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAddUpboundProfileRegistry(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.profiles, tc.args.cfg.Upbound.Profiles); diff != "" {
+				t.Errorf("\n%s\nAddUpboundProfileRegistry(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			name, _, ok := tc.args.cfg.GetUpboundProfileForRegistry(tc.args.host)
+			if diff := cmp.Diff(tc.args.profile, name); diff != "" || !ok {
+				t.Errorf("\n%s\nGetUpboundProfileForRegistry(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if !tc.args.cfg.RemoveUpboundProfileRegistry(tc.args.host) {
+				t.Errorf("\n%s\nRemoveUpboundProfileRegistry(...): registry was not removed", tc.reason)
+			}
+			if _, _, ok := tc.args.cfg.GetUpboundProfileForRegistry(tc.args.host); ok {
+				t.Errorf("\n%s\nGetUpboundProfileForRegistry(...): registry still provided for after removal", tc.reason)
+			}
+		})
+	}
+}
+
+func TestSessionStatus(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	type want struct {
+		status    SessionStatus
+		remaining time.Duration
+	}
+	cases := map[string]struct {
+		reason  string
+		profile Profile
+		want    want
+	}{
+		"None": {
+			reason:  "A profile without a session should not have a session status.",
+			profile: Profile{},
+			want:    want{status: SessionNone},
+		},
+		"Unknown": {
+			reason:  "A session without a recorded expiry should have an unknown status.",
+			profile: Profile{Session: "token"},
+			want:    want{status: SessionUnknown},
+		},
+		"Valid": {
+			reason:  "A session that does not expire soon should be valid.",
+			profile: Profile{SessionRef: &SecretRef{Store: FileSecretStoreType, Key: "up/profiles/default"}, SessionExpiresAt: expiresAt(10 * 24 * time.Hour)},
+			want:    want{status: SessionValid, remaining: 10 * 24 * time.Hour},
+		},
+		"Expiring": {
+			reason:  "A session that expires within the warning period should be expiring.",
+			profile: Profile{Session: "token", SessionExpiresAt: expiresAt(time.Hour)},
+			want:    want{status: SessionExpiring, remaining: time.Hour},
+		},
+		"Expired": {
+			reason:  "A session that has passed its expiry should be expired.",
+			profile: Profile{Session: "token", SessionExpiresAt: expiresAt(-time.Hour)},
+			want:    want{status: SessionExpired, remaining: -time.Hour},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			status, remaining := tc.profile.SessionStatus(now)
+			if diff := cmp.Diff(tc.want, want{status: status, remaining: remaining}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nSessionStatus(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
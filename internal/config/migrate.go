@@ -0,0 +1,60 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// CurrentVersion is the version of the configuration file format written by
+// this version of up.
+const CurrentVersion = 1
+
+const (
+	errUnsupportedVersionFmt = "config file version %d is newer than the supported version %d: upgrade up to use it"
+	errMigrateFmt            = "unable to migrate config file to version %d"
+)
+
+// A Migration migrates a Config from the previous version of the format to
+// version To.
+type Migration struct {
+	To      int
+	Migrate func(*Config) error
+}
+
+// Migrate applies the supplied migrations, in order, to a Config whose version
+// is lower than theirs, and sets the version of the Config to CurrentVersion.
+// It returns whether the Config was changed, in which case it should be
+// updated in its Source. A Config that is newer than CurrentVersion cannot be
+// migrated.
+func Migrate(c *Config, migrations ...Migration) (bool, error) {
+	if c.Version > CurrentVersion {
+		return false, errors.Errorf(errUnsupportedVersionFmt, c.Version, CurrentVersion)
+	}
+	if c.Version == CurrentVersion {
+		return false, nil
+	}
+	for _, m := range migrations {
+		if m.To <= c.Version || m.To > CurrentVersion {
+			continue
+		}
+		if err := m.Migrate(c); err != nil {
+			return false, errors.Wrapf(err, errMigrateFmt, m.To)
+		}
+		c.Version = m.To
+	}
+	c.Version = CurrentVersion
+	return true, nil
+}
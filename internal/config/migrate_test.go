@@ -0,0 +1,105 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestMigrate(t *testing.T) {
+	errBoom := errors.New("boom")
+	rename := Migration{To: 1, Migrate: func(c *Config) error {
+		c.Upbound.Default = "migrated"
+		return nil
+	}}
+
+	type args struct {
+		c          *Config
+		migrations []Migration
+	}
+	type want struct {
+		c        *Config
+		migrated bool
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unversioned": {
+			reason: "A config without a version should be migrated to the current version.",
+			args: args{
+				c:          &Config{Upbound: Upbound{Default: "default"}},
+				migrations: []Migration{rename},
+			},
+			want: want{
+				c:        &Config{Version: CurrentVersion, Upbound: Upbound{Default: "migrated"}},
+				migrated: true,
+			},
+		},
+		"Current": {
+			reason: "A config at the current version should not be migrated.",
+			args: args{
+				c:          &Config{Version: CurrentVersion, Upbound: Upbound{Default: "default"}},
+				migrations: []Migration{rename},
+			},
+			want: want{
+				c: &Config{Version: CurrentVersion, Upbound: Upbound{Default: "default"}},
+			},
+		},
+		"Newer": {
+			reason: "A config that is newer than the current version should return an error.",
+			args: args{
+				c: &Config{Version: CurrentVersion + 1},
+			},
+			want: want{
+				c:   &Config{Version: CurrentVersion + 1},
+				err: errors.Errorf(errUnsupportedVersionFmt, CurrentVersion+1, CurrentVersion),
+			},
+		},
+		"MigrationFailed": {
+			reason: "An error should be returned if a migration fails.",
+			args: args{
+				c: &Config{},
+				migrations: []Migration{{To: 1, Migrate: func(c *Config) error {
+					return errBoom
+				}}},
+			},
+			want: want{
+				c:   &Config{},
+				err: errors.Wrapf(errBoom, errMigrateFmt, 1),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			migrated, err := Migrate(tc.args.c, tc.args.migrations...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nMigrate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.migrated, migrated); diff != "" {
+				t.Errorf("\n%s\nMigrate(...): -want migrated, +got migrated:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, tc.args.c); diff != "" {
+				t.Errorf("\n%s\nMigrate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
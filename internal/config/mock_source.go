@@ -19,6 +19,9 @@ type MockSource struct {
 	InitializeFn   func() error
 	GetConfigFn    func() (*Config, error)
 	UpdateConfigFn func(*Config) error
+
+	ResolveSessionFn func(Profile) (string, error)
+	RemoveSessionFn  func(Profile) (Profile, error)
 }
 
 // Initialize calls the underlying initialize function.
@@ -35,3 +38,13 @@ func (m *MockSource) GetConfig() (*Config, error) {
 func (m *MockSource) UpdateConfig(c *Config) error {
 	return m.UpdateConfigFn(c)
 }
+
+// ResolveSession calls the underlying resolve session function.
+func (m *MockSource) ResolveSession(p Profile) (string, error) {
+	return m.ResolveSessionFn(p)
+}
+
+// RemoveSession calls the underlying remove session function.
+func (m *MockSource) RemoveSession(p Profile) (Profile, error) {
+	return m.RemoveSessionFn(p)
+}
@@ -0,0 +1,345 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/upbound/up/internal/input"
+)
+
+// SecretsFile is the name of the encrypted secrets file that is stored
+// alongside the up config file.
+const SecretsFile = "secrets"
+
+const (
+	// PassphraseEnv is the environment variable from which the passphrase for
+	// the encrypted file secret store is read. The passphrase is prompted for
+	// if it is not set, which fails if there is no terminal to prompt on.
+	PassphraseEnv = "UP_SECRET_PASSPHRASE"
+
+	helperPrefix   = "docker-credential-"
+	helperUsername = "up"
+
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+	saltLength   = 16
+	nonceLength  = 24
+	passphraseUI = "Secret store passphrase"
+)
+
+const (
+	errUnknownSecretStoreFmt = "unknown secret store type: %s"
+	errNoSecretHelper        = "a credential helper must be specified for the helper secret store"
+	errSecretNotFoundFmt     = "secret not found for reference: %s"
+	errReadSecrets           = "unable to read secret store"
+	errWriteSecrets          = "unable to write secret store"
+	errDecryptSecrets        = "unable to decrypt secret store: passphrase may be incorrect"
+	errEmptyPassphrase       = "secret store passphrase must not be empty"
+	errPromptPassphraseFmt   = "unable to prompt for secret store passphrase: set %s to supply it non-interactively"
+	errPromptsDisabled       = "prompting is disabled"
+)
+
+// SecretStoreType is a type of store for profile secrets.
+type SecretStoreType string
+
+// Types of secret stores.
+const (
+	// PlaintextSecretStoreType stores secrets in the config file itself.
+	PlaintextSecretStoreType SecretStoreType = "plaintext"
+	// FileSecretStoreType stores secrets in a passphrase encrypted file.
+	FileSecretStoreType SecretStoreType = "file"
+	// HelperSecretStoreType stores secrets using an external credential helper
+	// that implements the Docker credential helper protocol.
+	HelperSecretStoreType SecretStoreType = "helper"
+)
+
+// A SecretRef is a reference to a secret that is held in a secret store rather
+// than in the config file.
+type SecretRef struct {
+	// Store is the type of store the secret is held in.
+	Store SecretStoreType `json:"store"`
+
+	// Helper is the name of the credential helper used by the helper store,
+	// i.e. a helper named "pass" is invoked as docker-credential-pass.
+	Helper string `json:"helper,omitempty"`
+
+	// Key identifies the secret in its store. It is empty if no secret is
+	// currently stored.
+	Key string `json:"key,omitempty"`
+}
+
+// SecretStore stores secrets that are referenced by profiles.
+type SecretStore interface {
+	Get(key string) (string, error)
+	Store(key, secret string) error
+	Erase(key string) error
+}
+
+// SecretStoreFn returns the SecretStore that holds the secrets for the
+// supplied reference.
+type SecretStoreFn func(ref SecretRef) (SecretStore, error)
+
+// NewHelperSecretStore constructs a SecretStore that uses the supplied
+// external credential helper.
+func NewHelperSecretStore(helper string) *HelperSecretStore {
+	return &HelperSecretStore{
+		program: client.NewShellProgramFunc(helperPrefix + helper),
+	}
+}
+
+// HelperSecretStore stores secrets using an external credential helper.
+type HelperSecretStore struct {
+	program client.ProgramFunc
+}
+
+// Get gets the secret with the supplied key from the credential helper.
+func (h *HelperSecretStore) Get(key string) (string, error) {
+	creds, err := client.Get(h.program, key)
+	if credentials.IsErrCredentialsNotFound(err) {
+		return "", errors.Errorf(errSecretNotFoundFmt, key)
+	}
+	if err != nil {
+		return "", err
+	}
+	return creds.Secret, nil
+}
+
+// Store stores the secret with the supplied key in the credential helper.
+func (h *HelperSecretStore) Store(key, secret string) error {
+	return client.Store(h.program, &credentials.Credentials{
+		ServerURL: key,
+		Username:  helperUsername,
+		Secret:    secret,
+	})
+}
+
+// Erase erases the secret with the supplied key from the credential helper.
+// Erasing a secret that does not exist is not an error.
+func (h *HelperSecretStore) Erase(key string) error {
+	if _, err := client.Get(h.program, key); credentials.IsErrCredentialsNotFound(err) {
+		return nil
+	}
+	return client.Erase(h.program, key)
+}
+
+// FileSecretStoreModifier modifies a FileSecretStore.
+type FileSecretStoreModifier func(*FileSecretStore)
+
+// WithPassphrase sets the function used to obtain the passphrase for the
+// encrypted file.
+func WithPassphrase(fn func() (string, error)) FileSecretStoreModifier {
+	return func(f *FileSecretStore) {
+		f.passphraseFn = fn
+	}
+}
+
+// NewFileSecretStore constructs a SecretStore that holds secrets in a file at
+// the supplied path that is encrypted with a passphrase. By default the
+// passphrase is read from the environment, or prompted for if it is not set.
+func NewFileSecretStore(fs afero.Fs, path string, modifiers ...FileSecretStoreModifier) *FileSecretStore {
+	f := &FileSecretStore{
+		fs:           fs,
+		path:         filepath.Clean(path),
+		passphraseFn: defaultPassphrase,
+	}
+	for _, m := range modifiers {
+		m(f)
+	}
+	return f
+}
+
+// FileSecretStore stores secrets in a passphrase encrypted file. Secrets are
+// encrypted with a key derived from the passphrase using scrypt and sealed
+// with NaCl secretbox.
+type FileSecretStore struct {
+	fs           afero.Fs
+	path         string
+	passphraseFn func() (string, error)
+	passphrase   string
+}
+
+// secretsFile is the on disk format of an encrypted secrets file.
+type secretsFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// Get gets the secret with the supplied key from the encrypted file.
+func (f *FileSecretStore) Get(key string) (string, error) {
+	secrets, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	s, ok := secrets[key]
+	if !ok {
+		return "", errors.Errorf(errSecretNotFoundFmt, key)
+	}
+	return s, nil
+}
+
+// Store stores the secret with the supplied key in the encrypted file.
+func (f *FileSecretStore) Store(key, secret string) error {
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+	secrets[key] = secret
+	return f.write(secrets)
+}
+
+// Erase erases the secret with the supplied key from the encrypted file.
+// Erasing a secret that does not exist is not an error.
+func (f *FileSecretStore) Erase(key string) error {
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return f.write(secrets)
+}
+
+// read decrypts the secrets in the file. A file that does not exist holds no
+// secrets.
+func (f *FileSecretStore) read() (map[string]string, error) {
+	b, err := afero.ReadFile(f.fs, f.path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errReadSecrets)
+	}
+	sf := &secretsFile{}
+	if err := json.Unmarshal(b, sf); err != nil {
+		return nil, errors.Wrap(err, errReadSecrets)
+	}
+	key, err := f.key(sf.Salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceLength]byte
+	copy(nonce[:], sf.Nonce)
+	data, ok := secretbox.Open(nil, sf.Data, &nonce, key)
+	if !ok {
+		return nil, errors.New(errDecryptSecrets)
+	}
+	secrets := map[string]string{}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, errors.Wrap(err, errReadSecrets)
+	}
+	return secrets, nil
+}
+
+// write encrypts the supplied secrets with a fresh salt and nonce and writes
+// them to the file.
+func (f *FileSecretStore) write(secrets map[string]string) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return errors.Wrap(err, errWriteSecrets)
+	}
+	sf := &secretsFile{
+		Salt:  make([]byte, saltLength),
+		Nonce: make([]byte, nonceLength),
+	}
+	if _, err := rand.Read(sf.Salt); err != nil {
+		return errors.Wrap(err, errWriteSecrets)
+	}
+	if _, err := rand.Read(sf.Nonce); err != nil {
+		return errors.Wrap(err, errWriteSecrets)
+	}
+	key, err := f.key(sf.Salt)
+	if err != nil {
+		return err
+	}
+	var nonce [nonceLength]byte
+	copy(nonce[:], sf.Nonce)
+	sf.Data = secretbox.Seal(nil, data, &nonce, key)
+	b, err := json.Marshal(sf)
+	if err != nil {
+		return errors.Wrap(err, errWriteSecrets)
+	}
+	if err := f.fs.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return errors.Wrap(err, errWriteSecrets)
+	}
+	return errors.Wrap(afero.WriteFile(f.fs, f.path, b, 0600), errWriteSecrets)
+}
+
+// key derives the encryption key from the passphrase and supplied salt. The
+// passphrase is only obtained once per store.
+func (f *FileSecretStore) key(salt []byte) (*[keyLength]byte, error) {
+	if f.passphrase == "" {
+		p, err := f.passphraseFn()
+		if err != nil {
+			return nil, err
+		}
+		if p == "" {
+			return nil, errors.New(errEmptyPassphrase)
+		}
+		f.passphrase = p
+	}
+	k, err := scrypt.Key([]byte(f.passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	var key [keyLength]byte
+	copy(key[:], k)
+	return &key, nil
+}
+
+// defaultPassphrase reads the passphrase from the environment, falling back to
+// prompting for it on stderr.
+func defaultPassphrase() (string, error) {
+	return passphraseFrom(os.LookupEnv, input.NewStderrPrompter())
+}
+
+// envPassphrase reads the passphrase from the environment without ever
+// prompting for it.
+func envPassphrase() (string, error) {
+	return passphraseFrom(os.LookupEnv, noPrompter{})
+}
+
+// noPrompter is an input.Prompter that refuses to prompt.
+type noPrompter struct{}
+
+func (noPrompter) Prompt(string, bool) (string, error) {
+	return "", errors.New(errPromptsDisabled)
+}
+
+// passphraseFrom reads the passphrase from the environment, falling back to
+// prompting for it. Prompting fails rather than blocks if there is no terminal
+// to prompt on, in which case the passphrase must be set in the environment.
+func passphraseFrom(lookupEnv func(string) (string, bool), p input.Prompter) (string, error) {
+	if v, ok := lookupEnv(PassphraseEnv); ok {
+		return v, nil
+	}
+	v, err := p.Prompt(passphraseUI, true)
+	return v, errors.Wrapf(err, errPromptPassphraseFmt, PassphraseEnv)
+}
@@ -0,0 +1,219 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+var _ SecretStore = &FileSecretStore{}
+var _ SecretStore = &HelperSecretStore{}
+var _ SecretStore = &mockSecretStore{}
+
+// mockSecretStore is an in memory SecretStore.
+type mockSecretStore struct {
+	secrets map[string]string
+}
+
+func (m *mockSecretStore) Get(key string) (string, error) {
+	s, ok := m.secrets[key]
+	if !ok {
+		return "", errors.Errorf(errSecretNotFoundFmt, key)
+	}
+	return s, nil
+}
+
+func (m *mockSecretStore) Store(key, secret string) error {
+	m.secrets[key] = secret
+	return nil
+}
+
+func (m *mockSecretStore) Erase(key string) error {
+	delete(m.secrets, key)
+	return nil
+}
+
+func passphrase(p string) FileSecretStoreModifier {
+	return WithPassphrase(func() (string, error) { return p, nil })
+}
+
+func TestFileSecretStore(t *testing.T) {
+	path := "/.up/secrets"
+
+	type want struct {
+		secret string
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		setup  func(fs afero.Fs) error
+		read   []FileSecretStoreModifier
+		key    string
+		want   want
+	}{
+		"NotExist": {
+			reason: "Getting a secret from a store with no file should return an error.",
+			setup:  func(afero.Fs) error { return nil },
+			read:   []FileSecretStoreModifier{passphrase("correct")},
+			key:    "up/profiles/default",
+			want: want{
+				err: errors.Errorf(errSecretNotFoundFmt, "up/profiles/default"),
+			},
+		},
+		"Stored": {
+			reason: "A stored secret should be returned when the same passphrase is used.",
+			setup: func(fs afero.Fs) error {
+				return NewFileSecretStore(fs, path, passphrase("correct")).Store("up/profiles/default", "token")
+			},
+			read: []FileSecretStoreModifier{passphrase("correct")},
+			key:  "up/profiles/default",
+			want: want{
+				secret: "token",
+			},
+		},
+		"Erased": {
+			reason: "An erased secret should not be returned.",
+			setup: func(fs afero.Fs) error {
+				s := NewFileSecretStore(fs, path, passphrase("correct"))
+				if err := s.Store("up/profiles/default", "token"); err != nil {
+					return err
+				}
+				return s.Erase("up/profiles/default")
+			},
+			read: []FileSecretStoreModifier{passphrase("correct")},
+			key:  "up/profiles/default",
+			want: want{
+				err: errors.Errorf(errSecretNotFoundFmt, "up/profiles/default"),
+			},
+		},
+		"WrongPassphrase": {
+			reason: "Secrets should not be readable with a different passphrase.",
+			setup: func(fs afero.Fs) error {
+				return NewFileSecretStore(fs, path, passphrase("correct")).Store("up/profiles/default", "token")
+			},
+			read: []FileSecretStoreModifier{passphrase("incorrect")},
+			key:  "up/profiles/default",
+			want: want{
+				err: errors.New(errDecryptSecrets),
+			},
+		},
+		"EmptyPassphrase": {
+			reason: "An empty passphrase should be rejected.",
+			setup: func(fs afero.Fs) error {
+				return NewFileSecretStore(fs, path, passphrase("correct")).Store("up/profiles/default", "token")
+			},
+			read: []FileSecretStoreModifier{passphrase("")},
+			key:  "up/profiles/default",
+			want: want{
+				err: errors.New(errEmptyPassphrase),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if err := tc.setup(fs); err != nil {
+				t.Fatal(err)
+			}
+			if b, _ := afero.ReadFile(fs, path); strings.Contains(string(b), "token") {
+				t.Errorf("\n%s\nStore(...): secret persisted in plaintext", tc.reason)
+			}
+			s, err := NewFileSecretStore(fs, path, tc.read...).Get(tc.key)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.secret, s); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// promptFn is an input.Prompter that calls itself to prompt.
+type promptFn func(label string, sensitive bool) (string, error)
+
+func (fn promptFn) Prompt(label string, sensitive bool) (string, error) {
+	return fn(label, sensitive)
+}
+
+func TestPassphraseFrom(t *testing.T) {
+	errNotTTY := errors.New("refusing to prompt in non-interactive terminal")
+
+	type args struct {
+		env    map[string]string
+		prompt promptFn
+	}
+	type want struct {
+		passphrase string
+		err        error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Env": {
+			reason: "The passphrase should be read from the environment without prompting.",
+			args: args{
+				env: map[string]string{PassphraseEnv: "correct"},
+				prompt: func(string, bool) (string, error) {
+					return "", errors.New("should not prompt")
+				},
+			},
+			want: want{
+				passphrase: "correct",
+			},
+		},
+		"Prompt": {
+			reason: "The passphrase should be prompted for if it is not set in the environment.",
+			args: args{
+				prompt: func(string, bool) (string, error) { return "prompted", nil },
+			},
+			want: want{
+				passphrase: "prompted",
+			},
+		},
+		"NoTerminal": {
+			reason: "An error naming the passphrase environment variable should be returned if the passphrase cannot be prompted for.",
+			args: args{
+				prompt: func(string, bool) (string, error) { return "", errNotTTY },
+			},
+			want: want{
+				err: errors.Wrapf(errNotTTY, errPromptPassphraseFmt, PassphraseEnv),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lookupEnv := func(k string) (string, bool) {
+				v, ok := tc.args.env[k]
+				return v, ok
+			}
+			p, err := passphraseFrom(lookupEnv, tc.args.prompt)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\npassphraseFrom(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.passphrase, p); diff != "" {
+				t.Errorf("\n%s\npassphraseFrom(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
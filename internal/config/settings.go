@@ -0,0 +1,117 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// SettingsFile is the name of the file that holds system and project
+// settings. System settings are read from the system settings directory, while
+// project settings are read from the .up directory of the working directory or
+// any of its parents.
+const SettingsFile = "settings.json"
+
+const (
+	errReadSettingsFmt = "unable to read settings file: %s"
+)
+
+// SettingsLayer is a layer of configuration that settings may be set in.
+type SettingsLayer string
+
+// Layers of configuration, from lowest to highest precedence.
+const (
+	// DefaultLayer is the default value of a setting.
+	DefaultLayer SettingsLayer = "default"
+	// SystemLayer is the system settings file shared by all users.
+	SystemLayer SettingsLayer = "system"
+	// UserLayer is the base config of the user's selected profile.
+	UserLayer SettingsLayer = "user"
+	// ProjectLayer is the settings file discovered by walking up from the
+	// working directory.
+	ProjectLayer SettingsLayer = "project"
+	// EnvLayer is the environment of the process.
+	EnvLayer SettingsLayer = "env"
+	// FlagLayer is the flags supplied on the command line.
+	FlagLayer SettingsLayer = "flag"
+)
+
+// Settings are the values of settings keyed by their flag name, with hyphens
+// optionally replaced by underscores, or by their environment variable name.
+type Settings map[string]string
+
+// GetSystemSettingsPath returns the path of the system settings file.
+func GetSystemSettingsPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "up", SettingsFile)
+	}
+	return filepath.Join("/etc", "up", SettingsFile)
+}
+
+// ReadSettings reads the settings file at the supplied path. Values may be
+// any JSON scalar and are converted to strings. A file that does not exist
+// holds no settings.
+func ReadSettings(fs afero.Fs, path string) (Settings, error) {
+	b, err := afero.ReadFile(fs, path)
+	if os.IsNotExist(err) {
+		return Settings{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, errReadSettingsFmt, path)
+	}
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, errors.Wrapf(err, errReadSettingsFmt, path)
+	}
+	s := make(Settings, len(raw))
+	for k, v := range raw {
+		if v == nil {
+			continue
+		}
+		s[k] = fmt.Sprint(v)
+	}
+	return s, nil
+}
+
+// FindProjectSettings walks up from the supplied directory and returns the path
+// of the first project settings file it finds. The supplied home directory is
+// skipped, as its .up directory holds the user's configuration. No file is
+// found if no directory is supplied.
+func FindProjectSettings(fs afero.Fs, dir, home string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	dir = filepath.Clean(dir)
+	for {
+		if home == "" || dir != filepath.Clean(home) {
+			p := filepath.Join(dir, ConfigDir, SettingsFile)
+			if ok, _ := afero.Exists(fs, p); ok {
+				return p, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
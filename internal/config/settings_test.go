@@ -0,0 +1,132 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/afero"
+)
+
+func TestReadSettings(t *testing.T) {
+	type want struct {
+		settings Settings
+		err      error
+	}
+	cases := map[string]struct {
+		reason  string
+		content string
+		want    want
+	}{
+		"NotExist": {
+			reason: "A settings file that does not exist should hold no settings.",
+			want: want{
+				settings: Settings{},
+			},
+		},
+		"Scalars": {
+			reason:  "Scalar values should be converted to strings and null values ignored.",
+			content: `{"account": "my-org", "insecure_skip_tls_verify": true, "UP_DOMAIN": "https://local.upbound.io", "profile": null}`,
+			want: want{
+				settings: Settings{
+					"account":                  "my-org",
+					"insecure_skip_tls_verify": "true",
+					"UP_DOMAIN":                "https://local.upbound.io",
+				},
+			},
+		},
+		"Invalid": {
+			reason:  "A settings file that is not a JSON object should return an error.",
+			content: `["my-org"]`,
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tc.content != "" {
+				if err := afero.WriteFile(fs, "/etc/up/settings.json", []byte(tc.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s, err := ReadSettings(fs, "/etc/up/settings.json")
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadSettings(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.settings, s); diff != "" {
+				t.Errorf("\n%s\nReadSettings(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFindProjectSettings(t *testing.T) {
+	type want struct {
+		path  string
+		found bool
+	}
+	cases := map[string]struct {
+		reason string
+		files  []string
+		dir    string
+		want   want
+	}{
+		"WorkingDirectory": {
+			reason: "A settings file in the working directory should be found.",
+			files:  []string{"/src/repo/.up/settings.json"},
+			dir:    "/src/repo",
+			want: want{
+				path:  "/src/repo/.up/settings.json",
+				found: true,
+			},
+		},
+		"Nearest": {
+			reason: "The settings file nearest to the working directory should be found.",
+			files:  []string{"/src/.up/settings.json", "/src/repo/.up/settings.json"},
+			dir:    "/src/repo/apis/cluster",
+			want: want{
+				path:  "/src/repo/.up/settings.json",
+				found: true,
+			},
+		},
+		"SkipHome": {
+			reason: "The .up directory of the home directory should not be treated as a project.",
+			files:  []string{"/home/user/.up/settings.json"},
+			dir:    "/home/user/src/repo",
+		},
+		"NoDirectory": {
+			reason: "No settings file should be found if no directory is supplied.",
+			files:  []string{".up/settings.json"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for _, f := range tc.files {
+				if err := afero.WriteFile(fs, f, []byte("{}"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			path, found := FindProjectSettings(fs, tc.dir, "/home/user")
+			if diff := cmp.Diff(tc.want, want{path: path, found: found}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nFindProjectSettings(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
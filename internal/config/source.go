@@ -16,18 +16,36 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 )
 
+const (
+	sessionKeyFmt = "up/profiles/%s"
+
+	errStoreSessionFmt = "unable to store session for profile %s"
+	errResolveSession  = "unable to resolve session from secret store"
+	errEraseSession    = "unable to erase session from secret store"
+	errNoSecretStores  = "no secret stores are configured"
+)
+
 // Source is a source for interacting with a Config.
 type Source interface {
 	Initialize() error
 	GetConfig() (*Config, error)
 	UpdateConfig(*Config) error
+
+	// ResolveSession returns the session of the supplied profile, fetching it
+	// from a secret store if the profile only holds a reference to it.
+	ResolveSession(Profile) (string, error)
+	// RemoveSession erases the session of the supplied profile from its
+	// secret store and returns the profile without a session.
+	RemoveSession(Profile) (Profile, error)
 }
 
 // NewFSSource constructs a new FSSource. Path must be supplied via modifier or
@@ -40,8 +58,10 @@ type Source interface {
 // example).
 func NewFSSource(modifiers ...FSSourceModifier) *FSSource {
 	src := &FSSource{
-		fs: afero.NewOsFs(),
+		fs:     afero.NewOsFs(),
+		stores: map[SecretRef]SecretStore{},
 	}
+	src.storeFn = src.defaultSecretStore
 	for _, m := range modifiers {
 		m(src)
 	}
@@ -66,10 +86,32 @@ func WithFS(fs afero.Fs) FSSourceModifier {
 	}
 }
 
+// WithSecretStores overrides how the FSSource obtains the secret store for a
+// reference.
+func WithSecretStores(fn SecretStoreFn) FSSourceModifier {
+	return func(f *FSSource) {
+		f.storeFn = fn
+	}
+}
+
+// WithoutPrompts ensures the FSSource never prompts for input. The passphrase
+// of the encrypted file secret store must then be set in the environment.
+func WithoutPrompts() FSSourceModifier {
+	return func(f *FSSource) {
+		f.noPrompts = true
+	}
+}
+
 // FSSource provides a filesystem source for interacting with a Config.
+// Sessions of profiles that reference a secret store are held in that store
+// rather than in the config file.
 type FSSource struct {
 	fs   afero.Fs
 	path string
+
+	storeFn   SecretStoreFn
+	stores    map[SecretRef]SecretStore
+	noPrompts bool
 }
 
 // Initialize creates a config in the filesystem if one does not exist. If path
@@ -119,8 +161,14 @@ func (src *FSSource) GetConfig() (*Config, error) {
 	return conf, nil
 }
 
-// UpdateConfig updates the Config in the filesystem.
+// UpdateConfig updates the Config in the filesystem. The session of any
+// profile that references a secret store is written to that store and only
+// the reference is written to the filesystem.
 func (src *FSSource) UpdateConfig(c *Config) error {
+	c, err := src.storeSessions(c)
+	if err != nil {
+		return err
+	}
 	f, err := src.fs.OpenFile(src.path, os.O_RDWR|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
@@ -141,3 +189,111 @@ func (src *FSSource) UpdateConfig(c *Config) error {
 	}
 	return f.Close()
 }
+
+// ResolveSession returns the session of the supplied profile. Sessions stored
+// in plaintext are returned as is.
+func (src *FSSource) ResolveSession(p Profile) (string, error) {
+	if p.Session != "" || p.SessionRef == nil || p.SessionRef.Key == "" {
+		return p.Session, nil
+	}
+	store, err := src.secretStore(*p.SessionRef)
+	if err != nil {
+		return "", errors.Wrap(err, errResolveSession)
+	}
+	s, err := store.Get(p.SessionRef.Key)
+	return s, errors.Wrap(err, errResolveSession)
+}
+
+// RemoveSession erases the session of the supplied profile from its secret
+// store. The returned profile retains the type of store it references so that
+// future sessions are stored in the same way.
+func (src *FSSource) RemoveSession(p Profile) (Profile, error) {
+	p.Session = ""
+	if p.SessionRef == nil || p.SessionRef.Key == "" {
+		return p, nil
+	}
+	store, err := src.secretStore(*p.SessionRef)
+	if err != nil {
+		return p, errors.Wrap(err, errEraseSession)
+	}
+	if err := store.Erase(p.SessionRef.Key); err != nil {
+		return p, errors.Wrap(err, errEraseSession)
+	}
+	ref := *p.SessionRef
+	ref.Key = ""
+	p.SessionRef = &ref
+	return p, nil
+}
+
+// storeSessions writes the sessions of profiles that reference a secret store
+// to that store. It returns a copy of the supplied Config in which those
+// profiles only hold a reference to their session.
+func (src *FSSource) storeSessions(c *Config) (*Config, error) {
+	if c == nil || len(c.Upbound.Profiles) == 0 {
+		return c, nil
+	}
+	out := *c
+	out.Upbound.Profiles = make(map[string]Profile, len(c.Upbound.Profiles))
+	for name, p := range c.Upbound.Profiles {
+		if p.SessionRef != nil && p.SessionRef.Store == PlaintextSecretStoreType {
+			p.SessionRef = nil
+		}
+		if p.Session != "" && p.SessionRef != nil {
+			ref := *p.SessionRef
+			if ref.Key == "" {
+				ref.Key = fmt.Sprintf(sessionKeyFmt, name)
+			}
+			store, err := src.secretStore(ref)
+			if err != nil {
+				return nil, errors.Wrapf(err, errStoreSessionFmt, name)
+			}
+			if err := store.Store(ref.Key, p.Session); err != nil {
+				return nil, errors.Wrapf(err, errStoreSessionFmt, name)
+			}
+			p.Session = ""
+			p.SessionRef = &ref
+		}
+		out.Upbound.Profiles[name] = p
+	}
+	return &out, nil
+}
+
+// secretStore returns the secret store for the supplied reference. Stores are
+// reused so that, for instance, a passphrase is only requested once.
+func (src *FSSource) secretStore(ref SecretRef) (SecretStore, error) {
+	id := SecretRef{Store: ref.Store, Helper: ref.Helper}
+	if s, ok := src.stores[id]; ok {
+		return s, nil
+	}
+	if src.storeFn == nil {
+		return nil, errors.New(errNoSecretStores)
+	}
+	s, err := src.storeFn(id)
+	if err != nil {
+		return nil, err
+	}
+	if src.stores == nil {
+		src.stores = map[SecretRef]SecretStore{}
+	}
+	src.stores[id] = s
+	return s, nil
+}
+
+// defaultSecretStore constructs the secret store for the supplied reference.
+// The encrypted file store is kept alongside the config file.
+func (src *FSSource) defaultSecretStore(ref SecretRef) (SecretStore, error) {
+	switch ref.Store {
+	case FileSecretStoreType:
+		var mods []FileSecretStoreModifier
+		if src.noPrompts {
+			mods = append(mods, WithPassphrase(envPassphrase))
+		}
+		return NewFileSecretStore(src.fs, filepath.Join(filepath.Dir(src.path), SecretsFile), mods...), nil
+	case HelperSecretStoreType:
+		if ref.Helper == "" {
+			return nil, errors.New(errNoSecretHelper)
+		}
+		return NewHelperSecretStore(ref.Helper), nil
+	}
+	return nil, errors.Errorf(errUnknownSecretStoreFmt, ref.Store)
+}
@@ -168,3 +168,78 @@ func TestUpdateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestSessions(t *testing.T) {
+	ref := &SecretRef{Store: FileSecretStoreType}
+	cases := map[string]struct {
+		reason  string
+		profile Profile
+		stored  map[string]string
+		want    Profile
+	}{
+		"Plaintext": {
+			reason:  "Sessions of profiles that do not reference a secret store should be persisted in the config.",
+			profile: Profile{ID: "cool-user", Type: UserProfileType, Session: "token"},
+			stored:  map[string]string{},
+			want:    Profile{ID: "cool-user", Type: UserProfileType, Session: "token"},
+		},
+		"PlaintextRef": {
+			reason:  "Sessions of profiles that reference the plaintext store should be persisted in the config without a reference.",
+			profile: Profile{ID: "cool-user", Type: UserProfileType, Session: "token", SessionRef: &SecretRef{Store: PlaintextSecretStoreType}},
+			stored:  map[string]string{},
+			want:    Profile{ID: "cool-user", Type: UserProfileType, Session: "token"},
+		},
+		"SecretStore": {
+			reason:  "Sessions of profiles that reference a secret store should be stored there and only referenced by the config.",
+			profile: Profile{ID: "cool-user", Type: UserProfileType, Session: "token", SessionRef: ref},
+			stored:  map[string]string{"up/profiles/default": "token"},
+			want:    Profile{ID: "cool-user", Type: UserProfileType, SessionRef: &SecretRef{Store: FileSecretStoreType, Key: "up/profiles/default"}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			store := &mockSecretStore{secrets: map[string]string{}}
+			src := NewFSSource(
+				WithFS(afero.NewMemMapFs()),
+				WithPath("/.up/config.json"),
+				WithSecretStores(func(SecretRef) (SecretStore, error) { return store, nil }),
+			)
+			if err := src.Initialize(); err != nil {
+				t.Fatal(err)
+			}
+			conf := &Config{}
+			_ = conf.AddOrUpdateUpboundProfile("default", tc.profile)
+			if err := src.UpdateConfig(conf); err != nil {
+				t.Fatalf("\n%s\nUpdateConfig(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.stored, store.secrets); diff != "" {
+				t.Errorf("\n%s\nUpdateConfig(...): -want stored, +got stored:\n%s", tc.reason, diff)
+			}
+			got, err := src.GetConfig()
+			if err != nil {
+				t.Fatalf("\n%s\nGetConfig(...): %s", tc.reason, err)
+			}
+			p := got.Upbound.Profiles["default"]
+			if diff := cmp.Diff(tc.want, p); diff != "" {
+				t.Errorf("\n%s\nGetConfig(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			session, err := src.ResolveSession(p)
+			if err != nil {
+				t.Fatalf("\n%s\nResolveSession(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.profile.Session, session); diff != "" {
+				t.Errorf("\n%s\nResolveSession(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			removed, err := src.RemoveSession(p)
+			if err != nil {
+				t.Fatalf("\n%s\nRemoveSession(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(map[string]string{}, store.secrets); diff != "" {
+				t.Errorf("\n%s\nRemoveSession(...): -want stored, +got stored:\n%s", tc.reason, diff)
+			}
+			if s, _ := src.ResolveSession(removed); s != "" {
+				t.Errorf("\n%s\nRemoveSession(...): session %q still resolvable", tc.reason, s)
+			}
+		})
+	}
+}
@@ -15,6 +15,8 @@
 package credhelper
 
 import (
+	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
@@ -25,24 +27,30 @@ import (
 )
 
 const (
-	errUnimplemented     = "operation is not implemented"
-	errInitializeSource  = "unable to initialize source"
-	errExtractConfig     = "unable to extract config"
-	errGetDefaultProfile = "unable to get default profile in config"
-	errGetProfile        = "unable to get specified profile in config"
-	errUnsupportedDomain = "supplied server URL is not supported"
+	errInitializeSource   = "unable to initialize source"
+	errExtractConfig      = "unable to extract config"
+	errUpdateConfig       = "unable to update config"
+	errGetDefaultProfile  = "unable to get default profile in config"
+	errGetProfile         = "unable to get specified profile in config"
+	errResolveSession     = "unable to resolve session for profile"
+	errUnsupportedDomain  = "supplied server URL is not supported"
+	errUnsupportedUserFmt = "unsupported username %q: only session tokens may be stored with username %s"
+	errSessionConflictFmt = "profile %q already has a different session: use up login to replace it"
 )
 
 const (
 	defaultDockerUser = "_token"
+	domainKey         = "UP_DOMAIN"
+	xpkgSubdomain     = "xpkg."
 )
 
-// Helper is a docker credential helper for Upbound.
+// Helper is a docker credential helper for Upbound. Registries are mapped to
+// the profiles that provide credentials for them.
 type Helper struct {
 	log logging.Logger
 
 	profile string
-	domain  string
+	domains []string
 	src     config.Source
 }
 
@@ -56,10 +64,21 @@ func WithLogger(l logging.Logger) Opt {
 	}
 }
 
-// WithDomain sets the allowed registry domain.
+// WithDomain adds an allowed registry domain.
 func WithDomain(d string) Opt {
+	return WithDomains(d)
+}
+
+// WithDomains adds allowed registry domains. A registry is allowed if its host
+// is a domain or a subdomain of one. All registries are allowed if no domains
+// are supplied.
+func WithDomains(d ...string) Opt {
 	return func(h *Helper) {
-		h.domain = d
+		for _, dom := range d {
+			if dom != "" {
+				h.domains = append(h.domains, dom)
+			}
+		}
 	}
 }
 
@@ -91,44 +110,168 @@ func New(opts ...Opt) *Helper {
 	return h
 }
 
-// Add adds the supplied credentials.
+// Add adds the supplied credentials. The registry is mapped to the profile of
+// the helper, the profile that already provides credentials for it, or the
+// default profile, in that order. The secret is stored as the session of a
+// profile without one, while a profile with a different session is left
+// unchanged and an error is returned.
 func (h *Helper) Add(c *credentials.Credentials) error {
-	return errors.New(errUnimplemented)
+	if c.Username != defaultDockerUser {
+		return errors.Errorf(errUnsupportedUserFmt, c.Username, defaultDockerUser)
+	}
+	host := registryHost(c.ServerURL)
+	conf, err := h.config()
+	if err != nil {
+		return err
+	}
+	name, p, err := h.profileFor(conf, host)
+	if err != nil {
+		return err
+	}
+	session, err := h.src.ResolveSession(p)
+	if err != nil {
+		return errors.Wrap(err, errResolveSession)
+	}
+	switch {
+	case session == "":
+		// the secret is not issued by up, so when it expires is not known.
+		p.Session = c.Secret
+		p.SessionIssuedAt, p.SessionExpiresAt = nil, nil
+	case session != c.Secret:
+		return errors.Errorf(errSessionConflictFmt, name)
+	}
+	if err := conf.AddOrUpdateUpboundProfile(name, p); err != nil {
+		return errors.Wrap(err, errUpdateConfig)
+	}
+	if err := conf.AddUpboundProfileRegistry(name, host); err != nil {
+		return errors.Wrap(err, errUpdateConfig)
+	}
+	return errors.Wrap(h.src.UpdateConfig(conf), errUpdateConfig)
 }
 
-// Delete deletes credentials for the supplied server.
+// Delete deletes credentials for the supplied server by removing it from the
+// profile that provides credentials for it. The session of the profile is
+// retained as it may be used for other purposes.
 func (h *Helper) Delete(serverURL string) error {
-	return errors.New(errUnimplemented)
+	conf, err := h.config()
+	if err != nil {
+		return err
+	}
+	if !conf.RemoveUpboundProfileRegistry(registryHost(serverURL)) {
+		return nil
+	}
+	return errors.Wrap(h.src.UpdateConfig(conf), errUpdateConfig)
 }
 
-// List lists all the configured credentials.
+// List lists all the registries that profiles with a session provide
+// credentials for.
 func (h *Helper) List() (map[string]string, error) {
-	return nil, errors.New(errUnimplemented)
+	conf, err := h.config()
+	if err != nil {
+		return nil, err
+	}
+	l := map[string]string{}
+	for _, p := range conf.Upbound.Profiles {
+		if !p.HasSession() {
+			continue
+		}
+		for _, r := range p.Registries {
+			l[r] = defaultDockerUser
+		}
+	}
+	return l, nil
 }
 
 // Get gets credentials for the supplied server.
 func (h *Helper) Get(serverURL string) (string, string, error) {
-	if !strings.Contains(serverURL, h.domain) {
-		return "", "", errors.New(errUnsupportedDomain)
+	conf, err := h.config()
+	if err != nil {
+		return "", "", err
+	}
+	_, p, err := h.profileFor(conf, registryHost(serverURL))
+	if err != nil {
+		return "", "", err
 	}
+	session, err := h.src.ResolveSession(p)
+	if err != nil {
+		return "", "", errors.Wrap(err, errResolveSession)
+	}
+	return defaultDockerUser, session, nil
+}
+
+// config initializes the source and extracts the config from it.
+func (h *Helper) config() (*config.Config, error) {
 	if err := h.src.Initialize(); err != nil {
-		return "", "", errors.Wrap(err, errInitializeSource)
+		return nil, errors.Wrap(err, errInitializeSource)
 	}
 	conf, err := config.Extract(h.src)
-	if err != nil {
-		return "", "", errors.Wrap(err, errExtractConfig)
+	return conf, errors.Wrap(err, errExtractConfig)
+}
+
+// profileFor returns the profile that provides credentials for the supplied
+// registry host. If the helper has a profile it is always used. Otherwise the
+// profile the registry is mapped to is used, followed by a profile whose
+// domain serves the registry, and finally the default profile.
+func (h *Helper) profileFor(conf *config.Config, host string) (string, config.Profile, error) {
+	mName, mProfile, mapped := conf.GetUpboundProfileForRegistry(host)
+	if !mapped && !h.allowed(host) {
+		return "", config.Profile{}, errors.New(errUnsupportedDomain)
 	}
-	var p config.Profile
-	if h.profile == "" {
-		_, p, err = conf.GetDefaultUpboundProfile()
+	if h.profile != "" {
+		p, err := conf.GetUpboundProfile(h.profile)
 		if err != nil {
-			return "", "", errors.Wrap(err, errGetDefaultProfile)
+			return "", config.Profile{}, errors.Wrap(err, errGetProfile)
 		}
-	} else {
-		p, err = conf.GetUpboundProfile(h.profile)
-		if err != nil {
-			return "", "", errors.Wrap(err, errGetProfile)
+		return h.profile, p, nil
+	}
+	if mapped {
+		return mName, mProfile, nil
+	}
+	names := make([]string, 0, len(conf.Upbound.Profiles))
+	for name := range conf.Upbound.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if d, ok := conf.Upbound.Profiles[name].BaseConfig[domainKey]; ok && profileRegistry(d) == host {
+			return name, conf.Upbound.Profiles[name], nil
+		}
+	}
+	name, p, err := conf.GetDefaultUpboundProfile()
+	if err != nil {
+		return "", config.Profile{}, errors.Wrap(err, errGetDefaultProfile)
+	}
+	return name, p, nil
+}
+
+// allowed indicates whether the registry host is within an allowed domain.
+func (h *Helper) allowed(host string) bool {
+	if len(h.domains) == 0 {
+		return true
+	}
+	for _, d := range h.domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
 		}
 	}
-	return defaultDockerUser, p.Session, nil
+	return false
+}
+
+// profileRegistry returns the registry host for the supplied Upbound domain.
+func profileRegistry(domain string) string {
+	u, err := url.Parse(domain)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return xpkgSubdomain + u.Hostname()
+}
+
+// registryHost returns the host of the supplied server URL, which may or may
+// not include a scheme and path.
+func registryHost(serverURL string) string {
+	if u, err := url.Parse(serverURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	host, _, _ := strings.Cut(serverURL, "/")
+	return host
 }
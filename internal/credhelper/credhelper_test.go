@@ -16,6 +16,7 @@ package credhelper
 
 import (
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/docker/docker-credential-helpers/credentials"
@@ -57,6 +58,14 @@ func TestGet(t *testing.T) {
 			},
 			opts: []Opt{
 				WithDomain("registry.upbound.io"),
+				WithSource(&config.MockSource{
+					InitializeFn: func() error {
+						return nil
+					},
+					GetConfigFn: func() (*config.Config, error) {
+						return &config.Config{}, nil
+					},
+				}),
 			},
 			want: want{
 				err: errors.New(errUnsupportedDomain),
@@ -136,6 +145,37 @@ func TestGet(t *testing.T) {
 				err: errors.Wrap(errors.Errorf("profile not found with identifier: %s", testProfile), errGetProfile),
 			},
 		},
+		"ErrorResolveSession": {
+			reason: "If we fail to resolve the session of the profile return error.",
+			args: args{
+				server: testServer,
+			},
+			opts: []Opt{
+				WithProfile(testProfile),
+				WithSource(&config.MockSource{
+					InitializeFn: func() error {
+						return nil
+					},
+					GetConfigFn: func() (*config.Config, error) {
+						return &config.Config{
+							Upbound: config.Upbound{
+								Profiles: map[string]config.Profile{
+									testProfile: {
+										SessionRef: &config.SecretRef{Store: config.FileSecretStoreType, Key: testProfile},
+									},
+								},
+							},
+						}, nil
+					},
+					ResolveSessionFn: func(config.Profile) (string, error) {
+						return "", errBoom
+					},
+				}),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errResolveSession),
+			},
+		},
 		"Success": {
 			reason: "If we successfully get profile return credentials.",
 			args: args{
@@ -158,6 +198,72 @@ func TestGet(t *testing.T) {
 							},
 						}, nil
 					},
+					ResolveSessionFn: func(p config.Profile) (string, error) {
+						return p.Session, nil
+					},
+				}),
+			},
+			want: want{
+				user:   defaultDockerUser,
+				secret: testSecret,
+			},
+		},
+		"SuccessMappedRegistry": {
+			reason: "If a profile provides credentials for the registry it should be used rather than the default.",
+			args: args{
+				server: "https://registry.acme.io/v2/",
+			},
+			opts: []Opt{
+				WithDomain("upbound.io"),
+				WithSource(&config.MockSource{
+					InitializeFn: func() error {
+						return nil
+					},
+					GetConfigFn: func() (*config.Config, error) {
+						return &config.Config{
+							Upbound: config.Upbound{
+								Default: "default",
+								Profiles: map[string]config.Profile{
+									"default": {Session: "default"},
+									"acme":    {Session: testSecret, Registries: []string{"registry.acme.io"}},
+								},
+							},
+						}, nil
+					},
+					ResolveSessionFn: func(p config.Profile) (string, error) {
+						return p.Session, nil
+					},
+				}),
+			},
+			want: want{
+				user:   defaultDockerUser,
+				secret: testSecret,
+			},
+		},
+		"SuccessProfileDomain": {
+			reason: "If the domain of a profile serves the registry it should be used rather than the default.",
+			args: args{
+				server: "xpkg.acme.io",
+			},
+			opts: []Opt{
+				WithSource(&config.MockSource{
+					InitializeFn: func() error {
+						return nil
+					},
+					GetConfigFn: func() (*config.Config, error) {
+						return &config.Config{
+							Upbound: config.Upbound{
+								Default: "default",
+								Profiles: map[string]config.Profile{
+									"default":    {Session: "default"},
+									"selfhosted": {Session: testSecret, BaseConfig: map[string]string{"UP_DOMAIN": "https://acme.io"}},
+								},
+							},
+						}, nil
+					},
+					ResolveSessionFn: func(p config.Profile) (string, error) {
+						return p.Session, nil
+					},
 				}),
 			},
 			want: want{
@@ -183,22 +289,244 @@ func TestGet(t *testing.T) {
 }
 
 func TestAdd(t *testing.T) {
-	err := New().Add(nil)
-	if diff := cmp.Diff(errors.New(errUnimplemented), err, test.EquateErrors()); diff != "" {
-		t.Errorf("\nAdd(...): -want error, +got error:\n%s", diff)
+	testServer := "https://xpkg.upbound.io"
+	testSecret := "supersecretvalue"
+	issued, expires := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	type args struct {
+		profiles map[string]config.Profile
+		creds    *credentials.Credentials
+		opts     []Opt
+	}
+	type want struct {
+		conf *config.Config
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ErrorUnsupportedUser": {
+			reason: "Should return error if credentials are not for a session token.",
+			args: args{
+				profiles: map[string]config.Profile{
+					"default": {ID: "cool-user", Type: config.UserProfileType},
+				},
+				creds: &credentials.Credentials{
+					ServerURL: testServer,
+					Username:  "cool-user",
+					Secret:    testSecret,
+				},
+			},
+			want: want{
+				err: errors.Errorf(errUnsupportedUserFmt, "cool-user", defaultDockerUser),
+			},
+		},
+		"SuccessDefault": {
+			reason: "If no profile provides credentials for the registry, it should be mapped to the default profile, which is given the secret as its session if it has none.",
+			args: args{
+				profiles: map[string]config.Profile{
+					"default": {ID: "cool-user", Type: config.UserProfileType, SessionIssuedAt: &issued, SessionExpiresAt: &expires},
+					"other":   {ID: "cool-user", Type: config.UserProfileType, Session: "other"},
+				},
+				creds: &credentials.Credentials{
+					ServerURL: testServer,
+					Username:  defaultDockerUser,
+					Secret:    testSecret,
+				},
+			},
+			want: want{
+				conf: &config.Config{
+					Upbound: config.Upbound{
+						Default: "default",
+						Profiles: map[string]config.Profile{
+							"default": {ID: "cool-user", Type: config.UserProfileType, Session: testSecret, Registries: []string{"xpkg.upbound.io"}},
+							"other":   {ID: "cool-user", Type: config.UserProfileType, Session: "other"},
+						},
+					},
+				},
+			},
+		},
+		"SuccessDefaultSameSession": {
+			reason: "If the default profile already has the secret as its session, the registry should be mapped to it and its session left as is.",
+			args: args{
+				profiles: map[string]config.Profile{
+					"default": {ID: "cool-user", Type: config.UserProfileType, Session: testSecret, SessionIssuedAt: &issued, SessionExpiresAt: &expires},
+				},
+				creds: &credentials.Credentials{
+					ServerURL: testServer,
+					Username:  defaultDockerUser,
+					Secret:    testSecret,
+				},
+			},
+			want: want{
+				conf: &config.Config{
+					Upbound: config.Upbound{
+						Default: "default",
+						Profiles: map[string]config.Profile{
+							"default": {ID: "cool-user", Type: config.UserProfileType, Session: testSecret, SessionIssuedAt: &issued, SessionExpiresAt: &expires, Registries: []string{"xpkg.upbound.io"}},
+						},
+					},
+				},
+			},
+		},
+		"ErrorDefaultSessionConflict": {
+			reason: "The session of the default profile should not be replaced by a different secret.",
+			args: args{
+				profiles: map[string]config.Profile{
+					"default": {ID: "cool-user", Type: config.UserProfileType, Session: "api-session"},
+				},
+				creds: &credentials.Credentials{
+					ServerURL: testServer,
+					Username:  defaultDockerUser,
+					Secret:    testSecret,
+				},
+			},
+			want: want{
+				err: errors.Errorf(errSessionConflictFmt, "default"),
+			},
+		},
+		"SuccessProfile": {
+			reason: "If the helper has a profile, the registry should be mapped to it.",
+			args: args{
+				profiles: map[string]config.Profile{
+					"default": {ID: "cool-user", Type: config.UserProfileType},
+					"other":   {ID: "cool-user", Type: config.UserProfileType},
+				},
+				creds: &credentials.Credentials{
+					ServerURL: testServer,
+					Username:  defaultDockerUser,
+					Secret:    testSecret,
+				},
+				opts: []Opt{WithProfile("other")},
+			},
+			want: want{
+				conf: &config.Config{
+					Upbound: config.Upbound{
+						Default: "default",
+						Profiles: map[string]config.Profile{
+							"default": {ID: "cool-user", Type: config.UserProfileType},
+							"other":   {ID: "cool-user", Type: config.UserProfileType, Session: testSecret, Registries: []string{"xpkg.upbound.io"}},
+						},
+					},
+				},
+			},
+		},
+		"ErrorProfileSessionConflict": {
+			reason: "The session of the profile of the helper should not be replaced by a different secret.",
+			args: args{
+				profiles: map[string]config.Profile{
+					"default": {ID: "cool-user", Type: config.UserProfileType},
+					"other":   {ID: "cool-user", Type: config.UserProfileType, Session: "other"},
+				},
+				creds: &credentials.Credentials{
+					ServerURL: testServer,
+					Username:  defaultDockerUser,
+					Secret:    testSecret,
+				},
+				opts: []Opt{WithProfile("other")},
+			},
+			want: want{
+				err: errors.Errorf(errSessionConflictFmt, "other"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *config.Config
+			src := &config.MockSource{
+				InitializeFn: func() error {
+					return nil
+				},
+				GetConfigFn: func() (*config.Config, error) {
+					return &config.Config{
+						Upbound: config.Upbound{
+							Default:  "default",
+							Profiles: tc.args.profiles,
+						},
+					}, nil
+				},
+				ResolveSessionFn: func(p config.Profile) (string, error) {
+					return p.Session, nil
+				},
+				UpdateConfigFn: func(c *config.Config) error {
+					got = c
+					return nil
+				},
+			}
+			err := New(append([]Opt{WithSource(src)}, tc.args.opts...)...).Add(tc.args.creds)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAdd(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conf, got); diff != "" {
+				t.Errorf("\n%s\nAdd(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
 	}
 }
 
 func TestDelete(t *testing.T) {
-	err := New().Delete("")
-	if diff := cmp.Diff(errors.New(errUnimplemented), err, test.EquateErrors()); diff != "" {
+	var got *config.Config
+	src := &config.MockSource{
+		InitializeFn: func() error {
+			return nil
+		},
+		GetConfigFn: func() (*config.Config, error) {
+			return &config.Config{
+				Upbound: config.Upbound{
+					Profiles: map[string]config.Profile{
+						"default": {Session: "token", Registries: []string{"registry.acme.io", "xpkg.upbound.io"}},
+					},
+				},
+			}, nil
+		},
+		UpdateConfigFn: func(c *config.Config) error {
+			got = c
+			return nil
+		},
+	}
+	want := &config.Config{
+		Upbound: config.Upbound{
+			Profiles: map[string]config.Profile{
+				"default": {Session: "token", Registries: []string{"registry.acme.io"}},
+			},
+		},
+	}
+	err := New(WithSource(src)).Delete("https://xpkg.upbound.io")
+	if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
 		t.Errorf("\nDelete(...): -want error, +got error:\n%s", diff)
 	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("\nDelete(...): -want, +got:\n%s", diff)
+	}
 }
 
 func TestList(t *testing.T) {
-	_, err := New().List()
-	if diff := cmp.Diff(errors.New(errUnimplemented), err, test.EquateErrors()); diff != "" {
+	src := &config.MockSource{
+		InitializeFn: func() error {
+			return nil
+		},
+		GetConfigFn: func() (*config.Config, error) {
+			return &config.Config{
+				Upbound: config.Upbound{
+					Profiles: map[string]config.Profile{
+						"default":    {Session: "token", Registries: []string{"xpkg.upbound.io"}},
+						"selfhosted": {SessionRef: &config.SecretRef{Store: config.FileSecretStoreType, Key: "up/profiles/selfhosted"}, Registries: []string{"xpkg.acme.io"}},
+						"loggedout":  {Registries: []string{"registry.acme.io"}},
+					},
+				},
+			}, nil
+		},
+	}
+	want := map[string]string{
+		"xpkg.upbound.io": defaultDockerUser,
+		"xpkg.acme.io":    defaultDockerUser,
+	}
+	l, err := New(WithSource(src)).List()
+	if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
 		t.Errorf("\nList(...): -want error, +got error:\n%s", diff)
 	}
+	if diff := cmp.Diff(want, l); diff != "" {
+		t.Errorf("\nList(...): -want, +got:\n%s", diff)
+	}
 }
@@ -21,6 +21,10 @@ import (
 // maturityTag is the struct field tag used to specify maturity of a command.
 const maturityTag = "maturity"
 
+// selectsMaturityTag is the struct field tag used to specify the maturity of
+// the commands below a command, such as alpha.
+const selectsMaturityTag = "selects-maturity"
+
 // Maturity is the maturity of a feature.
 type Maturity string
 
@@ -53,3 +57,12 @@ func GetMaturity(n *kong.Node) Maturity {
 	}
 	return Stable
 }
+
+// SelectedMaturity returns the maturity level selected by the supplied
+// command, or the supplied current maturity level if it does not select one.
+func SelectedMaturity(n *kong.Node, current Maturity) Maturity {
+	if m := Maturity(n.Tag.Get(selectsMaturityTag)); m != "" {
+		return m
+	}
+	return current
+}
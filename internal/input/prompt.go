@@ -78,8 +78,18 @@ func NewPrompter() Prompter {
 	}
 }
 
-// defaultPrompter is a prompter that uses stdin for input and stdout for
-// output.
+// NewStderrPrompter constructs a new prompter that uses stdin for input and
+// stderr for output, so that prompts are not mixed into output that may be
+// redirected or captured.
+func NewStderrPrompter() Prompter {
+	return &defaultPrompter{
+		in:  os.Stdin,
+		out: os.Stderr,
+		tty: defaultTTY{},
+	}
+}
+
+// defaultPrompter is a prompter that reads input from a terminal.
 type defaultPrompter struct {
 	in  file
 	out file
@@ -66,16 +66,7 @@ func NewImagePullApplicator(secret *SecretApplicator) *ImagePullApplicator {
 // Apply constructs an DockerConfig image pull Secret with the provided registry
 // and credentials.
 func (i *ImagePullApplicator) Apply(ctx context.Context, name, ns, user, pass, registry string) error {
-	regAuth := &create.DockerConfigJSON{
-		Auths: map[string]create.DockerConfigEntry{
-			registry: {
-				Username: user,
-				Password: pass,
-				Auth:     encodeDockerConfigFieldAuth(user, pass),
-			},
-		},
-	}
-	regAuthJSON, err := json.Marshal(regAuth)
+	regAuthJSON, err := DockerConfigJSON(user, pass, registry)
 	if err != nil {
 		return err
 	}
@@ -93,6 +84,20 @@ func (i *ImagePullApplicator) Apply(ctx context.Context, name, ns, user, pass, r
 	return i.secret.Apply(ctx, ns, secret)
 }
 
+// DockerConfigJSON constructs a docker config that authenticates to the
+// provided registry with the provided credentials.
+func DockerConfigJSON(user, pass, registry string) ([]byte, error) {
+	return json.Marshal(&create.DockerConfigJSON{
+		Auths: map[string]create.DockerConfigEntry{
+			registry: {
+				Username: user,
+				Password: pass,
+				Auth:     encodeDockerConfigFieldAuth(user, pass),
+			},
+		},
+	})
+}
+
 // encodeDockerConfigFieldAuth returns base64 encoding of the username and
 // password string
 // NOTE(hasheddan): this function comes directly from kubectl
@@ -15,15 +15,20 @@
 package upbound
 
 import (
-	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	"io/fs"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
 	"github.com/spf13/afero"
 
 	"github.com/upbound/up-sdk-go"
@@ -51,13 +56,26 @@ const (
 
 const (
 	errProfileNotFoundFmt = "profile not found with identifier: %s"
+	errResolveSessionFmt  = "unable to resolve session for profile: %s"
+	errMigrateConfig      = "unable to write migrated config file"
+
+	warnProjectSettingFmt = "ignoring %q in project settings file %s: only account may be set by a project"
 )
 
+// projectKeys are the settings that a project settings file may set. A project
+// settings file is read from the working directory or any of its parents, so
+// it must not be able to select a profile or redirect or weaken the connection
+// to Upbound that a profile's session is sent over.
+var projectKeys = map[string]bool{
+	"account":    true,
+	"UP_ACCOUNT": true,
+}
+
 // Flags are common flags used by commands that interact with Upbound.
 type Flags struct {
 	// Optional
 	Domain  *url.URL `env:"UP_DOMAIN" default:"https://upbound.io" help:"Root Upbound domain."`
-	Profile string   `env:"UP_PROFILE" help:"Profile used to execute command."`
+	Profile string   `env:"UP_PROFILE" predictor:"profiles" help:"Profile used to execute command."`
 	Account string   `short:"a" env:"UP_ACCOUNT" help:"Account used to execute command."`
 
 	// Insecure
@@ -85,9 +103,19 @@ type Context struct {
 	Cfg              *config.Config
 	CfgSrc           config.Source
 
+	// Settings are the effective values of the flags and the layers of
+	// configuration they were resolved from.
+	Settings []Setting
+
 	allowMissingProfile bool
+	skipSessionWarning  bool
+	readOnly            bool
 	cfgPath             string
+	systemPath          string
+	wd                  string
+	lookupEnv           func(string) (string, bool)
 	fs                  afero.Fs
+	secretStores        config.SecretStoreFn
 }
 
 // Option modifies a Context
@@ -101,7 +129,29 @@ func AllowMissingProfile() Option {
 	}
 }
 
-// NewFromFlags constructs a new context from flags.
+// SkipSessionWarning indicates that no warning should be emitted if the session
+// of the profile has expired or expires soon, i.e. because the command renews
+// or invalidates it.
+func SkipSessionWarning() Option {
+	return func(ctx *Context) {
+		ctx.skipSessionWarning = true
+	}
+}
+
+// ReadOnly indicates that the Context must neither write config nor prompt for
+// input, i.e. because it is used for shell completion. Config written by an
+// earlier version of up is migrated in memory only, and sessions held in the
+// encrypted file secret store can only be resolved if its passphrase is set in
+// the environment.
+func ReadOnly() Option {
+	return func(ctx *Context) {
+		ctx.readOnly = true
+	}
+}
+
+// NewFromFlags constructs a new context from flags. Flags are layered over the
+// project settings file discovered from the working directory, the base config
+// of the selected profile, and the system settings file, in that order.
 func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 	p, err := config.GetDefaultPath()
 	if err != nil {
@@ -109,29 +159,73 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 	}
 
 	c := &Context{
-		fs:      afero.NewOsFs(),
-		cfgPath: p,
+		fs:         afero.NewOsFs(),
+		cfgPath:    p,
+		systemPath: config.GetSystemSettingsPath(),
+		lookupEnv:  os.LookupEnv,
 	}
 
 	for _, o := range opts {
 		o(c)
 	}
 
-	src := config.NewFSSource(
+	// The project settings file is optional, so it is not discovered if the
+	// working directory cannot be determined.
+	if c.wd == "" {
+		c.wd, _ = os.Getwd()
+	}
+
+	srcOpts := []config.FSSourceModifier{
 		config.WithFS(c.fs),
 		config.WithPath(c.cfgPath),
-	)
-	if err := src.Initialize(); err != nil {
-		return nil, err
+	}
+	if c.secretStores != nil {
+		srcOpts = append(srcOpts, config.WithSecretStores(c.secretStores))
+	}
+	if c.readOnly {
+		srcOpts = append(srcOpts, config.WithoutPrompts())
+	}
+	src := config.NewFSSource(srcOpts...)
+	if !c.readOnly {
+		if err := src.Initialize(); err != nil {
+			return nil, err
+		}
 	}
 	conf, err := config.Extract(src)
+	if c.readOnly && errors.Is(err, fs.ErrNotExist) {
+		conf, err = &config.Config{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	// Config files written by earlier versions of up are migrated to the
+	// current format the first time they are read.
+	migrated, err := config.Migrate(conf, Migrations()...)
+	if err != nil {
+		return nil, err
+	}
+	if migrated && !c.readOnly {
+		if err := src.UpdateConfig(conf); err != nil {
+			return nil, errors.Wrap(err, errMigrateConfig)
+		}
+	}
 
 	c.Cfg = conf
 	c.CfgSrc = src
 
+	layers, err := c.layers(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// The profile is resolved before the base config of the user's profile is
+	// layered in, as a profile cannot select itself.
+	pf, _, err := resolveFlags(layers)
+	if err != nil {
+		return nil, err
+	}
+	f.Profile = pf.Profile
+
 	// If profile identifier is not provided, use the default, or empty if the
 	// default cannot be obtained.
 	c.Profile = config.Profile{}
@@ -149,10 +243,19 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 		c.ProfileName = f.Profile
 	}
 
-	of, err := c.applyOverrides(f, c.ProfileName)
+	// The session is only resolved from its secret store when it is used, as
+	// a store may prompt for a passphrase.
+	if w := c.SessionWarning(time.Now()); w != "" && !c.skipSessionWarning {
+		pterm.Warning.WithWriter(os.Stderr).Println(w)
+	}
+
+	// The user's layer takes precedence over the system settings only.
+	layers = append(layers[:len(layers)-1], c.userLayer(), layers[len(layers)-1])
+	of, settings, err := resolveFlags(layers)
 	if err != nil {
 		return nil, err
 	}
+	c.Settings = settings
 
 	c.APIEndpoint = of.APIEndpoint
 	if c.APIEndpoint == nil {
@@ -189,9 +292,27 @@ func NewFromFlags(f Flags, opts ...Option) (*Context, error) { //nolint:gocyclo
 	return c, nil
 }
 
+// Session returns the session of the profile, resolving it from its secret
+// store the first time it is called.
+func (c *Context) Session() (string, error) {
+	if c.Profile.Session != "" || c.CfgSrc == nil {
+		return c.Profile.Session, nil
+	}
+	session, err := c.CfgSrc.ResolveSession(c.Profile)
+	if err != nil {
+		return "", errors.Wrapf(err, errResolveSessionFmt, c.ProfileName)
+	}
+	c.Profile.Session = session
+	return session, nil
+}
+
 // BuildSDKConfig builds an Upbound SDK config suitable for usage with any
-// service client.
-func (c *Context) BuildSDKConfig(session string) (*up.Config, error) {
+// service client. It is authenticated with the session of the profile.
+func (c *Context) BuildSDKConfig() (*up.Config, error) {
+	session, err := c.Session()
+	if err != nil {
+		return nil, err
+	}
 	cj, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, err
@@ -209,8 +330,14 @@ func (c *Context) BuildSDKConfig(session string) (*up.Config, error) {
 	client := up.NewClient(func(u *up.HTTPClient) {
 		u.BaseURL = c.APIEndpoint
 		u.HTTP = &http.Client{
-			Jar:       cj,
-			Transport: tr,
+			Jar: cj,
+			// Token profiles are transparently re-authenticated from their
+			// token source if their session has expired.
+			Transport: &reauthTransport{
+				base:  tr,
+				jar:   cj,
+				upCtx: c,
+			},
 		}
 		u.UserAgent = UserAgent
 	})
@@ -219,40 +346,77 @@ func (c *Context) BuildSDKConfig(session string) (*up.Config, error) {
 	}), nil
 }
 
-// applyOverrides applies applicable overrides to the given Flags based on the
-// pre-existing configs, if there are any.
-func (c *Context) applyOverrides(f Flags, profileName string) (Flags, error) {
-	// profile doesn't exist, return the supplied flags
-	if _, ok := c.Cfg.Upbound.Profiles[profileName]; !ok {
-		return f, nil
+// layers returns the layers of configuration other than the user's, from
+// highest to lowest precedence. The system settings are always the last layer.
+func (c *Context) layers(f Flags) ([]layer, error) {
+	env, flags, err := envAndFlagSettings(f, c.lookupEnv)
+	if err != nil {
+		return nil, err
 	}
-
-	of := Flags{}
-
-	baseReader, err := c.Cfg.BaseToJSON(profileName)
+	layers := []layer{
+		{name: config.FlagLayer, values: flags},
+		{name: config.EnvLayer, values: env},
+	}
+	// The home directory is the parent of the directory that holds the user's
+	// config file.
+	home := filepath.Dir(filepath.Dir(c.cfgPath))
+	if p, ok := config.FindProjectSettings(c.fs, c.wd, home); ok {
+		s, err := config.ReadSettings(c.fs, p)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(s))
+		for k := range s {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !projectKeys[k] {
+				pterm.Warning.WithWriter(os.Stderr).Printfln(warnProjectSettingFmt, k, p)
+				delete(s, k)
+			}
+		}
+		layers = append(layers, layer{name: config.ProjectLayer, source: p, values: s})
+	}
+	s, err := config.ReadSettings(c.fs, c.systemPath)
 	if err != nil {
-		return of, err
+		return nil, err
 	}
+	return append(layers, layer{name: config.SystemLayer, source: c.systemPath, values: s}), nil
+}
 
-	overlayBytes, err := json.Marshal(f)
-	if err != nil {
-		return of, err
+// userLayer returns the layer of the base config of the selected profile.
+// Profiles may not select another profile, so the profile setting is ignored.
+func (c *Context) userLayer() layer {
+	values := config.Settings{}
+	for k, v := range c.Profile.BaseConfig {
+		values[k] = v
+	}
+	for _, k := range []string{"profile", "UP_PROFILE"} {
+		delete(values, k)
 	}
+	return layer{name: config.UserLayer, source: c.ProfileName, values: values}
+}
 
-	resolver, err := JSON(baseReader, bytes.NewReader(overlayBytes))
+// resolveFlags resolves Flags from the supplied layers, returning the settings
+// they were resolved from in the order the flags are declared.
+func resolveFlags(layers []layer) (Flags, []Setting, error) {
+	f := Flags{}
+	resolved := map[string]Setting{}
+	parser, err := kong.New(&f, kong.Resolvers(layered(layers, resolved)))
 	if err != nil {
-		return of, err
+		return f, nil, err
 	}
-	parser, err := kong.New(&of, kong.Resolvers(resolver))
-	if err != nil {
-		return of, err
+	if _, err := parser.Parse([]string{}); err != nil {
+		return f, nil, err
 	}
-
-	if _, err = parser.Parse([]string{}); err != nil {
-		return of, err
+	settings := make([]Setting, 0, len(resolved))
+	for _, flag := range parser.Model.Flags {
+		if s, ok := resolved[flag.Name]; ok {
+			settings = append(settings, s)
+		}
 	}
-
-	return of, nil
+	return f, settings, nil
 }
 
 // MarshalJSON marshals the Flags struct, converting the url.URL to strings.
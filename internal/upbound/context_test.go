@@ -16,7 +16,9 @@ package upbound
 
 import (
 	"fmt"
+	iofs "io/fs"
 	"net/url"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -72,6 +74,22 @@ var (
 		}
 	  }
 	`
+	legacyConfigJSON = `{
+		"upbound": {
+		  "default": "default",
+		  "profiles": {
+			"default": {
+			  "id": "someone@upbound.io",
+			  "type": "user",
+			  "session": "a token",
+			  "base": {
+				"account": "my-org"
+			  }
+			}
+		  }
+		}
+	  }
+	`
 )
 
 func withConfig(config string) Option {
@@ -99,6 +117,40 @@ func withPath(p string) Option {
 	}
 }
 
+func withFile(path, content string) Option {
+	return func(ctx *Context) {
+		_ = ctx.fs.MkdirAll(filepath.Dir(path), 0755)
+		_ = afero.WriteFile(ctx.fs, path, []byte(content), 0644)
+	}
+}
+
+func withEnv(env map[string]string) Option {
+	return func(ctx *Context) {
+		ctx.lookupEnv = func(k string) (string, bool) {
+			v, ok := env[k]
+			return v, ok
+		}
+	}
+}
+
+func withWorkingDir(wd string) Option {
+	return func(ctx *Context) {
+		ctx.wd = wd
+	}
+}
+
+func withSystemPath(p string) Option {
+	return func(ctx *Context) {
+		ctx.systemPath = p
+	}
+}
+
+func withSecretStores(fn config.SecretStoreFn) Option {
+	return func(ctx *Context) {
+		ctx.secretStores = fn
+	}
+}
+
 func withURL(uri string) *url.URL {
 	u, _ := url.Parse(uri)
 	return u
@@ -273,6 +325,34 @@ func TestNewFromFlags(t *testing.T) {
 				},
 			},
 		},
+		"LegacyBaseConfigMigrated": {
+			reason: "We should return a Context that includes a base config migrated from the unversioned format.",
+			args: args{
+				flags: []string{},
+				opts: []Option{
+					withConfig(legacyConfigJSON),
+					withPath("/.up/config.json"),
+				},
+			},
+			want: want{
+				c: &Context{
+					ProfileName:      "default",
+					Account:          "my-org",
+					APIEndpoint:      withURL("https://api.upbound.io"),
+					Domain:           withURL("https://upbound.io"),
+					ProxyEndpoint:    withURL("https://proxy.upbound.io/v1/controlPlanes"),
+					RegistryEndpoint: withURL("https://xpkg.upbound.io"),
+					Profile: config.Profile{
+						ID:      "someone@upbound.io",
+						Type:    config.UserProfileType,
+						Session: "a token",
+						BaseConfig: map[string]string{
+							"UP_ACCOUNT": "my-org",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -295,9 +375,307 @@ func TestNewFromFlags(t *testing.T) {
 				// NOTE(tnthornton) we're not concerned about the Cfg's
 				// internal components.
 				cmpopts.IgnoreFields(Context{}, "Cfg"),
+				// Settings are covered by TestNewFromFlagsLayers.
+				cmpopts.IgnoreFields(Context{}, "Settings"),
 			); diff != "" {
 				t.Errorf("\n%s\nNewFromFlags(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
 		})
 	}
 }
+
+func TestNewFromFlagsLayers(t *testing.T) {
+	system := `{"UP_DOMAIN": "https://system.upbound.io", "account": "system-org"}`
+	project := `{"profile": "cool-profile", "account": "project-org", "insecure_skip_tls_verify": true}`
+	redirect := `{"profile": "cool-profile", "OVERRIDE_API_ENDPOINT": "https://evil.example.com", "UP_DOMAIN": "https://evil.example.com", "UP_INSECURE_SKIP_TLS_VERIFY": true}`
+
+	type args struct {
+		flags []string
+		env   map[string]string
+		opts  []Option
+	}
+	type want struct {
+		err         error
+		profile     string
+		apiEndpoint string
+		settings    []Setting
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SystemOnly": {
+			reason: "Settings that are only set in the system settings file should be resolved from it.",
+			args: args{
+				opts: []Option{
+					withFS(afero.NewMemMapFs()),
+					withFile("/etc/up/settings.json", system),
+				},
+			},
+			want: want{
+				apiEndpoint: "https://api.system.upbound.io",
+				settings: []Setting{
+					{Name: "domain", Env: "UP_DOMAIN", Value: "https://system.upbound.io", Layer: config.SystemLayer, Source: "/etc/up/settings.json"},
+					{Name: "profile", Env: "UP_PROFILE", Layer: config.DefaultLayer},
+					{Name: "account", Env: "UP_ACCOUNT", Value: "system-org", Layer: config.SystemLayer, Source: "/etc/up/settings.json"},
+					{Name: "insecure-skip-tls-verify", Env: "UP_INSECURE_SKIP_TLS_VERIFY", Layer: config.DefaultLayer},
+					{Name: "override-api-endpoint", Env: "OVERRIDE_API_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-proxy-endpoint", Env: "OVERRIDE_PROXY_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-registry-endpoint", Env: "OVERRIDE_REGISTRY_ENDPOINT", Layer: config.DefaultLayer},
+				},
+			},
+		},
+		"ProjectSetsAccount": {
+			reason: "A project settings file discovered from the working directory should set the account and take precedence over the base config of the profile.",
+			args: args{
+				opts: []Option{
+					withFS(afero.NewMemMapFs()),
+					withPath("/home/.up/config.json"),
+					withFile("/home/.up/config.json", baseConfigJSON),
+					withFile("/etc/up/settings.json", system),
+					withFile("/src/repo/.up/settings.json", project),
+					withWorkingDir("/src/repo/apis/cluster"),
+				},
+			},
+			want: want{
+				profile:     "default",
+				apiEndpoint: "https://api.local.upbound.io",
+				settings: []Setting{
+					{Name: "domain", Env: "UP_DOMAIN", Value: "https://local.upbound.io", Layer: config.UserLayer, Source: "default"},
+					{Name: "profile", Env: "UP_PROFILE", Layer: config.DefaultLayer},
+					{Name: "account", Env: "UP_ACCOUNT", Value: "project-org", Layer: config.ProjectLayer, Source: "/src/repo/.up/settings.json"},
+					{Name: "insecure-skip-tls-verify", Env: "UP_INSECURE_SKIP_TLS_VERIFY", Value: "true", Layer: config.UserLayer, Source: "default"},
+					{Name: "override-api-endpoint", Env: "OVERRIDE_API_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-proxy-endpoint", Env: "OVERRIDE_PROXY_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-registry-endpoint", Env: "OVERRIDE_REGISTRY_ENDPOINT", Layer: config.DefaultLayer},
+				},
+			},
+		},
+		"EnvAndFlagsOverrideProject": {
+			reason: "The environment should take precedence over the project settings file, and flags over the environment.",
+			args: args{
+				flags: []string{
+					"--account=flag-org",
+				},
+				opts: []Option{
+					withConfig(baseConfigJSON),
+					withPath("/.up/config.json"),
+					withFile("/src/repo/.up/settings.json", project),
+					withWorkingDir("/src/repo"),
+				},
+				env: map[string]string{
+					"UP_PROFILE": "default",
+					"UP_ACCOUNT": "env-org",
+				},
+			},
+			want: want{
+				profile:     "default",
+				apiEndpoint: "https://api.local.upbound.io",
+				settings: []Setting{
+					{Name: "domain", Env: "UP_DOMAIN", Value: "https://local.upbound.io", Layer: config.UserLayer, Source: "default"},
+					{Name: "profile", Env: "UP_PROFILE", Value: "default", Layer: config.EnvLayer},
+					{Name: "account", Env: "UP_ACCOUNT", Value: "flag-org", Layer: config.FlagLayer},
+					{Name: "insecure-skip-tls-verify", Env: "UP_INSECURE_SKIP_TLS_VERIFY", Value: "true", Layer: config.UserLayer, Source: "default"},
+					{Name: "override-api-endpoint", Env: "OVERRIDE_API_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-proxy-endpoint", Env: "OVERRIDE_PROXY_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-registry-endpoint", Env: "OVERRIDE_REGISTRY_ENDPOINT", Layer: config.DefaultLayer},
+				},
+			},
+		},
+		"ProjectCannotRedirect": {
+			reason: "A project settings file should not be able to select a profile, redirect its session to another endpoint, or skip verifying TLS certificates.",
+			args: args{
+				opts: []Option{
+					withConfig(defaultConfigJSON),
+					withPath("/.up/config.json"),
+					withFile("/src/repo/.up/settings.json", redirect),
+					withWorkingDir("/src/repo"),
+				},
+			},
+			want: want{
+				profile:     "default",
+				apiEndpoint: "https://api.upbound.io",
+				settings: []Setting{
+					{Name: "domain", Env: "UP_DOMAIN", Value: "https://upbound.io", Layer: config.DefaultLayer},
+					{Name: "profile", Env: "UP_PROFILE", Layer: config.DefaultLayer},
+					{Name: "account", Env: "UP_ACCOUNT", Layer: config.DefaultLayer},
+					{Name: "insecure-skip-tls-verify", Env: "UP_INSECURE_SKIP_TLS_VERIFY", Layer: config.DefaultLayer},
+					{Name: "override-api-endpoint", Env: "OVERRIDE_API_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-proxy-endpoint", Env: "OVERRIDE_PROXY_ENDPOINT", Layer: config.DefaultLayer},
+					{Name: "override-registry-endpoint", Env: "OVERRIDE_REGISTRY_ENDPOINT", Layer: config.DefaultLayer},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Parse flags with the environment of the case applied, as kong
+			// would with the environment of the process.
+			flags := Flags{}
+			parser, _ := kong.New(&flags, kong.Resolvers(kong.ResolverFunc(func(_ *kong.Context, _ *kong.Path, flag *kong.Flag) (interface{}, error) {
+				if v, ok := tc.args.env[flag.Env]; ok {
+					return v, nil
+				}
+				return nil, nil
+			})))
+			if _, err := parser.Parse(tc.args.flags); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := append([]Option{
+				withSystemPath("/etc/up/settings.json"),
+				withEnv(tc.args.env),
+			}, tc.args.opts...)
+			c, err := NewFromFlags(flags, opts...)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNewFromFlags(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.profile, c.ProfileName); diff != "" {
+				t.Errorf("\n%s\nNewFromFlags(...): -want profile, +got profile:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.apiEndpoint, c.APIEndpoint.String()); diff != "" {
+				t.Errorf("\n%s\nNewFromFlags(...): -want API endpoint, +got API endpoint:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.settings, c.Settings); diff != "" {
+				t.Errorf("\n%s\nNewFromFlags(...): -want settings, +got settings:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSessionResolvedLazily(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := config.NewFileSecretStore(fs, "/.up/secrets", config.WithPassphrase(func() (string, error) {
+		return "passphrase", nil
+	})).Store("default", "a token"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `{"upbound": {"default": "default", "profiles": {"default": {"id": "someone@upbound.io", "type": "user", "sessionRef": {"store": "file", "key": "default"}}}}}`
+
+	prompted := 0
+	stores := func(config.SecretRef) (config.SecretStore, error) {
+		return config.NewFileSecretStore(fs, "/.up/secrets", config.WithPassphrase(func() (string, error) {
+			prompted++
+			return "passphrase", nil
+		})), nil
+	}
+
+	flags := Flags{}
+	parser, _ := kong.New(&flags)
+	parser.Parse([]string{})
+
+	c, err := NewFromFlags(flags, withFS(fs), withPath("/.up/config.json"), withFile("/.up/config.json", cfg), withSecretStores(stores))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(0, prompted); diff != "" {
+		t.Errorf("\nNewFromFlags(...): -want passphrase prompts, +got passphrase prompts:\n%s", diff)
+	}
+
+	session, err := c.Session()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("a token", session); diff != "" {
+		t.Errorf("\nSession(): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(1, prompted); diff != "" {
+		t.Errorf("\nSession(): -want passphrase prompts, +got passphrase prompts:\n%s", diff)
+	}
+}
+
+func TestNewFromFlagsReadOnly(t *testing.T) {
+	// The passphrase of the file secret store must not be set in the
+	// environment of the test.
+	t.Setenv(config.PassphraseEnv, "")
+	os.Unsetenv(config.PassphraseEnv) //nolint:errcheck
+
+	stored := `{"upbound": {"default": "default", "profiles": {"default": {"id": "someone@upbound.io", "type": "user", "sessionRef": {"store": "file", "key": "default"}}}}}`
+
+	type args struct {
+		config string
+		setup  func(fs afero.Fs) error
+	}
+	type want struct {
+		config     string
+		sessionErr bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoConfig": {
+			reason: "A missing config file should not be created.",
+			args: args{
+				setup: func(afero.Fs) error { return nil },
+			},
+		},
+		"LegacyConfig": {
+			reason: "A config file written by an earlier version of up should not be migrated on disk.",
+			args: args{
+				config: legacyConfigJSON,
+				setup:  func(afero.Fs) error { return nil },
+			},
+			want: want{
+				config: legacyConfigJSON,
+			},
+		},
+		"FileStoreWithoutPassphrase": {
+			reason: "A session held in the file secret store should not be resolved by prompting for its passphrase.",
+			args: args{
+				config: stored,
+				setup: func(fs afero.Fs) error {
+					return config.NewFileSecretStore(fs, "/.up/secrets", config.WithPassphrase(func() (string, error) {
+						return "passphrase", nil
+					})).Store("default", "a token")
+				},
+			},
+			want: want{
+				config:     stored,
+				sessionErr: true,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tc.args.config != "" {
+				if err := afero.WriteFile(fs, "/.up/config.json", []byte(tc.args.config), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tc.args.setup(fs); err != nil {
+				t.Fatal(err)
+			}
+
+			flags := Flags{}
+			parser, _ := kong.New(&flags)
+			parser.Parse([]string{})
+
+			c, err := NewFromFlags(flags, withFS(fs), withPath("/.up/config.json"), ReadOnly())
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = c.Session()
+			if diff := cmp.Diff(tc.want.sessionErr, err != nil); diff != "" {
+				t.Errorf("\n%s\nSession(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			b, err := afero.ReadFile(fs, "/.up/config.json")
+			if tc.want.config == "" && !errors.Is(err, iofs.ErrNotExist) {
+				t.Errorf("\n%s\nNewFromFlags(...): -want no config file, +got: %q", tc.reason, b)
+			}
+			if diff := cmp.Diff(tc.want.config, string(b)); diff != "" {
+				t.Errorf("\n%s\nNewFromFlags(...): -want config file, +got config file:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
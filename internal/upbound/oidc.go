@@ -0,0 +1,214 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+)
+
+const (
+	// oidcDiscoveryPath is appended to an issuer to obtain its metadata, as
+	// defined by section 4 of OpenID Connect Discovery 1.0.
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+	errOIDCDiscover       = "unable to discover OpenID provider metadata"
+	errOIDCIssuerFmt      = "issuer %q of OpenID provider metadata does not match %q"
+	errOIDCFetchKeys      = "unable to fetch OpenID provider signing keys"
+	errOIDCStatusFmt      = "unexpected status from %s: %s"
+	errOIDCNoKeyFmt       = "no signing key found for ID token key ID %q"
+	errOIDCKeyTypeFmt     = "unsupported signing key type %q"
+	errOIDCInvalidKey     = "invalid signing key"
+	errOIDCVerifyIDToken  = "unable to verify ID token"
+	errOIDCIDTokenIssuer  = "ID token was not issued by the OpenID provider"
+	errOIDCIDTokenAud     = "ID token was not issued to this client"
+	errOIDCIDTokenNonce   = "ID token nonce does not match the nonce of the request"
+	errOIDCIDTokenExpired = "ID token has expired or is not yet valid"
+)
+
+// idTokenMethods are the algorithms that ID tokens may be signed with. Tokens
+// must be signed with an asymmetric key published by the OpenID provider.
+var idTokenMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodRS384.Alg(),
+	jwt.SigningMethodRS512.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodES384.Alg(),
+	jwt.SigningMethodES512.Alg(),
+}
+
+// oidcProvider is the subset of the OpenID provider metadata that single
+// sign-on uses, as defined by section 3 of OpenID Connect Discovery 1.0.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// jsonWebKeySet is a JSON Web Key Set, as defined by section 5 of RFC 7517.
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey is a public RSA or EC JSON Web Key, as defined by section 4 of
+// RFC 7517 and section 6 of RFC 7518.
+type jsonWebKey struct {
+	KeyID   string `json:"kid"`
+	KeyType string `json:"kty"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// discoverProvider fetches the metadata of the OpenID provider with the
+// supplied issuer. The issuer of the metadata must be identical to the
+// supplied issuer, as required by section 4.3 of OpenID Connect Discovery 1.0.
+func discoverProvider(ctx context.Context, client *http.Client, issuer string) (*oidcProvider, error) {
+	p := &oidcProvider{}
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+oidcDiscoveryPath, p); err != nil {
+		return nil, errors.Wrap(err, errOIDCDiscover)
+	}
+	if p.Issuer != issuer {
+		return nil, errors.Errorf(errOIDCIssuerFmt, p.Issuer, issuer)
+	}
+	return p, nil
+}
+
+// verifyIDToken verifies the supplied ID token and returns its claims. The
+// token is validated as required by section 3.1.3.7 of OpenID Connect Core
+// 1.0: it must be signed by a key of the provider, issued by the provider, to
+// the supplied client, with the nonce of the authentication request, and must
+// not have expired.
+func verifyIDToken(ctx context.Context, client *http.Client, p *oidcProvider, raw, clientID, nonce string) (jwt.MapClaims, error) {
+	keys := &jsonWebKeySet{}
+	if err := getJSON(ctx, client, p.JWKSURI, keys); err != nil {
+		return nil, errors.Wrap(err, errOIDCFetchKeys)
+	}
+	claims := jwt.MapClaims{}
+	parser := &jwt.Parser{ValidMethods: idTokenMethods}
+	if _, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys.key(kid)
+	}); err != nil {
+		var verr *jwt.ValidationError
+		if errors.As(err, &verr) && verr.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet|jwt.ValidationErrorIssuedAt) != 0 {
+			return nil, errors.New(errOIDCIDTokenExpired)
+		}
+		return nil, errors.Wrap(err, errOIDCVerifyIDToken)
+	}
+	if !claims.VerifyIssuer(p.Issuer, true) {
+		return nil, errors.New(errOIDCIDTokenIssuer)
+	}
+	if !claims.VerifyAudience(clientID, true) {
+		return nil, errors.New(errOIDCIDTokenAud)
+	}
+	// A token with several audiences must have been authorized for this
+	// client.
+	if aud, ok := claims["aud"].([]interface{}); ok && len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != clientID {
+			return nil, errors.New(errOIDCIDTokenAud)
+		}
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New(errOIDCIDTokenNonce)
+	}
+	return claims, nil
+}
+
+// key returns the public signing key with the supplied key ID. A key ID may be
+// omitted if the set holds a single signing key.
+func (s *jsonWebKeySet) key(kid string) (interface{}, error) {
+	var signing []jsonWebKey
+	for _, k := range s.Keys {
+		if k.Use == "" || k.Use == "sig" {
+			signing = append(signing, k)
+		}
+	}
+	for _, k := range signing {
+		if k.KeyID == kid || (kid == "" && len(signing) == 1) {
+			return k.publicKey()
+		}
+	}
+	return nil, errors.Errorf(errOIDCNoKeyFmt, kid)
+}
+
+// publicKey returns the RSA or ECDSA public key of the JSON Web Key.
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, errOIDCInvalidKey)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, errOIDCInvalidKey)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New(errOIDCInvalidKey)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, errOIDCInvalidKey)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, errOIDCInvalidKey)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, errors.Errorf(errOIDCKeyTypeFmt, k.KeyType)
+}
+
+// getJSON decodes the JSON document at the supplied URL into obj.
+func getJSON(ctx context.Context, client *http.Client, u string, obj interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() // nolint:errcheck
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf(errOIDCStatusFmt, u, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(obj)
+}
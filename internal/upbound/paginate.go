@@ -0,0 +1,48 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"context"
+
+	"github.com/upbound/up-sdk-go/service/common"
+)
+
+// DefaultPageSize is the number of items requested in each page when listing
+// all items.
+const DefaultPageSize = 100
+
+// A ListPageFn lists a page of items with the supplied options. It returns the
+// items in the page, the number of the page, and the total number of items in
+// all pages.
+type ListPageFn[T any] func(ctx context.Context, opts ...common.ListOption) (items []T, page, count int, err error)
+
+// ListAll lists all items by requesting pages of the supplied size in turn,
+// until all items have been listed or a page is empty.
+func ListAll[T any](ctx context.Context, size int, fn ListPageFn[T]) ([]T, error) {
+	all := []T{}
+	opts := []common.ListOption{common.WithSize(size)}
+	for {
+		items, page, count, err := fn(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) == 0 || len(all) >= count {
+			return all, nil
+		}
+		opts = []common.ListOption{common.WithSize(size), common.WithPage(page + 1)}
+	}
+}
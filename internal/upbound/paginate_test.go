@@ -0,0 +1,101 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/upbound/up-sdk-go/service/common"
+)
+
+// pages returns a ListPageFn that serves the supplied items in pages of the
+// requested size, numbered from zero.
+func pages(items []int) ListPageFn[int] {
+	return func(_ context.Context, opts ...common.ListOption) ([]int, int, int, error) {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.upbound.io", nil)
+		for _, o := range opts {
+			o(req)
+		}
+		size, _ := strconv.Atoi(req.URL.Query().Get(common.SizeParam))
+		page, _ := strconv.Atoi(req.URL.Query().Get(common.PageParam))
+		start, end := page*size, (page+1)*size
+		if start > len(items) {
+			start = len(items)
+		}
+		if end > len(items) {
+			end = len(items)
+		}
+		return items[start:end], page, len(items), nil
+	}
+}
+
+func TestListAll(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		size int
+		fn   ListPageFn[int]
+	}
+	type want struct {
+		items []int
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SinglePage": {
+			reason: "Items that fit in a single page should be listed with a single request.",
+			args:   args{size: 5, fn: pages([]int{1, 2, 3})},
+			want:   want{items: []int{1, 2, 3}},
+		},
+		"MultiplePages": {
+			reason: "Items should be listed from every page.",
+			args:   args{size: 2, fn: pages([]int{1, 2, 3, 4, 5})},
+			want:   want{items: []int{1, 2, 3, 4, 5}},
+		},
+		"Empty": {
+			reason: "No items should be listed if there are none.",
+			args:   args{size: 2, fn: pages(nil)},
+			want:   want{items: []int{}},
+		},
+		"Error": {
+			reason: "Errors listing a page should be returned.",
+			args: args{size: 2, fn: func(context.Context, ...common.ListOption) ([]int, int, int, error) {
+				return nil, 0, 0, errBoom
+			}},
+			want: want{err: errBoom},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			items, err := ListAll(context.Background(), tc.args.size, tc.args.fn)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nListAll(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.items, items); diff != "" {
+				t.Errorf("\n%s\nListAll(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
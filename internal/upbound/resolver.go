@@ -16,60 +16,119 @@ package upbound
 
 import (
 	"encoding/json"
-	"io"
+	"fmt"
 	"strings"
 
 	"github.com/alecthomas/kong"
+
+	"github.com/upbound/up/internal/config"
 )
 
-// JSON returns a Resolver that retrieves values from a JSON source.
-// Based slightly off of https://github.com/alecthomas/kong/blob/f48da244f54370c0cb63e22b0e500e5459a491bf/resolver.go#L33-L60
-// Hyphens in flag names are replaced with underscores.
-func JSON(base, overlay io.Reader) (kong.Resolver, error) {
-	baseValues := map[string]interface{}{}
-	overlayValues := map[string]interface{}{}
-	err := json.NewDecoder(base).Decode(&baseValues)
-	if err != nil {
-		return nil, err
-	}
-	err = json.NewDecoder(overlay).Decode(&overlayValues)
-	if err != nil {
-		return nil, err
-	}
+// A Setting is the effective value of a flag and the layer of configuration it
+// was resolved from.
+type Setting struct {
+	// Name is the name of the flag.
+	Name string
 
-	var f kong.ResolverFunc = func(context *kong.Context, parent *kong.Path, flag *kong.Flag) (interface{}, error) {
-		name := strings.ReplaceAll(flag.Name, "-", "_")
-		bRaw, bOk := resolveValue(name, flag.Env, baseValues)
-		oRaw, oOk := resolveValue(name, flag.Env, overlayValues)
+	// Env is the environment variable the flag may be set through.
+	Env string
 
-		// if found in base and in overlay AND is not the defaultValue for overlay
-		if bOk && oOk && flag.Default != oRaw {
-			return oRaw, nil
-		}
+	// Value is the effective value of the flag.
+	Value string
 
-		if bOk {
-			return bRaw, nil
-		}
+	// Layer is the layer of configuration the value was resolved from.
+	Layer config.SettingsLayer
 
-		if oOk {
-			return oRaw, nil
-		}
+	// Source is the settings file or profile the value was read from, if any.
+	Source string
+}
+
+// layer is a layer of configuration that flags may be resolved from.
+type layer struct {
+	name   config.SettingsLayer
+	source string
+	values config.Settings
+}
 
-		return nil, nil
+// layered returns a Resolver that resolves each flag from the first of the
+// supplied layers that sets it, so layers must be ordered from highest to
+// lowest precedence. The setting each flag resolves to is recorded in the
+// supplied map.
+func layered(layers []layer, settings map[string]Setting) kong.Resolver {
+	var f kong.ResolverFunc = func(context *kong.Context, parent *kong.Path, flag *kong.Flag) (interface{}, error) {
+		// Only flags that may be set through the environment are settings.
+		if flag.Env == "" {
+			return nil, nil
+		}
+		s := Setting{
+			Name:  flag.Name,
+			Env:   flag.Env,
+			Value: flag.Default,
+			Layer: config.DefaultLayer,
+		}
+		for _, l := range layers {
+			if v, ok := lookup(l.values, flag); ok {
+				s.Value, s.Layer, s.Source = v, l.name, l.source
+				break
+			}
+		}
+		settings[flag.Name] = s
+		if s.Layer == config.DefaultLayer {
+			return nil, nil
+		}
+		return s.Value, nil
 	}
 
-	return f, nil
+	return f
 }
 
-func resolveValue(fieldName, envVarName string, vals map[string]interface{}) (interface{}, bool) {
-	// attempt to lookup by field name first
-	raw, ok := vals[fieldName]
-	if !ok {
-		// fall back to env var name
-		raw, ok = vals[envVarName]
+// lookup looks up the value of the supplied flag by its name, its name with
+// hyphens replaced by underscores, or its environment variable name.
+func lookup(vals config.Settings, flag *kong.Flag) (string, bool) {
+	for _, k := range []string{flag.Name, strings.ReplaceAll(flag.Name, "-", "_"), flag.Env} {
+		if v, ok := vals[k]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// envAndFlagSettings splits the supplied parsed Flags into the settings that
+// were set through the environment and those that were set on the command
+// line. Flags are parsed with their environment variables applied, so a value
+// matching its environment variable is attributed to the environment, while a
+// value matching its default was not set at all.
+func envAndFlagSettings(f Flags, lookupEnv func(string) (string, bool)) (config.Settings, config.Settings, error) {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	parsed := map[string]interface{}{}
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		return nil, nil, err
+	}
+	parser, err := kong.New(&Flags{})
+	if err != nil {
+		return nil, nil, err
+	}
+	env, flags := config.Settings{}, config.Settings{}
+	for _, flag := range parser.Model.Flags {
+		if flag.Env == "" {
+			continue
+		}
+		ev, eok := lookupEnv(flag.Env)
+		if eok && ev != "" {
+			env[flag.Env] = ev
+		}
+		raw, ok := parsed[strings.ReplaceAll(flag.Name, "-", "_")]
 		if !ok {
-			return nil, false
+			continue
+		}
+		v := fmt.Sprint(raw)
+		if v == flag.Default || (eok && v == ev) {
+			continue
 		}
+		flags[flag.Name] = v
 	}
-	return raw, true
+	return env, flags, nil
 }
@@ -0,0 +1,209 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/upbound/up/internal/config"
+	uphttp "github.com/upbound/up/internal/http"
+)
+
+const (
+	// DefaultSessionLifetime is the lifetime of a session when Upbound does
+	// not communicate when it expires.
+	DefaultSessionLifetime = 30 * 24 * time.Hour
+
+	loginPath = "/v1/login"
+
+	errReadBody       = "unable to read response body"
+	errParseCookieFmt = "unable to parse session cookie: %s"
+	errNoTokenSource  = "profile does not have a token source to re-authenticate with"
+	errReauthenticate = "unable to re-authenticate profile"
+
+	warnSessionExpiringFmt = "Session for profile %q expires in %s. Run up login to renew it."
+	warnSessionExpiredFmt  = "Session for profile %q expired %s ago. Run up login to renew it."
+)
+
+// login is the request body sent to authenticate a user or token.
+type login struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	Remember bool   `json:"remember"`
+}
+
+// Login authenticates to the Upbound API at the supplied endpoint with an ID
+// and password, which is the token itself for token profiles. It returns the
+// cookie holding the resulting session.
+func Login(ctx context.Context, client uphttp.Client, apiEndpoint *url.URL, id, password string) (*http.Cookie, error) {
+	body, err := json.Marshal(&login{
+		ID:       id,
+		Password: password,
+		Remember: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	u := *apiEndpoint
+	u.Path = loginPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close() // nolint:errcheck
+	return extractSession(res, CookieName)
+}
+
+// extractSession extracts the specified cookie from an HTTP response. The
+// caller is responsible for closing the response body.
+func extractSession(res *http.Response, cookieName string) (*http.Cookie, error) {
+	for _, cook := range res.Cookies() {
+		if cook.Name == cookieName {
+			return cook, nil
+		}
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadBody)
+	}
+	return nil, errors.Errorf(errParseCookieFmt, string(b))
+}
+
+// SetSession sets the session of the supplied profile from a session cookie,
+// recording when it was issued and when it expires. The expiry of the cookie
+// is used if it has one, otherwise the default session lifetime is assumed.
+func SetSession(p *config.Profile, cookie *http.Cookie, now time.Time) {
+	expires := now.Add(DefaultSessionLifetime)
+	switch {
+	case !cookie.Expires.IsZero():
+		expires = cookie.Expires
+	case cookie.MaxAge > 0:
+		expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+	}
+	issued := now.UTC()
+	expires = expires.UTC()
+	p.Session = cookie.Value
+	p.SessionIssuedAt = &issued
+	p.SessionExpiresAt = &expires
+}
+
+// reauthenticate obtains a new session for the profile of the Context from
+// its token source, and persists it to the config unless the Context is read
+// only.
+func (c *Context) reauthenticate(ctx context.Context, client uphttp.Client) (*http.Cookie, error) {
+	if c.Profile.Type != config.TokenProfileType || c.Profile.TokenSource == nil || c.Profile.TokenSource.File == "" {
+		return nil, errors.New(errNoTokenSource)
+	}
+	tf, err := TokenFromPath(c.Profile.TokenSource.File, func(conf *tokenConf) { conf.fs = c.fs })
+	if err != nil {
+		return nil, errors.Wrap(err, errReauthenticate)
+	}
+	cookie, err := Login(ctx, client, c.APIEndpoint, c.Profile.ID, tf.Token)
+	if err != nil {
+		return nil, errors.Wrap(err, errReauthenticate)
+	}
+	SetSession(&c.Profile, cookie, time.Now())
+	if err := c.Cfg.AddOrUpdateUpboundProfile(c.ProfileName, c.Profile); err != nil {
+		return nil, errors.Wrap(err, errReauthenticate)
+	}
+	if c.readOnly {
+		return cookie, nil
+	}
+	if err := c.CfgSrc.UpdateConfig(c.Cfg); err != nil {
+		return nil, errors.Wrap(err, errReauthenticate)
+	}
+	return cookie, nil
+}
+
+// reauthTransport re-authenticates token profiles from their token source when
+// a request is rejected as unauthorized, then retries the request once with
+// the new session.
+type reauthTransport struct {
+	base  http.RoundTripper
+	jar   *cookiejar.Jar
+	upCtx *Context
+}
+
+// RoundTrip executes a single HTTP transaction.
+func (t *reauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	// A request with a body can only be retried if the body can be read
+	// again.
+	if req.Body != nil && req.GetBody == nil {
+		return res, nil
+	}
+	cookie, rerr := t.upCtx.reauthenticate(req.Context(), &http.Client{Transport: t.base})
+	if rerr != nil {
+		return res, nil // nolint:nilerr
+	}
+	_ = res.Body.Close()
+	t.jar.SetCookies(t.upCtx.APIEndpoint, []*http.Cookie{{Name: CookieName, Value: cookie.Value}})
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Del("Cookie")
+	retry.AddCookie(&http.Cookie{Name: CookieName, Value: cookie.Value})
+	return t.base.RoundTrip(retry)
+}
+
+// SessionWarning returns a warning about the session of the profile of the
+// Context if it has expired or expires soon and cannot be renewed from a token
+// source. An empty string is returned if no warning is necessary.
+func (c *Context) SessionWarning(now time.Time) string {
+	if c.Profile.TokenSource != nil && c.Profile.Type == config.TokenProfileType {
+		return ""
+	}
+	status, remaining := c.Profile.SessionStatus(now)
+	switch status { // nolint:exhaustive
+	case config.SessionExpiring:
+		return fmt.Sprintf(warnSessionExpiringFmt, c.ProfileName, HumanDuration(remaining))
+	case config.SessionExpired:
+		return fmt.Sprintf(warnSessionExpiredFmt, c.ProfileName, HumanDuration(-remaining))
+	}
+	return ""
+}
+
+// HumanDuration formats the supplied duration in whole days, hours or minutes.
+func HumanDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dm", d/time.Minute)
+}
@@ -0,0 +1,225 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/upbound/up/internal/config"
+)
+
+func TestExtractSession(t *testing.T) {
+	errBoom := errors.New("boom")
+	cook := http.Cookie{
+		Name:  "SID",
+		Value: "cool-session",
+	}
+	type args struct {
+		res  *http.Response
+		name string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+		err    error
+	}{
+		"ErrorNoCookieFailReadBody": {
+			reason: "Should return an error if cookie does not exist and we fail to read body.",
+			args: args{
+				res: &http.Response{
+					Body: io.NopCloser(iotest.ErrReader(errBoom)),
+				},
+			},
+			err: errors.Wrap(errBoom, errReadBody),
+		},
+		"ErrorNoCookie": {
+			reason: "Should return an error if cookie does not exist.",
+			args: args{
+				res: &http.Response{
+					Body: io.NopCloser(bytes.NewBuffer([]byte("unauthorized"))),
+				},
+			},
+			err: errors.Errorf(errParseCookieFmt, "unauthorized"),
+		},
+		"ErrorOtherCookie": {
+			reason: "Should return an error if only cookies with other names exist.",
+			args: args{
+				res: &http.Response{
+					Header: http.Header{"Set-Cookie": []string{(&http.Cookie{Name: "other", Value: "value"}).String()}},
+					Body:   io.NopCloser(bytes.NewBuffer([]byte("unauthorized"))),
+				},
+				name: cook.Name,
+			},
+			err: errors.Errorf(errParseCookieFmt, "unauthorized"),
+		},
+		"Successful": {
+			reason: "Should return cookie if it exists.",
+			args: args{
+				res: &http.Response{
+					Header: http.Header{"Set-Cookie": []string{cook.String()}},
+				},
+				name: cook.Name,
+			},
+			want: cook.Value,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cookie, err := extractSession(tc.args.res, tc.args.name)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nextractSession(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			session := ""
+			if cookie != nil {
+				session = cookie.Value
+			}
+			if diff := cmp.Diff(tc.want, session); diff != "" {
+				t.Errorf("\n%s\nextractSession(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetSession(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		reason string
+		cookie *http.Cookie
+		want   time.Time
+	}{
+		"Expires": {
+			reason: "The expiry of the cookie should be recorded if it has one.",
+			cookie: &http.Cookie{Name: CookieName, Value: "token", Expires: now.Add(time.Hour)},
+			want:   now.Add(time.Hour),
+		},
+		"MaxAge": {
+			reason: "The max age of the cookie should be used if it does not have an expiry.",
+			cookie: &http.Cookie{Name: CookieName, Value: "token", MaxAge: 60},
+			want:   now.Add(time.Minute),
+		},
+		"Default": {
+			reason: "The default session lifetime should be assumed if the cookie does not communicate its expiry.",
+			cookie: &http.Cookie{Name: CookieName, Value: "token"},
+			want:   now.Add(DefaultSessionLifetime),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := config.Profile{}
+			SetSession(&p, tc.cookie, now)
+			want := config.Profile{
+				Session:          "token",
+				SessionIssuedAt:  &now,
+				SessionExpiresAt: &tc.want,
+			}
+			if diff := cmp.Diff(want, p); diff != "" {
+				t.Errorf("\n%s\nSetSession(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReauthTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == loginPath {
+			http.SetCookie(w, &http.Cookie{Name: CookieName, Value: "renewed"})
+			return
+		}
+		if c, err := r.Cookie(CookieName); err != nil || c.Value != "renewed" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+	endpoint, _ := url.Parse(srv.URL)
+
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/token.json", []byte(`{"accessId":"cool-id","token":"cool-token"}`), os.ModePerm)
+
+	cases := map[string]struct {
+		reason  string
+		profile config.Profile
+		want    int
+	}{
+		"TokenSource": {
+			reason:  "A token profile with a token source should be re-authenticated and the request retried.",
+			profile: config.Profile{ID: "cool-id", Type: config.TokenProfileType, Session: "expired", TokenSource: &config.TokenSource{File: "/token.json"}},
+			want:    http.StatusOK,
+		},
+		"NoTokenSource": {
+			reason:  "A profile without a token source should not be re-authenticated.",
+			profile: config.Profile{ID: "cool-user", Type: config.UserProfileType, Session: "expired"},
+			want:    http.StatusUnauthorized,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated *config.Config
+			upCtx := &Context{
+				ProfileName: "default",
+				Profile:     tc.profile,
+				APIEndpoint: endpoint,
+				Cfg:         &config.Config{},
+				CfgSrc: &config.MockSource{
+					UpdateConfigFn: func(c *config.Config) error {
+						updated = c
+						return nil
+					},
+				},
+				fs: fs,
+			}
+			jar, _ := cookiejar.New(nil)
+			jar.SetCookies(endpoint, []*http.Cookie{{Name: CookieName, Value: tc.profile.Session}})
+			client := &http.Client{
+				Jar:       jar,
+				Transport: &reauthTransport{base: http.DefaultTransport, jar: jar, upCtx: upCtx},
+			}
+			res, err := client.Post(srv.URL+"/v1/controlPlanes", "application/json", bytes.NewReader([]byte("{}")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close() // nolint:errcheck
+			if diff := cmp.Diff(tc.want, res.StatusCode); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if tc.want != http.StatusOK {
+				return
+			}
+			b, _ := io.ReadAll(res.Body)
+			if diff := cmp.Diff("{}", string(b)); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want body, +got body:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff("renewed", updated.Upbound.Profiles["default"].Session); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want session, +got session:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/pkg/errors"
+
+	"github.com/upbound/up/internal/config"
+)
+
+const (
+	errUnknownSettingFmt = "unknown setting %q: must be one of %s"
+	errInvalidSettingFmt = "invalid value for setting %s"
+)
+
+// Types of setting values.
+const (
+	StringSettingType = "string"
+	BoolSettingType   = "bool"
+	URLSettingType    = "url"
+)
+
+// A SettingKey is a setting that may be set in the base config of a profile.
+type SettingKey struct {
+	// Name is the name of the flag that the setting configures.
+	Name string
+
+	// Key is the key the setting is stored under in the base config, which is
+	// the environment variable of the flag.
+	Key string
+
+	// Type is the type of the value of the setting.
+	Type string
+
+	// Help describes the setting.
+	Help string
+}
+
+// SettingKeys returns the settings that may be set in the base config of a
+// profile, in the order their flags are declared. They are the Flags that may
+// be set through the environment, other than the profile itself.
+func SettingKeys() ([]SettingKey, error) {
+	parser, err := kong.New(&Flags{})
+	if err != nil {
+		return nil, err
+	}
+	keys := []SettingKey{}
+	for _, flag := range parser.Model.Flags {
+		if flag.Env == "" || flag.Name == "profile" {
+			continue
+		}
+		keys = append(keys, SettingKey{
+			Name: flag.Name,
+			Key:  flag.Env,
+			Type: settingType(flag),
+			Help: flag.Help,
+		})
+	}
+	return keys, nil
+}
+
+// ResolveSettingKey returns the setting for the supplied key, which may be the
+// name of its flag, with or without hyphens replaced by underscores, or its
+// environment variable, in any case.
+func ResolveSettingKey(key string) (SettingKey, error) {
+	keys, err := SettingKeys()
+	if err != nil {
+		return SettingKey{}, err
+	}
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		for _, alias := range []string{k.Name, strings.ReplaceAll(k.Name, "-", "_"), k.Key} {
+			if strings.EqualFold(key, alias) {
+				return k, nil
+			}
+		}
+		names[i] = k.Name
+	}
+	return SettingKey{}, errors.Errorf(errUnknownSettingFmt, key, strings.Join(names, ", "))
+}
+
+// Validate returns an error if the supplied value is not valid for the
+// setting, i.e. if it could not be parsed as its flag.
+func (k SettingKey) Validate(value string) error {
+	parser, err := kong.New(&Flags{})
+	if err != nil {
+		return err
+	}
+	_, err = parser.Parse([]string{fmt.Sprintf("--%s=%s", k.Name, value)})
+	return errors.Wrapf(err, errInvalidSettingFmt, k.Name)
+}
+
+// CanonicalSettings validates the supplied settings and returns them keyed by
+// their canonical key. An error is returned if any setting is unknown or its
+// value is invalid.
+func CanonicalSettings(settings map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(settings))
+	for k, v := range settings {
+		key, err := ResolveSettingKey(k)
+		if err != nil {
+			return nil, err
+		}
+		if err := key.Validate(v); err != nil {
+			return nil, err
+		}
+		out[key.Key] = v
+	}
+	return out, nil
+}
+
+// settingType returns the type of the value of the supplied flag.
+func settingType(flag *kong.Flag) string {
+	switch {
+	case flag.IsBool():
+		return BoolSettingType
+	case flag.Target.Type() == reflect.TypeOf(&url.URL{}):
+		return URLSettingType
+	}
+	return StringSettingType
+}
+
+// Migrations are the migrations of the config file format, in order.
+func Migrations() []config.Migration {
+	return []config.Migration{
+		{To: 1, Migrate: canonicalizeBaseConfigs},
+	}
+}
+
+// canonicalizeBaseConfigs stores the settings in the base config of each
+// profile under their canonical key, as earlier versions of up accepted any
+// key. Keys that are not settings are retained so that they may be inspected
+// and unset.
+func canonicalizeBaseConfigs(c *config.Config) error {
+	for name, p := range c.Upbound.Profiles {
+		if len(p.BaseConfig) == 0 {
+			continue
+		}
+		base := make(map[string]string, len(p.BaseConfig))
+		for k, v := range p.BaseConfig {
+			sk, err := ResolveSettingKey(k)
+			if err != nil || k == sk.Key {
+				base[k] = v
+				continue
+			}
+			// A setting that is also stored under its canonical key keeps
+			// that value.
+			if _, ok := p.BaseConfig[sk.Key]; !ok {
+				base[sk.Key] = v
+			}
+		}
+		p.BaseConfig = base
+		c.Upbound.Profiles[name] = p
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"testing"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+
+	"github.com/upbound/up/internal/config"
+)
+
+func TestResolveSettingKey(t *testing.T) {
+	account := SettingKey{Name: "account", Key: "UP_ACCOUNT", Type: StringSettingType, Help: "Account used to execute command."}
+	insecure := SettingKey{Name: "insecure-skip-tls-verify", Key: "UP_INSECURE_SKIP_TLS_VERIFY", Type: BoolSettingType, Help: "[INSECURE] Skip verifying TLS certificates."}
+
+	type want struct {
+		key SettingKey
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		key    string
+		want   want
+	}{
+		"FlagName": {
+			reason: "A setting should be resolved by the name of its flag.",
+			key:    "account",
+			want:   want{key: account},
+		},
+		"Underscores": {
+			reason: "A setting should be resolved by the name of its flag with hyphens replaced by underscores.",
+			key:    "insecure_skip_tls_verify",
+			want:   want{key: insecure},
+		},
+		"Env": {
+			reason: "A setting should be resolved by its environment variable in any case.",
+			key:    "up_account",
+			want:   want{key: account},
+		},
+		"Profile": {
+			reason: "A profile should not be able to select another profile.",
+			key:    "UP_PROFILE",
+			want: want{
+				err: errors.Errorf(errUnknownSettingFmt, "UP_PROFILE", "domain, account, insecure-skip-tls-verify, override-api-endpoint, override-proxy-endpoint, override-registry-endpoint"),
+			},
+		},
+		"Typo": {
+			reason: "A key that is not a setting should return an error.",
+			key:    "acount",
+			want: want{
+				err: errors.Errorf(errUnknownSettingFmt, "acount", "domain, account, insecure-skip-tls-verify, override-api-endpoint, override-proxy-endpoint, override-registry-endpoint"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			key, err := ResolveSettingKey(tc.key)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveSettingKey(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.key, key); diff != "" {
+				t.Errorf("\n%s\nResolveSettingKey(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSettingKeyValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		key    string
+		value  string
+		want   error
+	}{
+		"ValidBool": {
+			reason: "A bool setting should accept a bool value.",
+			key:    "insecure-skip-tls-verify",
+			value:  "true",
+		},
+		"InvalidBool": {
+			reason: "A bool setting should reject a value that is not a bool.",
+			key:    "insecure-skip-tls-verify",
+			value:  "maybe",
+			want:   cmpopts.AnyError,
+		},
+		"ValidURL": {
+			reason: "A URL setting should accept a URL.",
+			key:    "domain",
+			value:  "https://local.upbound.io",
+		},
+		"InvalidURL": {
+			reason: "A URL setting should reject a value that is not a URL.",
+			key:    "domain",
+			value:  "::local",
+			want:   cmpopts.AnyError,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			key, err := ResolveSettingKey(tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = key.Validate(tc.value)
+			if diff := cmp.Diff(tc.want, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeBaseConfigs(t *testing.T) {
+	c := &config.Config{
+		Upbound: config.Upbound{
+			Profiles: map[string]config.Profile{
+				"default": {
+					BaseConfig: map[string]string{
+						"account":                  "my-org",
+						"insecure_skip_tls_verify": "true",
+						"UP_DOMAIN":                "https://local.upbound.io",
+						"domain":                   "https://ignored.upbound.io",
+						"acount":                   "typo",
+					},
+				},
+			},
+		},
+	}
+	want := map[string]string{
+		"UP_ACCOUNT":                  "my-org",
+		"UP_INSECURE_SKIP_TLS_VERIFY": "true",
+		"UP_DOMAIN":                   "https://local.upbound.io",
+		"acount":                      "typo",
+	}
+	if err := canonicalizeBaseConfigs(c); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, c.Upbound.Profiles["default"].BaseConfig); diff != "" {
+		t.Errorf("\ncanonicalizeBaseConfigs(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultSSOClientID is the OAuth2 client ID of up.
+	DefaultSSOClientID = "up-cli"
+
+	ssoCallbackPath = "/callback"
+	ssoListenAddr   = "127.0.0.1:0"
+
+	ssoCallbackPage = "<html><body>Login complete. You may close this window and return to up.</body></html>"
+	ssoFailurePage  = "<html><body>Login failed: %s. You may close this window and return to up.</body></html>"
+
+	errSSOListen     = "unable to start local callback listener"
+	errSSOOpenURL    = "unable to open authorization URL"
+	errSSOStateFmt   = "callback state %q does not match the state of the request"
+	errSSOAuthFmt    = "authorization failed: %s"
+	errSSONoCode     = "callback did not include an authorization code"
+	errSSOExchange   = "unable to exchange authorization code"
+	errSSONoIDToken  = "token response did not include an ID token"
+	errSSONoIdentity = "ID token does not identify the user"
+)
+
+// SSOConfig configures a browser based single sign-on flow.
+type SSOConfig struct {
+	// ClientID is the OAuth2 client ID used to authenticate.
+	ClientID string
+
+	// Issuer is the OpenID provider that users sign on with. Its endpoints
+	// and signing keys are discovered from its metadata. Defaults to the API
+	// endpoint.
+	Issuer *url.URL
+
+	// AuthURL overrides the authorization endpoint of the OpenID provider.
+	AuthURL *url.URL
+
+	// TokenURL overrides the token endpoint of the OpenID provider.
+	TokenURL *url.URL
+
+	// OpenURL directs the user to the supplied authorization URL, i.e. by
+	// opening it in a browser.
+	OpenURL func(u string) error
+}
+
+// SSOResult is the result of a successful single sign-on.
+type SSOResult struct {
+	// ID identifies the user that signed on.
+	ID string
+
+	// Token is the OAuth2 token obtained for the user. Its access token is
+	// used as the session. The ID token of the token has been verified.
+	Token *oauth2.Token
+}
+
+// ssoCallback is the result of the authorization redirect.
+type ssoCallback struct {
+	code string
+	err  error
+}
+
+// LoginSSO signs on through the browser using the OpenID Connect
+// authorization code flow with PKCE. A local listener receives the redirect
+// from the authorization server, after which the code is exchanged for a
+// token.
+//
+// The flow follows these contracts:
+//   - the endpoints and signing keys of the OpenID provider are discovered
+//     from its metadata, as defined by OpenID Connect Discovery 1.0.
+//   - the authorization code grant is defined by section 4.1 of RFC 6749, and
+//     protected by a PKCE code challenge as defined by RFC 7636.
+//   - the ID token is validated as required by section 3.1.3.7 of OpenID
+//     Connect Core 1.0, including its signature, issuer, audience and nonce,
+//     so that the identity recorded for the profile is that of the user who
+//     signed on with the provider.
+func (c *Context) LoginSSO(ctx context.Context, cfg SSOConfig) (*SSOResult, error) { //nolint:gocyclo
+	client := c.httpClient()
+	issuer := c.APIEndpoint
+	if cfg.Issuer != nil {
+		issuer = cfg.Issuer
+	}
+	provider, err := discoverProvider(ctx, client, issuer.String())
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", ssoListenAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, errSSOListen)
+	}
+	defer ln.Close() // nolint:errcheck
+
+	conf := &oauth2.Config{
+		ClientID: cfg.ClientID,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   endpoint(cfg.AuthURL, provider.AuthorizationEndpoint),
+			TokenURL:  endpoint(cfg.TokenURL, provider.TokenEndpoint),
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		RedirectURL: fmt.Sprintf("http://%s%s", ln.Addr().String(), ssoCallbackPath),
+		Scopes:      []string{"openid", "profile", "email"},
+	}
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	callbacks := make(chan ssoCallback, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(ssoCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		// A callback that does not carry the state of our request was not
+		// redirected by the authorization server for this login, so it is
+		// rejected and we keep waiting for one that was.
+		if s := r.URL.Query().Get("state"); s != state {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, ssoFailurePage, html.EscapeString(errors.Errorf(errSSOStateFmt, s).Error()))
+			return
+		}
+		cb := callback(r)
+		if cb.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, ssoFailurePage, html.EscapeString(cb.err.Error()))
+		} else {
+			fmt.Fprint(w, ssoCallbackPage)
+		}
+		select {
+		case callbacks <- cb:
+		default:
+		}
+	})
+	srv := &http.Server{Handler: mux} // nolint:gosec
+	go srv.Serve(ln)                  // nolint:errcheck
+	defer srv.Close()                 // nolint:errcheck
+
+	authURL := conf.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+	if err := cfg.OpenURL(authURL); err != nil {
+		return nil, errors.Wrap(err, errSSOOpenURL)
+	}
+
+	var cb ssoCallback
+	select {
+	case cb = <-callbacks:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if cb.err != nil {
+		return nil, cb.err
+	}
+
+	tok, err := conf.Exchange(context.WithValue(ctx, oauth2.HTTPClient, client), cb.code,
+		oauth2.SetAuthURLParam("code_verifier", verifier),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, errSSOExchange)
+	}
+	raw, ok := tok.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New(errSSONoIDToken)
+	}
+	claims, err := verifyIDToken(ctx, client, provider, raw, cfg.ClientID, nonce)
+	if err != nil {
+		return nil, err
+	}
+	id, err := identity(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &SSOResult{ID: id, Token: tok}, nil
+}
+
+// endpoint returns the supplied override if it is not nil, or the supplied
+// discovered endpoint.
+func endpoint(override *url.URL, discovered string) string {
+	if override != nil {
+		return override.String()
+	}
+	return discovered
+}
+
+// httpClient returns an HTTP client that respects the TLS settings of the
+// Context.
+func (c *Context) httpClient() *http.Client {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipTLSVerify, //nolint:gosec
+	}
+	return &http.Client{Transport: tr}
+}
+
+// callback extracts the authorization code from the redirect of the
+// authorization server.
+func callback(r *http.Request) ssoCallback {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		if d := q.Get("error_description"); d != "" {
+			e = fmt.Sprintf("%s: %s", e, d)
+		}
+		return ssoCallback{err: errors.Errorf(errSSOAuthFmt, e)}
+	}
+	code := q.Get("code")
+	if code == "" {
+		return ssoCallback{err: errors.New(errSSONoCode)}
+	}
+	return ssoCallback{code: code}
+}
+
+// identity returns the user identified by the supplied verified ID token
+// claims, preferring their username over their email over their subject.
+func identity(claims jwt.MapClaims) (string, error) {
+	for _, k := range []string{"preferred_username", "email", "sub"} {
+		if v, ok := claims[k].(string); ok && v != "" {
+			return v, nil
+		}
+	}
+	return "", errors.New(errSSONoIdentity)
+}
+
+// randomString returns a URL safe random string suitable for use as an OAuth2
+// state or PKCE code verifier.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,342 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upbound
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const (
+	testAuthPath  = "/authorize"
+	testTokenPath = "/token"
+	testKeysPath  = "/keys"
+	testKeyID     = "cool-key"
+)
+
+// testOAuthServer is a stand-in OpenID provider. Its token endpoint issues a
+// token for the supplied code if the PKCE verifier matches the challenge of
+// the authorization request, with an ID token that has the claims returned by
+// claims and is signed by signer.
+type testOAuthServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	challenge string
+	nonce     string
+}
+
+func newTestOAuthServer(t *testing.T, code string, key, signer *rsa.PrivateKey, claims func(issuer, nonce string) jwt.MapClaims) *testOAuthServer {
+	t.Helper()
+	s := &testOAuthServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case oidcDiscoveryPath:
+			_ = json.NewEncoder(w).Encode(oidcProvider{
+				Issuer:                s.URL,
+				AuthorizationEndpoint: s.URL + testAuthPath,
+				TokenEndpoint:         s.URL + testTokenPath,
+				JWKSURI:               s.URL + testKeysPath,
+			})
+		case testKeysPath:
+			_ = json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{{
+				KeyID:   testKeyID,
+				KeyType: "RSA",
+				Use:     "sig",
+				N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case testTokenPath:
+			_ = r.ParseForm()
+			s.mu.Lock()
+			challenge, nonce := s.challenge, s.nonce
+			s.mu.Unlock()
+			sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+			if r.Form.Get("code") != code || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims(s.URL, nonce))
+			tok.Header["kid"] = testKeyID
+			idToken, err := tok.SignedString(signer)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "cool-session",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+				"id_token":     idToken,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return s
+}
+
+// authorize records the PKCE challenge and nonce of an authorization request.
+func (s *testOAuthServer) authorize(q url.Values) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.challenge = q.Get("code_challenge")
+	s.nonce = q.Get("nonce")
+}
+
+func TestLoginSSO(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validClaims := func(issuer, nonce string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   issuer,
+			"aud":   DefaultSSOClientID,
+			"sub":   "1234",
+			"email": "cool-user@upbound.io",
+			"nonce": nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	withClaim := func(k string, v any) func(issuer, nonce string) jwt.MapClaims {
+		return func(issuer, nonce string) jwt.MapClaims {
+			c := validClaims(issuer, nonce)
+			c[k] = v
+			return c
+		}
+	}
+	success := func(q url.Values) []url.Values {
+		return []url.Values{{"code": {"cool-code"}, "state": {q.Get("state")}}}
+	}
+
+	type args struct {
+		// browser simulates the user authorizing in the browser by returning
+		// the queries that the callback is redirected to, in order.
+		browser func(q url.Values) []url.Values
+		signer  *rsa.PrivateKey
+		claims  func(issuer, nonce string) jwt.MapClaims
+	}
+	type want struct {
+		id      string
+		session string
+		// statuses are the statuses of the responses to each callback but
+		// the last.
+		statuses []int
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "The code received by the callback should be exchanged for a session.",
+			args: args{
+				browser: success,
+				signer:  key,
+				claims:  validClaims,
+			},
+			want: want{
+				id:       "cool-user@upbound.io",
+				session:  "cool-session",
+				statuses: []int{},
+			},
+		},
+		"IgnoreStateMismatch": {
+			reason: "A callback with a different state should be rejected, and the callback with the state of the request awaited.",
+			args: args{
+				browser: func(q url.Values) []url.Values {
+					return []url.Values{
+						{"code": {"forged-code"}, "state": {"forged"}},
+						{"error": {"access_denied"}, "state": {"forged"}},
+						{"code": {"cool-code"}, "state": {q.Get("state")}},
+					}
+				},
+				signer: key,
+				claims: validClaims,
+			},
+			want: want{
+				id:       "cool-user@upbound.io",
+				session:  "cool-session",
+				statuses: []int{http.StatusBadRequest, http.StatusBadRequest},
+			},
+		},
+		"ErrorDenied": {
+			reason: "An error returned by the authorization server should be returned.",
+			args: args{
+				browser: func(q url.Values) []url.Values {
+					return []url.Values{{"error": {"access_denied"}, "state": {q.Get("state")}}}
+				},
+				signer: key,
+				claims: validClaims,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"ErrorWrongCode": {
+			reason: "An error should be returned if the code cannot be exchanged.",
+			args: args{
+				browser: func(q url.Values) []url.Values {
+					return []url.Values{{"code": {"wrong-code"}, "state": {q.Get("state")}}}
+				},
+				signer: key,
+				claims: validClaims,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"ErrorSignature": {
+			reason: "An ID token that is not signed by a key of the provider should be rejected.",
+			args: args{
+				browser: success,
+				signer:  other,
+				claims:  validClaims,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"ErrorIssuer": {
+			reason: "An ID token that was not issued by the provider should be rejected.",
+			args: args{
+				browser: success,
+				signer:  key,
+				claims:  withClaim("iss", "https://evil.example.com"),
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"ErrorAudience": {
+			reason: "An ID token that was issued to another client should be rejected.",
+			args: args{
+				browser: success,
+				signer:  key,
+				claims:  withClaim("aud", "other-client"),
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"ErrorNonce": {
+			reason: "An ID token that was issued for another authentication request should be rejected.",
+			args: args{
+				browser: success,
+				signer:  key,
+				claims:  withClaim("nonce", "replayed"),
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"ErrorExpired": {
+			reason: "An ID token that has expired should be rejected.",
+			args: args{
+				browser: success,
+				signer:  key,
+				claims:  withClaim("exp", time.Now().Add(-time.Hour).Unix()),
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := newTestOAuthServer(t, "cool-code", key, tc.args.signer, tc.args.claims)
+			defer srv.Close()
+			endpoint, _ := url.Parse(srv.URL)
+
+			upCtx := &Context{APIEndpoint: endpoint}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			statuses := []int{}
+			res, err := upCtx.LoginSSO(ctx, SSOConfig{
+				ClientID: DefaultSSOClientID,
+				OpenURL: func(u string) error {
+					authURL, err := url.Parse(u)
+					if err != nil {
+						return err
+					}
+					q := authURL.Query()
+					srv.authorize(q)
+					cbs := tc.args.browser(q)
+					for i, v := range cbs {
+						cb, err := url.Parse(q.Get("redirect_uri"))
+						if err != nil {
+							return err
+						}
+						cb.RawQuery = v.Encode()
+						if i == len(cbs)-1 {
+							go func() {
+								if res, err := http.Get(cb.String()); err == nil { // nolint:gosec,noctx
+									_ = res.Body.Close()
+								}
+							}()
+							break
+						}
+						res, err := http.Get(cb.String()) // nolint:gosec,noctx
+						if err != nil {
+							return err
+						}
+						_ = res.Body.Close()
+						statuses = append(statuses, res.StatusCode)
+					}
+					return nil
+				},
+			})
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoginSSO(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.statuses, statuses); diff != "" {
+				t.Errorf("\n%s\nLoginSSO(...): -want callback statuses, +got callback statuses:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, res.ID); diff != "" {
+				t.Errorf("\n%s\nLoginSSO(...): -want id, +got id:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.session, res.Token.AccessToken); diff != "" {
+				t.Errorf("\n%s\nLoginSSO(...): -want session, +got session:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
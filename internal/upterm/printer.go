@@ -0,0 +1,207 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upterm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/pterm/pterm"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats.
+const (
+	TableFormat    = "table"
+	WideFormat     = "wide"
+	JSONFormat     = "json"
+	YAMLFormat     = "yaml"
+	NameFormat     = "name"
+	JSONPathFormat = "jsonpath"
+	TemplateFormat = "go-template"
+)
+
+// Formats returns the output formats. The formats that are followed by their
+// template end with =.
+func Formats() []string {
+	return []string{TableFormat, WideFormat, JSONFormat, YAMLFormat, NameFormat, JSONPathFormat + "=", TemplateFormat + "="}
+}
+
+const (
+	errUnknownFormatFmt = "unknown output format %q: must be one of table, wide, json, yaml, name, jsonpath=<template>, or go-template=<template>"
+	errMissingTemplate  = "a template must be supplied, i.e. jsonpath={.items[*].name}"
+	errParseTemplate    = "unable to parse output template"
+	errExecuteTemplate  = "unable to execute output template"
+)
+
+// Format is an output format. The jsonpath and go-template formats are
+// followed by = and their template.
+type Format string
+
+// Validate returns an error if the format is unknown or is missing its
+// template.
+func (f Format) Validate() error {
+	_, _, err := f.parse()
+	return err
+}
+
+// parse splits the format into its kind and template.
+func (f Format) parse() (string, string, error) {
+	kind, tmpl, hasTmpl := strings.Cut(string(f), "=")
+	switch kind {
+	case TableFormat, WideFormat, JSONFormat, YAMLFormat, NameFormat:
+		if hasTmpl {
+			return "", "", errors.Errorf(errUnknownFormatFmt, f)
+		}
+	case JSONPathFormat, TemplateFormat:
+		if tmpl == "" {
+			return "", "", errors.New(errMissingTemplate)
+		}
+	default:
+		return "", "", errors.Errorf(errUnknownFormatFmt, f)
+	}
+	return kind, tmpl, nil
+}
+
+// OutputFlags are flags for commands that print lists of objects.
+type OutputFlags struct {
+	Output Format `short:"o" default:"table" predictor:"output-formats" help:"Output format. One of: table, wide, json, yaml, name, jsonpath=<template>, go-template=<template>."`
+}
+
+// A Column is a column of a table of objects.
+type Column[T any] struct {
+	// Header is the header of the column.
+	Header string
+
+	// Wide indicates that the column is only printed in the wide format.
+	Wide bool
+
+	// Value returns the value of the column for the supplied object.
+	Value func(T) string
+}
+
+// A Table describes how a list of objects is printed in the table formats.
+type Table[T any] struct {
+	// Columns are the columns of the table.
+	Columns []Column[T]
+
+	// Name returns the name of the supplied object, which is all that is
+	// printed of it in the name format.
+	Name func(T) string
+
+	// Empty is printed in place of a table that has no rows.
+	Empty string
+}
+
+// list is the structure that lists of objects are printed as in the
+// structured formats.
+type list[T any] struct {
+	Items []T `json:"items"`
+}
+
+// PrintList prints the supplied objects to the supplied writer in the format
+// selected by the supplied flags. Structured formats print the objects as a
+// list of items, i.e. the names of all objects are printed by
+// jsonpath={.items[*].name}.
+func PrintList[T any](w io.Writer, f OutputFlags, objs []T, t Table[T]) error { //nolint:gocyclo
+	kind, tmpl, err := f.Output.parse()
+	if err != nil {
+		return err
+	}
+	if objs == nil {
+		objs = []T{}
+	}
+	switch kind {
+	case TableFormat, WideFormat:
+		if len(objs) == 0 {
+			_, err := fmt.Fprintln(w, t.Empty)
+			return err
+		}
+		return pterm.DefaultTable.WithWriter(w).WithSeparator("   ").WithHasHeader().WithData(t.data(objs, kind == WideFormat)).Render()
+	case NameFormat:
+		for _, o := range objs {
+			if _, err := fmt.Fprintln(w, t.Name(o)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case YAMLFormat:
+		b, err := yaml.Marshal(list[T]{Items: objs})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case JSONFormat:
+		b, err := json.MarshalIndent(list[T]{Items: objs}, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	b, err := json.Marshal(list[T]{Items: objs})
+	if err != nil {
+		return err
+	}
+	// Templates are executed against the objects as they are printed in the
+	// json format, rather than against their Go types.
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	if kind == JSONPathFormat {
+		jp := jsonpath.New("output")
+		if err := jp.Parse(tmpl); err != nil {
+			return errors.Wrap(err, errParseTemplate)
+		}
+		return errors.Wrap(jp.Execute(w, data), errExecuteTemplate)
+	}
+	gt, err := template.New("output").Parse(tmpl)
+	if err != nil {
+		return errors.Wrap(err, errParseTemplate)
+	}
+	return errors.Wrap(gt.Execute(w, data), errExecuteTemplate)
+}
+
+// data returns the rows of the table, including its header.
+func (t Table[T]) data(objs []T, wide bool) [][]string {
+	cols := make([]Column[T], 0, len(t.Columns))
+	for _, c := range t.Columns {
+		if c.Wide && !wide {
+			continue
+		}
+		cols = append(cols, c)
+	}
+	data := make([][]string, len(objs)+1)
+	data[0] = make([]string, len(cols))
+	for i, c := range cols {
+		data[0][i] = c.Header
+	}
+	for i, o := range objs {
+		row := make([]string, len(cols))
+		for j, c := range cols {
+			row[j] = c.Value(o)
+		}
+		data[i+1] = row
+	}
+	return data
+}
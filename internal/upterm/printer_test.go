@@ -0,0 +1,120 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upterm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pterm/pterm"
+)
+
+type object struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+func TestPrintList(t *testing.T) {
+	pterm.DisableStyling()
+
+	objs := []object{{Name: "one", Status: "ready"}, {Name: "two", Status: "provisioning"}}
+	table := Table[object]{
+		Columns: []Column[object]{
+			{Header: "NAME", Value: func(o object) string { return o.Name }},
+			{Header: "STATUS", Wide: true, Value: func(o object) string { return o.Status }},
+		},
+		Name:  func(o object) string { return o.Name },
+		Empty: "No objects found",
+	}
+
+	type args struct {
+		format Format
+		objs   []object
+	}
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Table": {
+			reason: "Wide columns should not be printed in the table format.",
+			args:   args{format: TableFormat, objs: objs},
+			want:   want{out: "NAME\none \ntwo \n"},
+		},
+		"Wide": {
+			reason: "Wide columns should be printed in the wide format.",
+			args:   args{format: WideFormat, objs: objs},
+			want:   want{out: "NAME   STATUS      \none    ready       \ntwo    provisioning\n"},
+		},
+		"Empty": {
+			reason: "The empty message should be printed in place of a table with no rows.",
+			args:   args{format: TableFormat},
+			want:   want{out: "No objects found\n"},
+		},
+		"EmptyJSON": {
+			reason: "An empty list of items should be printed in structured formats.",
+			args:   args{format: JSONFormat},
+			want:   want{out: "{\n    \"items\": []\n}\n"},
+		},
+		"Name": {
+			reason: "Only names should be printed in the name format.",
+			args:   args{format: NameFormat, objs: objs},
+			want:   want{out: "one\ntwo\n"},
+		},
+		"YAML": {
+			reason: "Objects should be printed as a list of items in the yaml format.",
+			args:   args{format: YAMLFormat, objs: objs[:1]},
+			want:   want{out: "items:\n- name: one\n  status: ready\n"},
+		},
+		"JSONPath": {
+			reason: "A jsonpath template should be executed against the list of items.",
+			args:   args{format: "jsonpath={.items[*].name}", objs: objs},
+			want:   want{out: "one two"},
+		},
+		"GoTemplate": {
+			reason: "A Go template should be executed against the list of items as they are printed in json.",
+			args:   args{format: "go-template={{range .items}}{{.status}} {{end}}", objs: objs},
+			want:   want{out: "ready provisioning "},
+		},
+		"MissingTemplate": {
+			reason: "A template format without a template should return an error.",
+			args:   args{format: "jsonpath=", objs: objs},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"UnknownFormat": {
+			reason: "An unknown format should return an error.",
+			args:   args{format: "xml", objs: objs},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			err := PrintList(b, OutputFlags{Output: tc.args.format}, tc.args.objs, table)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPrintList(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, b.String()); diff != "" {
+				t.Errorf("\n%s\nPrintList(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -198,6 +198,72 @@ func (c *Local) Versions(k v1beta1.Dependency) ([]string, error) {
 	return vers, nil
 }
 
+// Packages returns all of the packages that currently exist in the cache.
+// Entries that cannot be parsed are skipped.
+func (c *Local) Packages() ([]*xpkg.ParsedPackage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pkgs := make([]*xpkg.ParsedPackage, 0)
+	err := c.walkEntries(func(path string) error {
+		if pkg, err := c.pkgres.FromDir(c.fs, path); err == nil {
+			pkgs = append(pkgs, pkg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgs, nil
+}
+
+// References returns references to all of the packages that currently exist
+// in the cache, in the form <registry>/<repository>:<version>. Unlike
+// Packages, entries are not parsed.
+func (c *Local) References() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	refs := make([]string, 0)
+	err := c.walkEntries(func(path string) error {
+		rel, err := filepath.Rel(c.root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		i := strings.LastIndex(rel, "@")
+		refs = append(refs, rel[:i]+":"+rel[i+1:])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refs, nil
+}
+
+// walkEntries calls the supplied function with the path of each package entry
+// in the cache. Callers must hold a lock on the cache.
+func (c *Local) walkEntries(fn func(path string) error) error {
+	return afero.Walk(c.fs, c.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		// package entries are directories of the form <repository>@<version>
+		if !info.IsDir() || !strings.Contains(info.Name(), "@") {
+			return nil
+		}
+		if err := fn(path); err != nil {
+			return err
+		}
+		return filepath.SkipDir
+	})
+}
+
 // Watch returns a channel that can be used to subscribe to events
 // from the cache.
 func (c *Local) Watch() <-chan Event {
@@ -440,6 +440,149 @@ func TestVersions(t *testing.T) {
 	}
 }
 
+func TestPackages(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	cache, _ := NewLocal(
+		"/cache",
+		WithFS(fs),
+	)
+	empty, _ := NewLocal(
+		"/empty",
+		WithFS(fs),
+	)
+
+	e1 := cache.newEntry(pkg1)
+	cache.add(e1, "index.docker.io/crossplane/provider-aws@v0.20.1-alpha")
+
+	e2 := cache.newEntry(pkg3)
+	cache.add(e2, "registry.upbound.io/crossplane/provider-gcp@v0.2.0")
+
+	type args struct {
+		cache *Local
+	}
+
+	type want struct {
+		locations []string
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "Should return all packages in the cache.",
+			args: args{
+				cache: cache,
+			},
+			want: want{
+				locations: []string{
+					"/cache/index.docker.io/crossplane/provider-aws@v0.20.1-alpha",
+					"/cache/registry.upbound.io/crossplane/provider-gcp@v0.2.0",
+				},
+			},
+		},
+		"CacheDNE": {
+			reason: "Should return an empty slice if the cache does not exist.",
+			args: args{
+				cache: empty,
+			},
+			want: want{
+				locations: []string{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkgs, err := tc.args.cache.Packages()
+
+			locs := make([]string, len(pkgs))
+			for i, p := range pkgs {
+				locs[i] = p.Location()
+			}
+
+			if diff := cmp.Diff(tc.want.locations, locs); diff != "" {
+				t.Errorf("\n%s\nPackages(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPackages(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	cache, _ := NewLocal(
+		"/cache",
+		WithFS(fs),
+	)
+	empty, _ := NewLocal(
+		"/empty",
+		WithFS(fs),
+	)
+
+	e1 := cache.newEntry(pkg1)
+	cache.add(e1, "index.docker.io/crossplane/provider-aws@v0.20.1-alpha")
+
+	e2 := cache.newEntry(pkg3)
+	cache.add(e2, "registry.upbound.io/crossplane/provider-gcp@v0.2.0")
+
+	type args struct {
+		cache *Local
+	}
+
+	type want struct {
+		refs []string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Success": {
+			reason: "Should return references to all packages in the cache.",
+			args: args{
+				cache: cache,
+			},
+			want: want{
+				refs: []string{
+					"index.docker.io/crossplane/provider-aws:v0.20.1-alpha",
+					"registry.upbound.io/crossplane/provider-gcp:v0.2.0",
+				},
+			},
+		},
+		"CacheDNE": {
+			reason: "Should return an empty slice if the cache does not exist.",
+			args: args{
+				cache: empty,
+			},
+			want: want{
+				refs: []string{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			refs, err := tc.args.cache.References()
+
+			if diff := cmp.Diff(tc.want.refs, refs); diff != "" {
+				t.Errorf("\n%s\nReferences(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReferences(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestCalculatePath(t *testing.T) {
 	tag1, _ := ociname.NewTag("crossplane/provider-aws:v0.20.1-alpha")
 	tag2, _ := ociname.NewTag("gcr.io/crossplane/provider-gcp:v1.0.0")
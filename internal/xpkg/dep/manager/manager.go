@@ -64,6 +64,7 @@ type Cache interface {
 	Get(v1beta1.Dependency) (*xpkg.ParsedPackage, error)
 	Store(v1beta1.Dependency, *xpkg.ParsedPackage) error
 	Versions(v1beta1.Dependency) ([]string, error)
+	Packages() ([]*xpkg.ParsedPackage, error)
 	Watch() <-chan cache.Event
 }
 
@@ -190,6 +191,12 @@ func (m *Manager) Versions(ctx context.Context, d v1beta1.Dependency) ([]string,
 	return m.c.Versions(d)
 }
 
+// Packages returns all of the packages that currently exist locally,
+// regardless of whether they are dependencies of the current workspace.
+func (m *Manager) Packages(ctx context.Context) ([]*xpkg.ParsedPackage, error) {
+	return m.c.Packages()
+}
+
 // Watch provides a hook for watching changes coming from the cache.
 func (m *Manager) Watch() <-chan cache.Event {
 	return m.c.Watch()
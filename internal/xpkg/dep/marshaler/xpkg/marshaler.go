@@ -134,6 +134,7 @@ func (r *Marshaler) FromDir(fs afero.Fs, path string) (*ParsedPackage, error) {
 	if err != nil {
 		return nil, err
 	}
+	pkg.Dir = path
 
 	return finalizePkg(pkg)
 }
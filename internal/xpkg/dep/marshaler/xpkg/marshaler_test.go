@@ -284,6 +284,7 @@ func TestFromDir(t *testing.T) {
 						},
 					},
 					DepName: "crossplane/provider-helm",
+					Dir:     path1,
 					PType:   v1beta1.ProviderPackageType,
 					Reg:     "index.docker.io",
 					Ver:     "v0.9.0",
@@ -329,6 +330,7 @@ func TestFromDir(t *testing.T) {
 						},
 					},
 					DepName: "registry.upbound.io/crossplane/provider-helm",
+					Dir:     path2,
 					PType:   v1beta1.ProviderPackageType,
 					Reg:     "registry.upbound.io",
 					Ver:     "v0.9.0",
@@ -398,6 +400,10 @@ func TestFromDir(t *testing.T) {
 				if diff := cmp.Diff(tc.want.pkg.Version(), pkg.Version()); diff != "" {
 					t.Errorf("\n%s\nFromDir(...): -want err, +got err:\n%s", tc.reason, diff)
 				}
+
+				if diff := cmp.Diff(tc.want.pkg.Location(), pkg.Location()); diff != "" {
+					t.Errorf("\n%s\nFromDir(...): -want err, +got err:\n%s", tc.reason, diff)
+				}
 			}
 
 		})
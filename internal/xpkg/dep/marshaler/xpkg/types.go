@@ -41,6 +41,9 @@ type ParsedPackage struct {
 	// in the crossplane.yaml and is represented in the directory name for
 	// the package on the filesystem.
 	DepName string
+	// The directory the package was loaded from, if it was loaded from the
+	// local cache.
+	Dir string
 	// The N corresponding Objs (CRDs, XRDs, Compositions) depending on the package type.
 	Objs []runtime.Object
 	// The type of Package.
@@ -73,6 +76,12 @@ func (p *ParsedPackage) Name() string {
 	return p.DepName
 }
 
+// Location returns the directory the package was loaded from, if any.
+// e.g. ~/.up/cache/index.docker.io/crossplane/provider-aws@v0.20.0
+func (p *ParsedPackage) Location() string {
+	return p.Dir
+}
+
 // Objects returns the slice of runtime.Objects corresponding to CRDs, XRDs, and
 // Compositions contained in the package.
 func (p *ParsedPackage) Objects() []runtime.Object {
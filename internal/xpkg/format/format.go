@@ -0,0 +1,446 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package format formats package YAML in a canonical style.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/lexer"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// indent is the number of spaces each level of nesting is indented by.
+	indent = 2
+
+	docSeparator = "---"
+	metaGroup    = "meta.pkg.crossplane.io/"
+
+	keyAPIVersion = "apiVersion"
+	keyKind       = "kind"
+	keySpec       = "spec"
+	keyDependsOn  = "dependsOn"
+
+	errCommentsLost = "formatting would not preserve all comments"
+)
+
+var (
+	// wellKnownKeys are the keys of Kubernetes objects that are ordered
+	// first, in the order they appear.
+	wellKnownKeys = []string{keyAPIVersion, keyKind, "metadata", keySpec}
+
+	// packageKeys are the keys that identify the package of a dependency.
+	packageKeys = []string{"provider", "configuration", "function"}
+)
+
+// A Document is a single YAML document within a file.
+type Document struct {
+	// StartLine is the zero-indexed line the document starts on.
+	StartLine int
+	// EndLine is the zero-indexed line after the last line of the document.
+	EndLine int
+	// Content is the content of the document.
+	Content []byte
+}
+
+// Split splits the supplied file content into its documents. Document
+// separators are not included in any document.
+func Split(b []byte) []Document {
+	docs := []Document{}
+	lines := strings.SplitAfter(string(b), "\n")
+	start := 0
+	var buf strings.Builder
+	for i, l := range lines {
+		if !isSeparator(l) {
+			buf.WriteString(l)
+			continue
+		}
+		docs = append(docs, Document{StartLine: start, EndLine: i, Content: []byte(buf.String())})
+		buf.Reset()
+		start = i + 1
+	}
+	end := len(lines)
+	if lines[end-1] == "" {
+		// content ends with a newline.
+		end--
+	}
+	return append(docs, Document{StartLine: start, EndLine: end, Content: []byte(buf.String())})
+}
+
+// isSeparator returns true if the supplied line is a document separator.
+func isSeparator(l string) bool {
+	l = strings.TrimRight(l, "\r\n")
+	return l == docSeparator || strings.HasPrefix(l, docSeparator+" ")
+}
+
+// Format formats each document in the supplied file content.
+func Format(b []byte) ([]byte, error) {
+	lines := strings.SplitAfter(string(b), "\n")
+	var buf bytes.Buffer
+	for i, d := range Split(b) {
+		if i > 0 {
+			// preserve the separator preceding the document.
+			buf.WriteString(lines[d.StartLine-1])
+		}
+		f, err := FormatDocument(d.Content)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(f)
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatDocument formats a single YAML document. Indentation is normalized,
+// the well-known keys of Kubernetes objects are ordered first, and the
+// dependencies of package meta files are sorted. Comments are preserved.
+func FormatDocument(b []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return b, nil
+	}
+	// the AST parser accepts some malformed input, which must not be
+	// rewritten into something valid.
+	if _, err := yaml.YAMLToJSON(b); err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseBytes(b, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range f.Docs {
+		if doc.Body == nil {
+			continue
+		}
+		orderKeys(doc.Body)
+		if isMeta(doc.Body) {
+			sortDependencies(doc.Body)
+		}
+		normalize(doc.Body, 1)
+	}
+
+	out := []byte(strings.TrimRight(f.String(), "\n") + "\n")
+	if !equalComments(b, out) {
+		return nil, errors.New(errCommentsLost)
+	}
+	// ensure we produced valid YAML.
+	if _, err := parser.ParseBytes(out, parser.ParseComments); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// normalize indents the supplied node such that it starts at the supplied
+// column, and nested nodes are indented consistently beneath it. Sequences
+// are not indented relative to the key that contains them.
+func normalize(n ast.Node, col int) {
+	switch t := n.(type) {
+	case *ast.MappingNode:
+		if t.IsFlowStyle {
+			t.AddColumn(col - t.GetToken().Position.Column)
+			return
+		}
+		for _, mv := range t.Values {
+			normalize(mv, col)
+		}
+	case *ast.MappingValueNode:
+		t.AddColumn(col - t.Key.GetToken().Position.Column)
+		switch v := t.Value.(type) {
+		case *ast.MappingNode, *ast.MappingValueNode:
+			normalize(v, col+indent)
+		case *ast.SequenceNode:
+			normalize(v, col)
+		case *ast.LiteralNode:
+			reindentLiteral(v, col-1+indent)
+		case *ast.StringNode:
+			if isMultiline(v) {
+				t.Value = &multiline{StringNode: v, indent: col - 1 + indent}
+			}
+		}
+	case *ast.SequenceNode:
+		if t.IsFlowStyle {
+			return
+		}
+		t.AddColumn(col - t.Start.Position.Column)
+		for i, v := range t.Values {
+			switch vv := v.(type) {
+			case *ast.MappingNode, *ast.MappingValueNode, *ast.SequenceNode:
+				normalize(vv, col+indent)
+			case *ast.LiteralNode:
+				// sequence entries are indented relative to the entry
+				// marker when printed.
+				reindentLiteral(vv, 0)
+			case *ast.StringNode:
+				if isMultiline(vv) {
+					t.Values[i] = &multiline{StringNode: vv}
+				}
+			}
+		}
+	}
+}
+
+// reindentLiteral indents the lines of the supplied block scalar by the
+// supplied number of spaces, preserving their relative indentation.
+func reindentLiteral(l *ast.LiteralNode, spaces int) {
+	tok := l.Value.GetToken()
+	lines := strings.Split(tok.Origin, "\n")
+	min := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if i := len(line) - len(strings.TrimLeft(line, " ")); min == -1 || i < min {
+			min = i
+		}
+	}
+	if min == -1 {
+		return
+	}
+	prefix := strings.Repeat(" ", spaces)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = prefix + line[min:]
+	}
+	tok.Origin = strings.Join(lines, "\n")
+}
+
+// isMultiline returns true if the supplied scalar spans multiple lines in
+// its source.
+func isMultiline(n *ast.StringNode) bool {
+	return strings.Contains(strings.TrimSpace(n.GetToken().Origin), "\n")
+}
+
+// A multiline is a plain or quoted scalar that spans multiple lines. The
+// scalar is printed as it appears in its source rather than folded onto a
+// single line, with its continuation lines reindented.
+type multiline struct {
+	*ast.StringNode
+
+	// indent is the number of spaces continuation lines are indented by.
+	indent int
+}
+
+// String prints the scalar as it appears in its source. Leading and trailing
+// whitespace of each line is not significant in flow scalars, so it is
+// replaced.
+func (m *multiline) String() string {
+	lines := strings.Split(strings.TrimSpace(m.GetToken().Origin), "\n")
+	prefix := strings.Repeat(" ", m.indent)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if i > 0 && line != "" {
+			line = prefix + line
+		}
+		lines[i] = line
+	}
+	s := strings.Join(lines, "\n")
+	if m.Comment != nil {
+		s += " " + m.Comment.String()
+	}
+	return s
+}
+
+// orderKeys orders the well-known keys of every Kubernetes object within the
+// supplied node first. The order of all other keys is preserved.
+func orderKeys(n ast.Node) {
+	ast.Walk(visitor(func(n ast.Node) {
+		m, ok := n.(*ast.MappingNode)
+		if !ok || m.IsFlowStyle || !isObject(m) {
+			return
+		}
+		sort.SliceStable(m.Values, func(i, j int) bool {
+			return keyRank(m.Values[i]) < keyRank(m.Values[j])
+		})
+	}), n)
+}
+
+// keyRank returns the position of the key of the supplied mapping value in
+// the well-known keys, or the number of well-known keys if it is not one.
+func keyRank(mv *ast.MappingValueNode) int {
+	k := keyOf(mv)
+	for i, w := range wellKnownKeys {
+		if k == w {
+			return i
+		}
+	}
+	return len(wellKnownKeys)
+}
+
+// isObject returns true if the supplied mapping has both an apiVersion and a
+// kind.
+func isObject(m *ast.MappingNode) bool {
+	_, av := value(m, keyAPIVersion)
+	_, k := value(m, keyKind)
+	return av && k
+}
+
+// isMeta returns true if the supplied document body is a package meta file.
+func isMeta(n ast.Node) bool {
+	m, ok := n.(*ast.MappingNode)
+	if !ok {
+		return false
+	}
+	av, ok := value(m, keyAPIVersion)
+	return ok && strings.HasPrefix(scalar(av), metaGroup)
+}
+
+// sortDependencies sorts the dependencies of the supplied package meta file by
+// package. Comments preceding a dependency are moved with it.
+func sortDependencies(n ast.Node) {
+	spec, ok := value(n.(*ast.MappingNode), keySpec)
+	if !ok {
+		return
+	}
+	sm, ok := asMapping(spec)
+	if !ok {
+		return
+	}
+	deps, ok := value(sm, keyDependsOn)
+	if !ok {
+		return
+	}
+	seq, ok := deps.(*ast.SequenceNode)
+	if !ok || seq.IsFlowStyle || len(seq.Values) < 2 {
+		return
+	}
+
+	comments := make([]*ast.CommentGroupNode, len(seq.Values))
+	copy(comments, seq.ValueComments)
+	if seq.Comment != nil && comments[0] == nil {
+		// a comment preceding the first dependency is attached to the
+		// sequence itself.
+		comments[0], seq.Comment = seq.Comment, nil
+	}
+
+	type entry struct {
+		value   ast.Node
+		comment *ast.CommentGroupNode
+	}
+	entries := make([]entry, len(seq.Values))
+	for i := range seq.Values {
+		entries[i] = entry{value: seq.Values[i], comment: comments[i]}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		pi, pj := packageOf(entries[i].value), packageOf(entries[j].value)
+		if pi == "" || pj == "" {
+			return pi != "" && pj == ""
+		}
+		return pi < pj
+	})
+	for i, e := range entries {
+		seq.Values[i], comments[i] = e.value, e.comment
+	}
+	seq.ValueComments = comments
+}
+
+// packageOf returns the package of the supplied dependency, or an empty
+// string if it does not declare one.
+func packageOf(n ast.Node) string {
+	m, ok := asMapping(n)
+	if !ok {
+		return ""
+	}
+	for _, k := range packageKeys {
+		if v, ok := value(m, k); ok {
+			return scalar(v)
+		}
+	}
+	return ""
+}
+
+// asMapping returns the supplied node as a mapping node. Mappings with a
+// single value are parsed as mapping value nodes.
+func asMapping(n ast.Node) (*ast.MappingNode, bool) {
+	switch t := n.(type) {
+	case *ast.MappingNode:
+		return t, true
+	case *ast.MappingValueNode:
+		return &ast.MappingNode{BaseNode: t.BaseNode, Start: t.Start, Values: []*ast.MappingValueNode{t}}, true
+	}
+	return nil, false
+}
+
+// value returns the value of the supplied key in the supplied mapping.
+func value(m *ast.MappingNode, key string) (ast.Node, bool) {
+	for _, mv := range m.Values {
+		if keyOf(mv) == key {
+			return mv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// keyOf returns the key of the supplied mapping value.
+func keyOf(mv *ast.MappingValueNode) string {
+	if mv.Key == nil || mv.Key.GetToken() == nil {
+		return ""
+	}
+	return mv.Key.GetToken().Value
+}
+
+// scalar returns the value of the supplied scalar node.
+func scalar(n ast.Node) string {
+	if n == nil || n.GetToken() == nil {
+		return ""
+	}
+	return n.GetToken().Value
+}
+
+// equalComments returns true if the supplied inputs contain the same
+// comments.
+func equalComments(a, b []byte) bool {
+	ca, cb := comments(a), comments(b)
+	if len(ca) != len(cb) {
+		return false
+	}
+	for i := range ca {
+		if ca[i] != cb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// comments returns the sorted comments in the supplied input.
+func comments(b []byte) []string {
+	c := []string{}
+	for _, t := range lexer.Tokenize(string(b)) {
+		if t.Type == token.CommentType {
+			c = append(c, strings.TrimSpace(t.Value))
+		}
+	}
+	sort.Strings(c)
+	return c
+}
+
+// visitor is an ast.Visitor that calls a function for each node it visits.
+type visitor func(ast.Node)
+
+// Visit implements ast.Visitor.
+func (v visitor) Visit(n ast.Node) ast.Visitor {
+	if n != nil {
+		v(n)
+	}
+	return v
+}
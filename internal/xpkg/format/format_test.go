@@ -0,0 +1,291 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestFormat(t *testing.T) {
+	type args struct {
+		in string
+	}
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AlreadyFormatted": {
+			reason: "Formatted input should be returned unchanged.",
+			args: args{
+				in: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cool
+data:
+  key: value
+`,
+			},
+			want: want{
+				out: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cool
+data:
+  key: value
+`,
+			},
+		},
+		"NormalizeIndentation": {
+			reason: "Nested mappings should be indented by two spaces, and sequences should not be indented relative to their key.",
+			args: args{
+				in: `apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: cool
+    labels:
+        app: cool
+data:
+    items:
+        - name: a
+          value: b
+`,
+			},
+			want: want{
+				out: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cool
+  labels:
+    app: cool
+data:
+  items:
+  - name: a
+    value: b
+`,
+			},
+		},
+		"OrderWellKnownKeys": {
+			reason: "The well-known keys of an object should be ordered first, preserving the order of other keys.",
+			args: args{
+				in: `spec:
+  replicas: 1
+data: a
+metadata:
+  name: cool
+kind: ConfigMap
+other: b
+apiVersion: v1
+`,
+			},
+			want: want{
+				out: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cool
+spec:
+  replicas: 1
+data: a
+other: b
+`,
+			},
+		},
+		"PreserveComments": {
+			reason: "Comments should be preserved when reordering keys.",
+			args: args{
+				in: `# head
+kind: ConfigMap
+# version
+apiVersion: v1
+metadata:
+  name: cool # inline
+`,
+			},
+			want: want{
+				out: `# head
+# version
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cool # inline
+`,
+			},
+		},
+		"SortDependencies": {
+			reason: "Dependencies of a package meta file should be sorted by package, keeping their comments.",
+			args: args{
+				in: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: cool
+spec:
+  dependsOn:
+    # zeta
+    - provider: xpkg.upbound.io/zeta
+      version: v1
+    # alpha
+    - configuration: xpkg.upbound.io/alpha
+      version: v2
+`,
+			},
+			want: want{
+				out: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: cool
+spec:
+  dependsOn:
+  # alpha
+  - configuration: xpkg.upbound.io/alpha
+    version: v2
+  # zeta
+  - provider: xpkg.upbound.io/zeta
+    version: v1
+`,
+			},
+		},
+		"LiteralBlock": {
+			reason: "Block scalars should be reindented, preserving their relative indentation.",
+			args: args{
+				in: `apiVersion: v1
+kind: ConfigMap
+data:
+    script: |
+          echo a
+            echo b
+`,
+			},
+			want: want{
+				out: `apiVersion: v1
+kind: ConfigMap
+data:
+  script: |
+    echo a
+      echo b
+`,
+			},
+		},
+		"MultilineScalar": {
+			reason: "Scalars spanning multiple lines should not be folded onto a single line.",
+			args: args{
+				in: `apiVersion: v1
+kind: ConfigMap
+data:
+    description: a long
+        description
+    quoted:
+    - 'a long
+       quoted value'
+`,
+			},
+			want: want{
+				out: `apiVersion: v1
+kind: ConfigMap
+data:
+  description: a long
+    description
+  quoted:
+  - 'a long
+    quoted value'
+`,
+			},
+		},
+		"MultipleDocuments": {
+			reason: "Each document should be formatted, preserving separators.",
+			args: args{
+				in: `kind: A
+apiVersion: v1
+---
+kind: B
+apiVersion: v1
+`,
+			},
+			want: want{
+				out: `apiVersion: v1
+kind: A
+---
+apiVersion: v1
+kind: B
+`,
+			},
+		},
+		"InvalidYAML": {
+			reason: "Invalid YAML should return an error.",
+			args: args{
+				in: "a: b: c\n",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := Format([]byte(tc.args.in))
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nFormat(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, string(out)); diff != "" {
+				t.Errorf("\n%s\nFormat(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	type want struct {
+		docs []Document
+	}
+	cases := map[string]struct {
+		reason string
+		in     string
+		want   want
+	}{
+		"SingleDocument": {
+			reason: "A file without separators should be a single document.",
+			in:     "a: b\nc: d\n",
+			want: want{
+				docs: []Document{{StartLine: 0, EndLine: 2, Content: []byte("a: b\nc: d\n")}},
+			},
+		},
+		"MultipleDocuments": {
+			reason: "Separators should not be included in any document.",
+			in:     "a: b\n---\nc: d\n",
+			want: want{
+				docs: []Document{
+					{StartLine: 0, EndLine: 1, Content: []byte("a: b\n")},
+					{StartLine: 2, EndLine: 3, Content: []byte("c: d\n")},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			docs := Split([]byte(tc.in))
+
+			if diff := cmp.Diff(tc.want.docs, docs); diff != "" {
+				t.Errorf("\n%s\nSplit(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	rootPathPrefix = "$."
+)
+
+// nodeAt returns the workspace node and the scalar YAML node found at the
+// supplied position in the file at the supplied uri. key indicates whether the
+// scalar is a mapping key. A nil scalar is returned if no scalar exists at the
+// position.
+func (s *Snapshot) nodeAt(uri span.URI, pos protocol.Position) (n workspace.Node, scalar ast.Node, key bool) {
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return nil, nil, false
+	}
+	for id := range details.NodeIDs {
+		n, ok := s.wsview.Nodes()[id]
+		if !ok {
+			continue
+		}
+		if scalar, key := scalarAt(n.GetAST(), pos); scalar != nil {
+			return n, scalar, key
+		}
+	}
+	return nil, nil, false
+}
+
+// scalarAt walks the supplied AST and returns the scalar node whose token spans
+// the supplied position, along with whether that scalar is a mapping key.
+func scalarAt(n ast.Node, pos protocol.Position) (ast.Node, bool) { // nolint:gocyclo
+	switch t := n.(type) {
+	case nil:
+		return nil, false
+	case *ast.DocumentNode:
+		return scalarAt(t.Body, pos)
+	case *ast.MappingNode:
+		for _, v := range t.Values {
+			if s, key := scalarAt(v, pos); s != nil {
+				return s, key
+			}
+		}
+	case *ast.MappingValueNode:
+		if s, _ := scalarAt(t.Key, pos); s != nil {
+			return s, true
+		}
+		return scalarAt(t.Value, pos)
+	case *ast.MappingKeyNode:
+		return scalarAt(t.Value, pos)
+	case *ast.SequenceNode:
+		for _, v := range t.Values {
+			if s, key := scalarAt(v, pos); s != nil {
+				return s, key
+			}
+		}
+	case *ast.TagNode:
+		return scalarAt(t.Value, pos)
+	case *ast.AnchorNode:
+		return scalarAt(t.Value, pos)
+	case *ast.CommentNode, *ast.CommentGroupNode:
+		return nil, false
+	default:
+		if inRange(tokenRange(n.GetToken()), pos) {
+			return n, false
+		}
+	}
+	return nil, false
+}
+
+// tokenRange returns the zero-indexed protocol.Range spanned by the supplied
+// token.
+// TODO(hasheddan): token position reflects file line and column by NOT being
+// zero-indexed, but VSCode interprets ranges with zero-indexing. We should
+// develop a more robust solution for this conversion.
+func tokenRange(tok *token.Token) protocol.Range {
+	if tok == nil {
+		return protocol.Range{}
+	}
+	startCh := tok.Position.Column - 1
+	endCh := startCh + len(tok.Value)
+
+	// end character can be unmatched if we have quotes
+	switch tok.Type { // nolint:exhaustive
+	case token.DoubleQuoteType, token.SingleQuoteType:
+		endCh += 2
+	}
+
+	return protocol.Range{
+		Start: protocol.Position{
+			Line:      uint32(tok.Position.Line - 1),
+			Character: uint32(startCh),
+		},
+		End: protocol.Position{
+			Line:      uint32(tok.Position.Line - 1),
+			Character: uint32(endCh),
+		},
+	}
+}
+
+// inRange returns true if the supplied position is within the supplied range.
+func inRange(r protocol.Range, pos protocol.Position) bool {
+	if pos.Line < r.Start.Line || pos.Line > r.End.Line {
+		return false
+	}
+	if pos.Line == r.Start.Line && pos.Character < r.Start.Character {
+		return false
+	}
+	if pos.Line == r.End.Line && pos.Character > r.End.Character {
+		return false
+	}
+	return true
+}
+
+// fieldPath returns the field path of the supplied node relative to the root
+// of its document, e.g. spec.resources[0].base.
+func fieldPath(n ast.Node) string {
+	return strings.TrimPrefix(n.GetPath(), rootPathPrefix)
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+// rootFieldPath is the field of errors of rules declared at the root of the
+// schema, which are evaluated from a nil field path.
+var rootFieldPath = (*field.Path)(nil).String()
+
+// CELValidator evaluates the x-kubernetes-validations rules of a schema
+// against objects, as the API server would when they are created.
+type CELValidator struct {
+	structural *structuralschema.Structural
+	rules      *cel.Validator
+}
+
+// newCELValidator returns a CELValidator for the supplied schema. A nil
+// validator is returned if the schema does not contain any rules, or if it is
+// not structural and its rules therefore could not be evaluated.
+func newCELValidator(s *apiextensions.JSONSchemaProps) *CELValidator {
+	if s == nil {
+		return nil
+	}
+	ss, err := structuralschema.NewStructural(s)
+	if err != nil {
+		return nil
+	}
+	rules := cel.NewValidator(ss, cel.PerCallLimit)
+	if rules == nil {
+		return nil
+	}
+	return &CELValidator{
+		structural: ss,
+		rules:      rules,
+	}
+}
+
+// Validate evaluates the rules of the CELValidator against the supplied data.
+// Rules that fail are surfaced on the field that declares them.
+func (c *CELValidator) Validate(data any) *validate.Result {
+	obj, ok := unstructuredContent(data)
+	if !ok {
+		return validator.Nop
+	}
+	ferrs, _ := c.rules.Validate(context.Background(), nil, c.structural, obj, nil, cel.RuntimeCELCostBudget)
+
+	errs := make([]error, len(ferrs))
+	for i, e := range ferrs {
+		name := e.Field
+		if name == "" || name == rootFieldPath {
+			// rules declared at the root of the schema apply to the object
+			// as a whole.
+			name = apiVersionField
+		}
+		errs[i] = &validator.Validation{
+			TypeCode: validator.FieldErrorTypeCode,
+			Message:  e.Detail,
+			Name:     name,
+			Reason:   validator.ReasonRuleFailed,
+		}
+	}
+	return &validate.Result{
+		Errors: errs,
+	}
+}
+
+// unstructuredContent returns the supplied data as an unstructured object.
+func unstructuredContent(data any) (map[string]any, bool) {
+	switch o := data.(type) {
+	case map[string]any:
+		return o, true
+	case runtime.Unstructured:
+		return o.UnstructuredContent(), true
+	case runtime.Object:
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+		return u, err == nil
+	}
+	return nil, false
+}
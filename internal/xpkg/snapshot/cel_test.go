@@ -0,0 +1,184 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+var testCELXRD = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xclusters.acme.io
+spec:
+  group: acme.io
+  names:
+    kind: XCluster
+    plural: xclusters
+  claimNames:
+    kind: Cluster
+    plural: clusters
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        x-kubernetes-validations:
+        - rule: "!has(self.spec.minSize) || self.spec.minSize >= 0"
+          message: minSize must not be negative
+        properties:
+          spec:
+            type: object
+            x-kubernetes-validations:
+            - rule: self.minSize <= self.maxSize
+              message: minSize must not exceed maxSize
+            properties:
+              minSize:
+                type: integer
+              maxSize:
+                type: integer
+              name:
+                type: string
+                x-kubernetes-validations:
+                - rule: self.startsWith('acme-')
+`)
+
+func testCluster(spec map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "acme.io/v1alpha1",
+		"kind":       "Cluster",
+		"metadata":   map[string]any{"name": "example"},
+		"spec":       spec,
+	}}
+}
+
+func TestCELValidator(t *testing.T) {
+	xrd := &xpextv1.CompositeResourceDefinition{}
+	if err := yaml.Unmarshal(testCELXRD, xrd); err != nil {
+		t.Fatal(err)
+	}
+	validators := map[schema.GroupVersionKind]*validator.ObjectValidator{}
+	if err := validatorsFromV1XRD(xrd, validators); err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		gvk schema.GroupVersionKind
+		obj runtime.Object
+	}
+	type want struct {
+		errs []error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClaimValid": {
+			reason: "A claim that satisfies the rules of its schema should not return errors.",
+			args: args{
+				gvk: gvk("acme.io", "v1alpha1", "Cluster"),
+				obj: testCluster(map[string]any{"minSize": int64(1), "maxSize": int64(3), "name": "acme-cluster"}),
+			},
+			want: want{
+				errs: []error{},
+			},
+		},
+		"ClaimInvalid": {
+			reason: "Rules that a claim does not satisfy should be surfaced on the fields that declare them.",
+			args: args{
+				gvk: gvk("acme.io", "v1alpha1", "Cluster"),
+				obj: testCluster(map[string]any{"minSize": int64(5), "maxSize": int64(3), "name": "cluster"}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "minSize must not exceed maxSize",
+						Name:     "spec",
+						Reason:   validator.ReasonRuleFailed,
+					},
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "failed rule: self.startsWith('acme-')",
+						Name:     "spec.name",
+						Reason:   validator.ReasonRuleFailed,
+					},
+				},
+			},
+		},
+		"RootInvalid": {
+			reason: "Rules declared at the root of the schema should be evaluated against the whole object.",
+			args: args{
+				gvk: gvk("acme.io", "v1alpha1", "Cluster"),
+				obj: testCluster(map[string]any{"minSize": int64(-1), "maxSize": int64(3), "name": "acme-cluster"}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "minSize must not be negative",
+						Name:     apiVersionField,
+						Reason:   validator.ReasonRuleFailed,
+					},
+				},
+			},
+		},
+		"CompositeInvalid": {
+			reason: "Rules of the XRD should also be evaluated for composite resources.",
+			args: args{
+				gvk: gvk("acme.io", "v1alpha1", "XCluster"),
+				obj: testCluster(map[string]any{"minSize": int64(5), "maxSize": int64(3)}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "minSize must not exceed maxSize",
+						Name:     "spec",
+						Reason:   validator.ReasonRuleFailed,
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs := []error{}
+			for _, e := range validators[tc.args.gvk].Validate(tc.args.obj).Errors {
+				// only errors from rules are of interest here.
+				if _, ok := e.(*validator.Validation); ok { //nolint:errorlint
+					errs = append(errs, e)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.errs, errs); diff != "" {
+				t.Errorf("\n%s\nValidate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-containerregistry/pkg/name"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	verrors "k8s.io/kube-openapi/pkg/validation/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	// CommandResolveDependencies is the command used to resolve the
+	// dependencies declared in the workspace's crossplane.yaml and add them
+	// to the cache.
+	CommandResolveDependencies = "xpls.dep.resolve"
+	// CommandAddDependency is the command used to add a package to the cache
+	// and to the dependencies declared in the workspace's crossplane.yaml.
+	CommandAddDependency = "xpls.dep.add"
+	// CommandBuild is the command used to build the workspace's package.
+	CommandBuild = "xpls.build"
+	// CommandRender is the command used to render the resources composed by
+	// a Composition.
+	CommandRender = "xpls.render"
+
+	fieldSpec     = "spec"
+	pathDependsOn = "spec.dependsOn"
+
+	dependsOnFmt      = "dependsOn:\n%s%s"
+	dependencyFmt     = "- %s: %s\n%s  version: %q\n%s"
+	requiredFieldFmt  = "%s: %s\n%s"
+	titleAddDepFmt    = "Add dependency on %s@%s to crossplane.yaml"
+	titleAPIVersion   = "Update apiVersion to %s"
+	titleRequiredFmt  = "Add required field %s"
+	titleResolveDeps  = "Resolve dependencies with `up xpkg dep`"
+	placeholderString = `""`
+)
+
+// CodeActions returns the quick fixes available for the validation errors
+// that overlap the supplied range in the file at the supplied uri.
+func (s *Snapshot) CodeActions(ctx context.Context, uri span.URI, rng protocol.Range) ([]protocol.CodeAction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+
+	actions := []protocol.CodeAction{}
+	for id := range details.NodeIDs {
+		n, ok := s.wsview.Nodes()[id]
+		if !ok {
+			return nil, errors.New(errInvalidNodeID)
+		}
+		for _, e := range validationErrors(s.validateNode(n), n.GetGVK()) {
+			en, ok := errorNode(e, n.GetAST())
+			if !ok {
+				continue
+			}
+			diag := newDiagnostic(e, en)
+			if !overlaps(diag.Range, rng) {
+				continue
+			}
+			actions = append(actions, s.quickFixes(ctx, uri, n, e, en, diag)...)
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].Title < actions[j].Title
+	})
+	return actions, nil
+}
+
+// quickFixes returns the quick fixes for the supplied validation error, which
+// was surfaced at the supplied error node within the supplied node.
+func (s *Snapshot) quickFixes(ctx context.Context, uri span.URI, n workspace.Node, e *verror, en ast.Node, diag protocol.Diagnostic) []protocol.CodeAction {
+	var actions []protocol.CodeAction
+	switch {
+	case e.reason == validator.ReasonDeprecatedAPIVersion:
+		if a, ok := updateAPIVersionAction(uri, n, en); ok {
+			actions = append(actions, a)
+		}
+	case e.reason == validator.ReasonPackageNotFound, e.reason == validator.ReasonVersionNotFound:
+		actions = append(actions, protocol.CodeAction{
+			Title: titleResolveDeps,
+			Kind:  protocol.QuickFix,
+			Command: &protocol.Command{
+				Title:   titleResolveDeps,
+				Command: CommandResolveDependencies,
+			},
+		})
+	case e.reason == validator.ReasonDefinitionNotFound:
+		actions = append(actions, s.addDependencyActions(ctx, n, e)...)
+	case e.code == verrors.RequiredFailCode:
+		if a, ok := s.addRequiredFieldAction(uri, n, e, en); ok {
+			actions = append(actions, a)
+		}
+	}
+	for i := range actions {
+		actions[i].Diagnostics = []protocol.Diagnostic{diag}
+	}
+	return actions
+}
+
+// updateAPIVersionAction returns an action that replaces the deprecated
+// apiVersion of a meta file with the current apiVersion.
+func updateAPIVersionAction(uri span.URI, n workspace.Node, en ast.Node) (protocol.CodeAction, bool) {
+	var gv string
+	switch n.GetGVK().Kind {
+	case pkgmetav1.ConfigurationKind:
+		gv = pkgmetav1.ConfigurationGroupVersionKind.GroupVersion().String()
+	case pkgmetav1.ProviderKind:
+		gv = pkgmetav1.ProviderGroupVersionKind.GroupVersion().String()
+	default:
+		return protocol.CodeAction{}, false
+	}
+	return protocol.CodeAction{
+		Title:       fmt.Sprintf(titleAPIVersion, gv),
+		Kind:        protocol.QuickFix,
+		IsPreferred: true,
+		Edit: protocol.WorkspaceEdit{
+			Changes: map[string][]protocol.TextEdit{
+				string(protocol.URIFromSpanURI(uri)): {{
+					Range:   tokenRange(en.GetToken()),
+					NewText: gv,
+				}},
+			},
+		},
+	}, true
+}
+
+// addDependencyActions returns actions that add a dependency on each cached
+// package that defines the GVK that could not be found. Packages that are
+// already dependencies of the workspace are skipped.
+func (s *Snapshot) addDependencyActions(ctx context.Context, n workspace.Node, e *verror) []protocol.CodeAction { // nolint:gocyclo
+	p, ok := paved(n)
+	if !ok {
+		return nil
+	}
+	var segs fieldpath.Segments
+	if prefix := strings.TrimSuffix(strings.TrimSuffix(e.name, apiVersionField), "."); prefix != "" {
+		var err error
+		if segs, err = fieldpath.Parse(prefix); err != nil {
+			return nil
+		}
+	}
+	gk := gvkAt(p, segs).GroupKind()
+
+	uri, meta, ok := s.metaNode()
+	if !ok {
+		return nil
+	}
+	existing := map[string]struct{}{}
+	if deps, err := s.wsview.Meta().DependsOn(); err == nil {
+		for _, d := range deps {
+			existing[repository(d.Package)] = struct{}{}
+		}
+	}
+
+	pkgs, err := s.dm.Packages(ctx)
+	if err != nil {
+		return nil
+	}
+	// only offer the latest cached version of each package.
+	latest := map[string]*mxpkg.ParsedPackage{}
+	for _, pkg := range pkgs {
+		repo := repository(packageName(pkg))
+		if _, ok := existing[repo]; ok {
+			continue
+		}
+		if curr, ok := latest[repo]; ok && !newerVersion(pkg.Version(), curr.Version()) {
+			continue
+		}
+		for _, o := range pkg.Objects() {
+			if _, _, ok := declaresKind(o, gk); ok {
+				latest[repo] = pkg
+				break
+			}
+		}
+	}
+
+	actions := []protocol.CodeAction{}
+	for _, pkg := range latest {
+		edit, ok := dependencyEdit(meta, pkg)
+		if !ok {
+			continue
+		}
+		actions = append(actions, protocol.CodeAction{
+			Title: fmt.Sprintf(titleAddDepFmt, packageName(pkg), pkg.Version()),
+			Kind:  protocol.QuickFix,
+			Edit: protocol.WorkspaceEdit{
+				Changes: map[string][]protocol.TextEdit{
+					string(protocol.URIFromSpanURI(uri)): {edit},
+				},
+			},
+		})
+	}
+	if len(actions) == 1 {
+		actions[0].IsPreferred = true
+	}
+	return actions
+}
+
+// dependencyEdit returns an edit that adds a dependency on the supplied
+// package to the supplied meta node.
+func dependencyEdit(meta workspace.Node, pkg *mxpkg.ParsedPackage) (protocol.TextEdit, bool) {
+	typ := strings.ToLower(string(pkg.Type()))
+	if typ == "" {
+		typ = strings.ToLower(string(v1beta1.ProviderPackageType))
+	}
+	item := func(indent string) string {
+		return fmt.Sprintf(dependencyFmt, typ, packageName(pkg), indent, pkg.Version(), indent)
+	}
+
+	if dn, ok := nodeAtPath(meta.GetAST(), pathDependsOn); ok {
+		seq, ok := dn.(*ast.SequenceNode)
+		if !ok || seq.IsFlowStyle || seq.Start == nil {
+			return protocol.TextEdit{}, false
+		}
+		return protocol.TextEdit{
+			Range:   insertRange(seq.Start),
+			NewText: item(indentOf(seq.Start)),
+		}, true
+	}
+	sn, ok := nodeAtPath(meta.GetAST(), fieldSpec)
+	if !ok {
+		return protocol.TextEdit{}, false
+	}
+	tok, ok := firstKey(sn)
+	if !ok {
+		return protocol.TextEdit{}, false
+	}
+	indent := indentOf(tok)
+	return protocol.TextEdit{
+		Range:   insertRange(tok),
+		NewText: fmt.Sprintf(dependsOnFmt, indent, item(indent)),
+	}, true
+}
+
+// addRequiredFieldAction returns an action that inserts the missing required
+// field, using the default from its schema if one exists.
+func (s *Snapshot) addRequiredFieldAction(uri span.URI, n workspace.Node, e *verror, en ast.Node) (protocol.CodeAction, bool) {
+	segs, err := fieldpath.Parse(e.name)
+	if err != nil || len(segs) == 0 || segs[len(segs)-1].Type != fieldpath.SegmentField {
+		return protocol.CodeAction{}, false
+	}
+	tok, ok := firstKey(en)
+	if !ok {
+		return protocol.CodeAction{}, false
+	}
+	field := segs[len(segs)-1].Field
+
+	var fs *spec.Schema
+	if gvk, path, ok := resolvePath(n, segs); ok {
+		if def, ok := s.definitions[gvk]; ok {
+			fs = schemaForPath(def.Schema, path)
+		}
+	}
+
+	return protocol.CodeAction{
+		Title:       fmt.Sprintf(titleRequiredFmt, field),
+		Kind:        protocol.QuickFix,
+		IsPreferred: true,
+		Edit: protocol.WorkspaceEdit{
+			Changes: map[string][]protocol.TextEdit{
+				string(protocol.URIFromSpanURI(uri)): {{
+					Range:   insertRange(tok),
+					NewText: fmt.Sprintf(requiredFieldFmt, field, placeholder(fs), indentOf(tok)),
+				}},
+			},
+		},
+	}, true
+}
+
+// resolvePath determines the GVK and schema path that the supplied path within
+// the supplied node refers to. Paths within the resource bases of a
+// Composition refer to the base's GVK.
+func resolvePath(n workspace.Node, segs fieldpath.Segments) (schema.GroupVersionKind, fieldpath.Segments, bool) {
+	if n.GetGVK().GroupKind() != xpextv1.CompositionGroupVersionKind.GroupKind() {
+		return n.GetGVK(), segs, true
+	}
+	if len(segs) < 4 || !matchPath(segs[:4], "spec", "resources", "*", "base") {
+		return schema.GroupVersionKind{}, nil, false
+	}
+	p, ok := paved(n)
+	if !ok {
+		return schema.GroupVersionKind{}, nil, false
+	}
+	return gvkAt(p, segs[:4]), segs[4:], true
+}
+
+// metaNode returns the uri and node of the workspace's meta file.
+func (s *Snapshot) metaNode() (span.URI, workspace.Node, bool) {
+	if s.wsview.Meta() == nil || s.wsview.MetaLocation() == "" {
+		return "", nil, false
+	}
+	uri := span.URIFromPath(filepath.Join(s.wsview.MetaLocation(), xpkg.MetaFile))
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return "", nil, false
+	}
+	for id := range details.NodeIDs {
+		if n, ok := s.wsview.Nodes()[id]; ok {
+			return uri, n, true
+		}
+	}
+	return "", nil, false
+}
+
+// packageName returns the name that should be used to depend on the supplied
+// package. The registry is omitted for packages in the default registry.
+func packageName(pkg *mxpkg.ParsedPackage) string {
+	if pkg.Registry() == "" || pkg.Registry() == name.DefaultRegistry {
+		return pkg.Name()
+	}
+	return fmt.Sprintf("%s/%s", pkg.Registry(), pkg.Name())
+}
+
+// repository returns the fully qualified repository for the supplied package
+// name so that names with and without the default registry can be compared.
+func repository(pkg string) string {
+	r, err := name.NewRepository(pkg)
+	if err != nil {
+		return pkg
+	}
+	return r.Name()
+}
+
+// newerVersion returns true if version a is newer than version b.
+func newerVersion(a, b string) bool {
+	va, erra := semver.NewVersion(a)
+	vb, errb := semver.NewVersion(b)
+	if erra != nil || errb != nil {
+		return a > b
+	}
+	return va.GreaterThan(vb)
+}
+
+// placeholder returns a YAML value for a field with the supplied schema. The
+// schema's default is preferred, followed by its first enum value, and finally
+// the zero value for its type.
+func placeholder(fs *spec.Schema) string {
+	if fs == nil {
+		return placeholderString
+	}
+	var v any
+	switch {
+	case fs.Default != nil:
+		v = fs.Default
+	case len(fs.Enum) > 0:
+		v = fs.Enum[0]
+	case fs.Type.Contains("object"):
+		v = map[string]any{}
+	case fs.Type.Contains("array"):
+		v = []any{}
+	case fs.Type.Contains("integer"), fs.Type.Contains("number"):
+		v = 0
+	case fs.Type.Contains("boolean"):
+		v = false
+	default:
+		return placeholderString
+	}
+	// NOTE(hasheddan): JSON is valid YAML flow style.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return placeholderString
+	}
+	return string(b)
+}
+
+// firstKey returns the token of the first key of the supplied block style
+// mapping.
+func firstKey(n ast.Node) (*token.Token, bool) {
+	switch t := n.(type) {
+	case *ast.MappingNode:
+		if t.IsFlowStyle || len(t.Values) == 0 {
+			return nil, false
+		}
+		return t.Values[0].Key.GetToken(), true
+	case *ast.MappingValueNode:
+		return t.Key.GetToken(), true
+	}
+	return nil, false
+}
+
+// insertRange returns an empty range at the start of the supplied token.
+func insertRange(tok *token.Token) protocol.Range {
+	r := tokenRange(tok)
+	r.End = r.Start
+	return r
+}
+
+// indentOf returns the indentation preceding the supplied token.
+func indentOf(tok *token.Token) string {
+	return strings.Repeat(" ", tok.Position.Column-1)
+}
+
+// overlaps returns true if the supplied ranges overlap.
+func overlaps(a, b protocol.Range) bool {
+	return inRange(a, b.Start) || inRange(a, b.End) || inRange(b, a.Start)
+}
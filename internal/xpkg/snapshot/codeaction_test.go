@@ -0,0 +1,244 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+var (
+	testDeprecatedMeta = []byte(`apiVersion: meta.pkg.crossplane.io/v1alpha1
+kind: Configuration
+metadata:
+  name: getting-started
+spec:
+  crossplane:
+    version: ">=v1.0.0"
+  dependsOn:
+    - provider: crossplane/provider-gcp
+      version: ">=v0.1.0"
+`)
+
+	testMissingRequiredClaim = []byte(`apiVersion: acme.io/v1alpha1
+kind: Network
+metadata:
+  name: example
+spec:
+  compositionRef:
+    name: foo
+`)
+
+	testCertificate = []byte(`apiVersion: acm.aws.crossplane.io/v1alpha1
+kind: Certificate
+metadata:
+  name: example
+`)
+)
+
+func testRange(line, start, end uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: line, Character: start},
+		End:   protocol.Position{Line: line, Character: end},
+	}
+}
+
+func TestCodeActions(t *testing.T) {
+	crd := &extv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(testSingleVersionCRD, crd); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := newTestSnapshot(t, map[string][]byte{
+		"/ws/crossplane.yaml":     testDeprecatedMeta,
+		"/ws/xrd.yaml":            testXRD,
+		"/ws/examples/claim.yaml": testMissingRequiredClaim,
+		"/ws/examples/cert.yaml":  testCertificate,
+	}, WithDepManager(&MockDepManager{
+		packages: []*mxpkg.ParsedPackage{
+			{
+				DepName: "crossplane/provider-aws",
+				Reg:     "index.docker.io",
+				Ver:     "v0.19.0",
+				PType:   v1beta1.ProviderPackageType,
+				Objs:    []runtime.Object{crd},
+			},
+			{
+				DepName: "crossplane/provider-aws",
+				Reg:     "index.docker.io",
+				Ver:     "v0.20.0",
+				PType:   v1beta1.ProviderPackageType,
+				Objs:    []runtime.Object{crd},
+			},
+		},
+	}))
+
+	metaURI := string(protocol.URIFromSpanURI(span.URIFromPath("/ws/crossplane.yaml")))
+	deprecatedDiag := protocol.Diagnostic{
+		Range:    testRange(0, 12, 43),
+		Severity: protocol.SeverityWarning,
+		Code:     validator.ReasonDeprecatedAPIVersion,
+		Source:   serverName,
+		Message:  "meta.pkg.crossplane.io/v1alpha1 is deprecated in favor of meta.pkg.crossplane.io/v1",
+	}
+
+	type args struct {
+		file string
+		rng  protocol.Range
+	}
+	type want struct {
+		actions []protocol.CodeAction
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DeprecatedAPIVersion": {
+			reason: "A deprecated meta apiVersion should be replaced with the current apiVersion.",
+			args: args{
+				file: "/ws/crossplane.yaml",
+				rng:  testRange(0, 20, 20),
+			},
+			want: want{
+				actions: []protocol.CodeAction{{
+					Title:       "Update apiVersion to meta.pkg.crossplane.io/v1",
+					Kind:        protocol.QuickFix,
+					Diagnostics: []protocol.Diagnostic{deprecatedDiag},
+					IsPreferred: true,
+					Edit: protocol.WorkspaceEdit{
+						Changes: map[string][]protocol.TextEdit{
+							metaURI: {{Range: testRange(0, 12, 43), NewText: "meta.pkg.crossplane.io/v1"}},
+						},
+					},
+				}},
+			},
+		},
+		"PackageNotFound": {
+			reason: "A dependency missing from the cache should offer to resolve dependencies.",
+			args: args{
+				file: "/ws/crossplane.yaml",
+				rng:  testRange(8, 20, 20),
+			},
+			want: want{
+				actions: []protocol.CodeAction{{
+					Title: titleResolveDeps,
+					Kind:  protocol.QuickFix,
+					Diagnostics: []protocol.Diagnostic{{
+						Range:   testRange(8, 16, 39),
+						Code:    validator.ReasonPackageNotFound,
+						Source:  serverName,
+						Message: "Package crossplane/provider-gcp does not exist locally. Please run `up xpkg dep` to fix.",
+					}},
+					Command: &protocol.Command{
+						Title:   titleResolveDeps,
+						Command: CommandResolveDependencies,
+					},
+				}},
+			},
+		},
+		"RequiredField": {
+			reason: "A missing required field should be inserted with its default.",
+			args: args{
+				file: "/ws/examples/claim.yaml",
+				rng:  testRange(5, 16, 16),
+			},
+			want: want{
+				actions: []protocol.CodeAction{{
+					Title: "Add required field region",
+					Kind:  protocol.QuickFix,
+					Diagnostics: []protocol.Diagnostic{{
+						Range:    testRange(5, 16, 17),
+						Severity: protocol.SeverityError,
+						Source:   serverName,
+						Message:  "spec.region in body is required (acme.io/v1alpha1, Kind=Network)",
+					}},
+					IsPreferred: true,
+					Edit: protocol.WorkspaceEdit{
+						Changes: map[string][]protocol.TextEdit{
+							string(protocol.URIFromSpanURI(span.URIFromPath("/ws/examples/claim.yaml"))): {{
+								Range:   testRange(5, 2, 2),
+								NewText: "region: \"us-east-1\"\n  ",
+							}},
+						},
+					},
+				}},
+			},
+		},
+		"MissingDependency": {
+			reason: "A resource without a definition should offer to depend on the latest cached package that defines it.",
+			args: args{
+				file: "/ws/examples/cert.yaml",
+				rng:  testRange(0, 15, 15),
+			},
+			want: want{
+				actions: []protocol.CodeAction{{
+					Title: "Add dependency on crossplane/provider-aws@v0.20.0 to crossplane.yaml",
+					Kind:  protocol.QuickFix,
+					Diagnostics: []protocol.Diagnostic{{
+						Range:    testRange(0, 12, 42),
+						Severity: protocol.SeverityWarning,
+						Code:     validator.ReasonDefinitionNotFound,
+						Source:   serverName,
+						Message:  "no definition found for resource (acm.aws.crossplane.io/v1alpha1, Kind=Certificate)",
+					}},
+					IsPreferred: true,
+					Edit: protocol.WorkspaceEdit{
+						Changes: map[string][]protocol.TextEdit{
+							metaURI: {{
+								Range:   testRange(8, 4, 4),
+								NewText: "- provider: crossplane/provider-aws\n      version: \"v0.20.0\"\n    ",
+							}},
+						},
+					},
+				}},
+			},
+		},
+		"NoOverlap": {
+			reason: "No actions should be returned for ranges without diagnostics.",
+			args: args{
+				file: "/ws/examples/cert.yaml",
+				rng:  testRange(3, 2, 2),
+			},
+			want: want{
+				actions: []protocol.CodeAction{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actions, err := snap.CodeActions(context.Background(), span.URIFromPath(tc.args.file), tc.args.rng)
+			if diff := cmp.Diff(tc.want.err, err); diff != "" {
+				t.Errorf("\n%s\nCodeActions(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.actions, actions); diff != "" {
+				t.Errorf("\n%s\nCodeActions(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
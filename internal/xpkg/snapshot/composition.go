@@ -48,6 +48,7 @@ const (
 // CompositionValidator defines a validator for compositions.
 type CompositionValidator struct {
 	s          *Snapshot
+	checkers   []compositionChecker
 	validators []compositionValidator
 }
 
@@ -55,6 +56,13 @@ type CompositionValidator struct {
 func DefaultCompositionValidators(s *Snapshot) (validator.Validator, error) {
 	return &CompositionValidator{
 		s: s,
+		checkers: []compositionChecker{
+			NewPatchTypeChecker(s),
+			NewReadinessChecksValidator(s),
+			NewConnectionDetailsValidator(s),
+			NewResourceNamesValidator(),
+			NewPatchSetsValidator(),
+		},
 		validators: []compositionValidator{
 			NewPatchesValidator(s),
 		},
@@ -97,12 +105,24 @@ func (c *CompositionValidator) Validate(data any) *validate.Result {
 		}
 	}
 
+	// the Composition is checked regardless of whether it could be
+	// rendered.
+	for _, ch := range c.checkers {
+		errs = append(errs, ch.Check(comp)...)
+	}
+
 	return &validate.Result{
 		Errors: errs,
 	}
 }
 
 func (c *CompositionValidator) marshal(data any) (*xpextv1.Composition, error) {
+	return compositionFrom(data)
+}
+
+// compositionFrom converts the supplied unstructured Composition to a typed
+// Composition with defaults applied.
+func compositionFrom(data any) (*xpextv1.Composition, error) {
 	u, ok := data.(*unstructured.Unstructured)
 	if !ok {
 		return nil, errors.New(errInvalidType)
@@ -132,6 +152,12 @@ type compositionValidator interface {
 	validate(int, resource.Composed) []error
 }
 
+// compositionChecker checks a Composition as a whole, rather than each of the
+// resources it composes.
+type compositionChecker interface {
+	Check(*xpextv1.Composition) []error
+}
+
 // PatchesValidator validates the patches fields of a Composition.
 type PatchesValidator struct {
 	s *Snapshot
@@ -158,15 +184,25 @@ func (p *PatchesValidator) validate(idx int, cd resource.Composed) []error {
 		errs := []error{}
 		for _, e := range result.Errors {
 			var ve *verrors.Validation
-			if !errors.As(e, &ve) {
+			var xe *validator.Validation
+			switch {
+			case errors.As(e, &ve):
+				errs = append(errs, &validator.Validation{
+					TypeCode: ve.Code(),
+					Message:  fmt.Sprintf(errFmt, ve.Error(), cdgvk),
+					Name:     fmt.Sprintf(resourceBaseFmt, idx, ve.Name),
+				})
+			case errors.As(e, &xe):
+				// errors from rules of the composed resource's schema.
+				errs = append(errs, &validator.Validation{
+					TypeCode: xe.Code(),
+					Message:  fmt.Sprintf(errFmt, xe.Error(), cdgvk),
+					Name:     fmt.Sprintf(resourceBaseFmt, idx, xe.Name),
+					Reason:   xe.Reason,
+				})
+			default:
 				return []error{fmt.Errorf(errIncorrectErrType)}
 			}
-			ie := &validator.Validation{
-				TypeCode: ve.Code(),
-				Message:  fmt.Sprintf(errFmt, ve.Error(), cdgvk),
-				Name:     fmt.Sprintf(resourceBaseFmt, idx, ve.Name),
-			}
-			errs = append(errs, ie)
 		}
 		return errs
 	}
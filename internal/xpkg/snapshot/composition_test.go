@@ -82,6 +82,7 @@ func TestCompositionValidation(t *testing.T) {
 							TypeCode: validator.WarningTypeCode,
 							Message:  "no definition found for resource (database.aws.crossplane.io/v1beta1, Kind=RDSInstance)",
 							Name:     "spec.resources[0].base.apiVersion",
+							Reason:   validator.ReasonDefinitionNotFound,
 						},
 					},
 				},
@@ -368,6 +369,11 @@ func TestCompositionValidation(t *testing.T) {
 							Message:  "resource template names must be unique within their Composition",
 							Name:     "spec.resources",
 						},
+						&validator.Validation{
+							TypeCode: validator.FieldErrorTypeCode,
+							Message:  "resource name r1 is already used by spec.resources[0]",
+							Name:     "spec.resources[1].name",
+						},
 					},
 				},
 			},
@@ -56,6 +56,7 @@ func TestAPIVersionDeprecation(t *testing.T) {
 					TypeCode: validator.WarningTypeCode,
 					Message:  "meta.pkg.crossplane.io/v1alpha1 is deprecated in favor of meta.pkg.crossplane.io/v1",
 					Name:     "apiVersion",
+					Reason:   validator.ReasonDeprecatedAPIVersion,
 				},
 			},
 		},
@@ -74,6 +75,7 @@ func TestAPIVersionDeprecation(t *testing.T) {
 					TypeCode: validator.WarningTypeCode,
 					Message:  "meta.pkg.crossplane.io/v1alpha1 is deprecated in favor of meta.pkg.crossplane.io/v1",
 					Name:     "apiVersion",
+					Reason:   validator.ReasonDeprecatedAPIVersion,
 				},
 			},
 		},
@@ -0,0 +1,65 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+
+	"github.com/upbound/up/internal/xpkg/format"
+)
+
+// Format returns the edits required to format the file at the supplied uri.
+// If a range is supplied only the documents that intersect it are formatted.
+func (s *Snapshot) Format(uri span.URI, rng *protocol.Range) ([]protocol.TextEdit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+
+	edits := []protocol.TextEdit{}
+	for _, d := range format.Split(details.Body) {
+		if rng != nil && !intersects(d, *rng) {
+			continue
+		}
+		f, err := format.FormatDocument(d.Content)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(f, d.Content) {
+			continue
+		}
+		edits = append(edits, protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(d.StartLine)},
+				End:   protocol.Position{Line: uint32(d.EndLine)},
+			},
+			NewText: string(f),
+		})
+	}
+	return edits, nil
+}
+
+// intersects returns true if the supplied document overlaps the supplied
+// range.
+func intersects(d format.Document, rng protocol.Range) bool {
+	return int(rng.Start.Line) < d.EndLine && int(rng.End.Line) >= d.StartLine
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var testUnformatted = []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: formatted
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+    name: unformatted
+`)
+
+func TestFormat(t *testing.T) {
+	snap := newTestSnapshot(t, map[string][]byte{
+		"/ws/unformatted.yaml": testUnformatted,
+	})
+
+	formatted := protocol.TextEdit{
+		Range: testMultiLineRange(5, 0, 9, 0),
+		NewText: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unformatted
+`,
+	}
+
+	type args struct {
+		file string
+		rng  *protocol.Range
+	}
+	type want struct {
+		edits []protocol.TextEdit
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Document": {
+			reason: "Only documents that change should be edited.",
+			args: args{
+				file: "/ws/unformatted.yaml",
+			},
+			want: want{
+				edits: []protocol.TextEdit{formatted},
+			},
+		},
+		"RangeFormatted": {
+			reason: "A range covering only formatted documents should not be edited.",
+			args: args{
+				file: "/ws/unformatted.yaml",
+				rng:  testRangeRef(1, 0, 4),
+			},
+			want: want{
+				edits: []protocol.TextEdit{},
+			},
+		},
+		"RangeUnformatted": {
+			reason: "A range covering an unformatted document should edit it.",
+			args: args{
+				file: "/ws/unformatted.yaml",
+				rng:  testRangeRef(7, 0, 4),
+			},
+			want: want{
+				edits: []protocol.TextEdit{formatted},
+			},
+		},
+		"UnknownFile": {
+			reason: "Formatting a file outside the workspace should return an error.",
+			args: args{
+				file: "/ws/missing.yaml",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			edits, err := snap.Format(span.URIFromPath(tc.args.file), tc.args.rng)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nFormat(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.edits, edits); diff != "" {
+				t.Errorf("\n%s\nFormat(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
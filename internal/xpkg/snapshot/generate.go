@@ -0,0 +1,165 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/yaml"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/format"
+)
+
+const (
+	exampleName      = "example"
+	exampleNamespace = "default"
+
+	errXRDNotFoundFmt  = "no CompositeResourceDefinition named %s found in the workspace"
+	errNoClaimFmt      = "%s does not offer a claim"
+	errNoSchemaFmt     = "no schema found for %s"
+	errAmbiguousXRDFmt = "%s refers to more than one CompositeResourceDefinition: %s"
+	errGenerateFmt     = "cannot generate example of %s"
+)
+
+// GenerateClaim returns a skeleton claim of the type offered by the XRD in
+// the workspace with the supplied name. The XRD may be referred to by its
+// name, or by the kind of the composite resource or claim it defines.
+func (s *Snapshot) GenerateClaim(name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	xrd, err := s.workspaceXRD(name)
+	if err != nil {
+		return nil, err
+	}
+	if xrd.Spec.ClaimNames == nil {
+		return nil, fmt.Errorf(errNoClaimFmt, xrd.GetName())
+	}
+	return s.generate(xrd.GetClaimGroupVersionKind(), true)
+}
+
+// GenerateExample returns a skeleton composite resource of the type defined
+// by the XRD in the workspace with the supplied name. The XRD may be referred
+// to by its name, or by the kind of the composite resource or claim it
+// defines.
+func (s *Snapshot) GenerateExample(name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	xrd, err := s.workspaceXRD(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.generate(xrd.GetCompositeGroupVersionKind(), false)
+}
+
+// workspaceXRD returns the XRD defined in the workspace that is referred to by
+// the supplied name.
+func (s *Snapshot) workspaceXRD(name string) (*xpextv1.CompositeResourceDefinition, error) {
+	pkg := s.wsPackageName()
+	found := map[string]*xpextv1.CompositeResourceDefinition{}
+	for _, def := range s.definitions {
+		xrd, ok := def.Object.(*xpextv1.CompositeResourceDefinition)
+		if !ok || def.Package != pkg {
+			continue
+		}
+		if xrd.GetName() == name || strings.EqualFold(xrd.Spec.Names.Kind, name) ||
+			(xrd.Spec.ClaimNames != nil && strings.EqualFold(xrd.Spec.ClaimNames.Kind, name)) {
+			found[xrd.GetName()] = xrd
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf(errXRDNotFoundFmt, name)
+	case 1:
+		for _, xrd := range found {
+			return xrd, nil
+		}
+	}
+	names := make([]string, 0, len(found))
+	for n := range found {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf(errAmbiguousXRDFmt, name, strings.Join(names, ", "))
+}
+
+// generate returns a skeleton object of the supplied type, which is
+// namespaced if it is a claim.
+func (s *Snapshot) generate(gvk schema.GroupVersionKind, namespaced bool) ([]byte, error) {
+	def, ok := s.definitions[gvk]
+	if !ok || def.Schema == nil {
+		return nil, fmt.Errorf(errNoSchemaFmt, gvk)
+	}
+
+	meta := map[string]any{"name": exampleName}
+	if namespaced {
+		meta["namespace"] = exampleNamespace
+	}
+	obj := map[string]any{
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind,
+		"metadata":   meta,
+	}
+	if sp, ok := def.Schema.Properties[fieldSpec]; ok {
+		obj[fieldSpec] = skeleton(&sp)
+	}
+
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, errGenerateFmt, gvk)
+	}
+	return format.Format(b)
+}
+
+// skeleton returns a value for the supplied schema. As with placeholder, the
+// schema's default is preferred, followed by its first enum value, and
+// finally the zero value for its type. Objects include only their required
+// fields, and arrays a single item.
+func skeleton(s *spec.Schema) any {
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+	switch valueType(s) {
+	case typeObject:
+		obj := map[string]any{}
+		for _, f := range s.Required {
+			if fs, ok := s.Properties[f]; ok {
+				obj[f] = skeleton(&fs)
+			}
+		}
+		return obj
+	case typeArray:
+		if s.Items == nil || s.Items.Schema == nil {
+			return []any{}
+		}
+		return []any{skeleton(s.Items.Schema)}
+	case typeInteger, typeNumber:
+		return 0
+	case typeBoolean:
+		return false
+	}
+	return ""
+}
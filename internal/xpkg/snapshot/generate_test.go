@@ -0,0 +1,230 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var (
+	testGenerateXRD = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xclusters.acme.io
+spec:
+  group: acme.io
+  names:
+    kind: XCluster
+    plural: xclusters
+  claimNames:
+    kind: Cluster
+    plural: clusters
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - parameters
+            properties:
+              parameters:
+                type: object
+                required:
+                - region
+                - size
+                - nodes
+                - enabled
+                properties:
+                  region:
+                    type: string
+                    enum:
+                    - us-east-1
+                    - us-west-2
+                  size:
+                    type: integer
+                    default: 3
+                  nodes:
+                    type: array
+                    items:
+                      type: object
+                      required:
+                      - name
+                      properties:
+                        name:
+                          type: string
+                  enabled:
+                    type: boolean
+                  description:
+                    type: string
+`)
+
+	testGeneratedClaim = `apiVersion: acme.io/v1alpha1
+kind: Cluster
+metadata:
+  name: example
+  namespace: default
+spec:
+  parameters:
+    enabled: false
+    nodes:
+    - name: ""
+    region: us-east-1
+    size: 3
+`
+
+	testGeneratedXR = `apiVersion: acme.io/v1alpha1
+kind: XCluster
+metadata:
+  name: example
+spec:
+  parameters:
+    enabled: false
+    nodes:
+    - name: ""
+    region: us-east-1
+    size: 3
+`
+)
+
+// testGenerateFiles returns the files of the workspace that claims and
+// examples are generated from.
+func testGenerateFiles() map[string][]byte {
+	return map[string][]byte{
+		"/ws/cluster.yaml":  testGenerateXRD,
+		"/ws/database.yaml": testDatabaseXRD,
+	}
+}
+
+func TestGenerateClaim(t *testing.T) {
+	snap := newTestSnapshot(t, testGenerateFiles())
+
+	type args struct {
+		xrd string
+	}
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"XRDName": {
+			reason: "A claim with the required fields of its schema should be generated for an XRD referred to by name.",
+			args: args{
+				xrd: "xclusters.acme.io",
+			},
+			want: want{
+				out: testGeneratedClaim,
+			},
+		},
+		"ClaimKind": {
+			reason: "An XRD may be referred to by the kind of its claim.",
+			args: args{
+				xrd: "cluster",
+			},
+			want: want{
+				out: testGeneratedClaim,
+			},
+		},
+		"NoClaim": {
+			reason: "An error should be returned if the XRD does not offer a claim.",
+			args: args{
+				xrd: "XDatabase",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"NotFound": {
+			reason: "An error should be returned if no XRD in the workspace matches.",
+			args: args{
+				xrd: "xbuckets.acme.io",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := snap.GenerateClaim(tc.args.xrd)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGenerateClaim(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, string(out)); diff != "" {
+				t.Errorf("\n%s\nGenerateClaim(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGenerateExample(t *testing.T) {
+	snap := newTestSnapshot(t, testGenerateFiles())
+
+	type args struct {
+		xrd string
+	}
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CompositeKind": {
+			reason: "A composite resource with the required fields of its schema should be generated for an XRD referred to by kind.",
+			args: args{
+				xrd: "XCluster",
+			},
+			want: want{
+				out: testGeneratedXR,
+			},
+		},
+		"NotFound": {
+			reason: "An error should be returned if no XRD in the workspace matches.",
+			args: args{
+				xrd: "XBucket",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := snap.GenerateExample(tc.args.xrd)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGenerateExample(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, string(out)); diff != "" {
+				t.Errorf("\n%s\nGenerateExample(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,524 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const (
+	goGeneratedHeader = "// Code generated by up xpkg generate types. DO NOT EDIT.\n\n"
+
+	goGroupVersionFile = "groupversion_info.go"
+	goTypesFileFmt     = "%s_types.go"
+	goDeepCopyFile     = "zz_generated.deepcopy.go"
+
+	goImportMeta    = `metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"`
+	goImportRuntime = `"k8s.io/apimachinery/pkg/runtime"`
+	goImportSchema  = `"k8s.io/apimachinery/pkg/runtime/schema"`
+	goImportIntStr  = `"k8s.io/apimachinery/pkg/util/intstr"`
+
+	fieldStatus = "status"
+
+	goRawExtension = "runtime.RawExtension"
+	goIntOrString  = "intstr.IntOrString"
+
+	errFormatGoFmt = "cannot format generated Go source %s"
+)
+
+// goKind is the kind of a Go type.
+type goKind int
+
+const (
+	goScalar goKind = iota
+	goStruct
+	goPointer
+	goSlice
+	goMap
+)
+
+// goType is a Go type used by a generated struct.
+type goType struct {
+	kind goKind
+	// name is the name of scalar and struct types.
+	name string
+	// elem is the element type of pointer, slice and map types.
+	elem *goType
+}
+
+func (t *goType) String() string {
+	switch t.kind {
+	case goPointer:
+		return "*" + t.elem.String()
+	case goSlice:
+		return "[]" + t.elem.String()
+	case goMap:
+		return "map[string]" + t.elem.String()
+	}
+	return t.name
+}
+
+// goInitialisms are the initialisms that are capitalized in Go names.
+var goInitialisms = map[string]bool{
+	"API":  true,
+	"CIDR": true,
+	"CPU":  true,
+	"DNS":  true,
+	"HTTP": true,
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"TLS":  true,
+	"UID":  true,
+	"URI":  true,
+	"URL":  true,
+}
+
+// goTypeMeta is the embedded type metadata of objects and lists. It is copied
+// by value.
+var goTypeMeta = goField{name: "TypeMeta", tag: `json:",inline"`, typ: &goType{kind: goScalar, name: "metav1.TypeMeta"}, embedded: true}
+
+// goField is a field of a generated struct.
+type goField struct {
+	name     string
+	tag      string
+	doc      string
+	typ      *goType
+	embedded bool
+}
+
+// goStructDef is a generated struct.
+type goStructDef struct {
+	name   string
+	doc    string
+	fields []goField
+}
+
+// goFile accumulates the structs of a generated Go file.
+type goFile struct {
+	structs []*goStructDef
+	names   map[string]bool
+}
+
+// goTypes returns a Go package for each group version of the supplied type
+// versions, containing structs with deepcopy methods for each type and a
+// scheme builder that registers them.
+func goTypes(tvs []typeVersion) ([]GeneratedFile, error) {
+	byGV := map[string][]typeVersion{}
+	gvs := []string{}
+	for _, tv := range tvs {
+		gv := filepath.Join(tv.group, tv.version)
+		if _, ok := byGV[gv]; !ok {
+			gvs = append(gvs, gv)
+		}
+		byGV[gv] = append(byGV[gv], tv)
+	}
+
+	files := []GeneratedFile{}
+	for _, gv := range gvs {
+		pkg := byGV[gv]
+		names := map[string]bool{}
+		var deepcopy bytes.Buffer
+		for _, tv := range pkg {
+			f := &goFile{names: names}
+			f.object(tv)
+			path := filepath.Join(gv, fmt.Sprintf(goTypesFileFmt, strings.ToLower(tv.kind)))
+			src := f.typesSource()
+			b, err := goSource(path, "", tv.version, src, goImports(src))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, GeneratedFile{Path: path, Content: b})
+			deepcopy.WriteString(f.deepCopySource())
+		}
+
+		path := filepath.Join(gv, goDeepCopyFile)
+		b, err := goSource(path, "", pkg[0].version, deepcopy.String(), goImports(deepcopy.String()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, GeneratedFile{Path: path, Content: b})
+
+		path = filepath.Join(gv, goGroupVersionFile)
+		doc := fmt.Sprintf("Package %s contains the %s types of the %s API group.", pkg[0].version, pkg[0].version, pkg[0].group)
+		src := groupVersionSource(pkg)
+		b, err = goSource(path, doc, pkg[0].version, src, goImports(src))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, GeneratedFile{Path: path, Content: b})
+	}
+	return files, nil
+}
+
+// goSource returns the formatted source of a Go file in the package with the
+// supplied name, documented by the supplied package doc if it is not empty.
+func goSource(path, doc, pkg, body string, imports []string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(goGeneratedHeader)
+	writeComment(&b, "", doc)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if len(imports) > 0 {
+		b.WriteString("import (\n")
+		for _, i := range imports {
+			fmt.Fprintf(&b, "\t%s\n", i)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.WriteString(body)
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, errFormatGoFmt, path)
+	}
+	return out, nil
+}
+
+// groupVersionSource returns the scheme builder of the Go package for the
+// supplied type versions, which share a group version.
+func groupVersionSource(tvs []typeVersion) string {
+	var b bytes.Buffer
+	tv := tvs[0]
+	b.WriteString("var (\n")
+	b.WriteString("\t// SchemeGroupVersion is the group version of the types in this package.\n")
+	fmt.Fprintf(&b, "\tSchemeGroupVersion = schema.GroupVersion{Group: %q, Version: %q}\n\n", tv.group, tv.version)
+	b.WriteString("\t// SchemeBuilder registers the types in this package with a scheme.\n")
+	b.WriteString("\tSchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)\n\n")
+	b.WriteString("\t// AddToScheme adds the types in this package to a scheme.\n")
+	b.WriteString("\tAddToScheme = SchemeBuilder.AddToScheme\n")
+	b.WriteString(")\n\n")
+	b.WriteString("func addKnownTypes(s *runtime.Scheme) error {\n")
+	b.WriteString("\ts.AddKnownTypes(SchemeGroupVersion,\n")
+	for _, tv := range tvs {
+		fmt.Fprintf(&b, "\t\t&%s{},\n\t\t&%s{},\n", tv.kind, tv.listKind)
+	}
+	b.WriteString("\t)\n")
+	b.WriteString("\tmetav1.AddToGroupVersion(s, SchemeGroupVersion)\n")
+	b.WriteString("\treturn nil\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// object adds the structs of the supplied type version to the file.
+func (f *goFile) object(tv typeVersion) {
+	scope := "Cluster"
+	if tv.namespaced {
+		scope = "Namespaced"
+	}
+	obj := &goStructDef{
+		name:   tv.kind,
+		doc:    fmt.Sprintf("%s is a %s scoped %s/%s resource.", tv.kind, strings.ToLower(scope), tv.group, tv.version),
+		fields: []goField{goTypeMeta, {name: "ObjectMeta", tag: `json:"metadata,omitempty"`, typ: &goType{kind: goStruct, name: "metav1.ObjectMeta"}, embedded: true}},
+	}
+	f.add(obj)
+	for _, p := range []string{fieldSpec, fieldStatus} {
+		ps, ok := tv.schema.Properties[p]
+		if !ok {
+			continue
+		}
+		fd := f.field(tv.kind, p, ps, contains(tv.schema.Required, p))
+		// the spec and status of objects are not pointers, by convention.
+		if fd.typ.kind == goPointer {
+			fd.typ = fd.typ.elem
+		}
+		obj.fields = append(obj.fields, fd)
+	}
+	f.add(&goStructDef{
+		name: tv.listKind,
+		doc:  fmt.Sprintf("%s is a list of %s.", tv.listKind, tv.kind),
+		fields: []goField{
+			goTypeMeta,
+			{name: "ListMeta", tag: `json:"metadata,omitempty"`, typ: &goType{kind: goStruct, name: "metav1.ListMeta"}, embedded: true},
+			{name: "Items", tag: `json:"items"`, typ: &goType{kind: goSlice, elem: &goType{kind: goStruct, name: tv.kind}}},
+		},
+	})
+}
+
+// add adds the supplied struct to the file.
+func (f *goFile) add(s *goStructDef) {
+	f.names[s.name] = true
+	f.structs = append(f.structs, s)
+}
+
+// structName returns an unused struct name based on the supplied name.
+func (f *goFile) structName(name string) string {
+	n := name
+	for i := 2; f.names[n]; i++ {
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+	f.names[n] = true
+	return n
+}
+
+// field returns the struct field for the supplied property of the struct
+// with the supplied name. Optional fields are omitted when empty.
+func (f *goFile) field(parent, prop string, s extv1.JSONSchemaProps, required bool) goField {
+	name := goName(prop)
+	t := f.typeFor(parent+name, fmt.Sprintf("the %s field of %s", prop, parent), s)
+	tag := fmt.Sprintf(`json:"%s"`, prop)
+	if !required {
+		tag = fmt.Sprintf(`json:"%s,omitempty"`, prop)
+		if t.kind == goScalar || t.kind == goStruct {
+			t = &goType{kind: goPointer, elem: t}
+		}
+	}
+	return goField{
+		name: name,
+		tag:  tag,
+		doc:  s.Description,
+		typ:  t,
+	}
+}
+
+// typeFor returns the Go type for the supplied schema, which describes the
+// supplied subject. Structs are generated for objects with properties, named
+// after the supplied name.
+func (f *goFile) typeFor(name, subject string, s extv1.JSONSchemaProps) *goType { // nolint:gocyclo
+	if s.XIntOrString {
+		return &goType{kind: goScalar, name: goIntOrString}
+	}
+	switch s.Type {
+	case typeString:
+		return &goType{kind: goScalar, name: "string"}
+	case typeInteger:
+		return &goType{kind: goScalar, name: "int64"}
+	case typeNumber:
+		return &goType{kind: goScalar, name: "float64"}
+	case typeBoolean:
+		return &goType{kind: goScalar, name: "bool"}
+	case typeArray:
+		if s.Items == nil || s.Items.Schema == nil {
+			return &goType{kind: goSlice, elem: &goType{kind: goStruct, name: goRawExtension}}
+		}
+		return &goType{kind: goSlice, elem: f.typeFor(name, "the items of "+subject, *s.Items.Schema)}
+	case typeObject:
+		if len(s.Properties) > 0 {
+			sn := f.structName(name)
+			def := &goStructDef{name: sn, doc: fmt.Sprintf("%s is the type of %s.", sn, subject)}
+			// reserve the position of the struct before its fields add
+			// their own structs.
+			f.structs = append(f.structs, def)
+			props := make([]string, 0, len(s.Properties))
+			for p := range s.Properties {
+				props = append(props, p)
+			}
+			sort.Strings(props)
+			for _, p := range props {
+				def.fields = append(def.fields, f.field(sn, p, s.Properties[p], contains(s.Required, p)))
+			}
+			return &goType{kind: goStruct, name: sn}
+		}
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			return &goType{kind: goMap, elem: f.typeFor(name, "the values of "+subject, *s.AdditionalProperties.Schema)}
+		}
+	}
+	return &goType{kind: goStruct, name: goRawExtension}
+}
+
+// goImports returns the imports used by the supplied source.
+func goImports(src string) []string {
+	imports := []string{}
+	for _, i := range []struct{ pkg, path string }{
+		{"metav1.", goImportMeta},
+		{"runtime.", goImportRuntime},
+		{"schema.", goImportSchema},
+		{"intstr.", goImportIntStr},
+	} {
+		if strings.Contains(src, i.pkg) {
+			imports = append(imports, i.path)
+		}
+	}
+	return imports
+}
+
+// typesSource returns the source of the structs of the file.
+func (f *goFile) typesSource() string {
+	var b bytes.Buffer
+	for _, s := range f.structs {
+		writeComment(&b, "", s.doc)
+		fmt.Fprintf(&b, "type %s struct {\n", s.name)
+		for _, fd := range s.fields {
+			writeComment(&b, "\t", fd.doc)
+			if fd.embedded {
+				fmt.Fprintf(&b, "\t%s `%s`\n", fd.typ, fd.tag)
+				continue
+			}
+			fmt.Fprintf(&b, "\t%s %s `%s`\n", fd.name, fd.typ, fd.tag)
+		}
+		b.WriteString("}\n\n")
+	}
+	return b.String()
+}
+
+// deepCopySource returns the source of the deepcopy methods of the structs of
+// the file.
+func (f *goFile) deepCopySource() string {
+	objects := map[string]bool{}
+	for _, s := range f.structs {
+		for _, fd := range s.fields {
+			if fd == goTypeMeta {
+				objects[s.name] = true
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	for _, s := range f.structs {
+		fmt.Fprintf(&b, "// DeepCopyInto copies the receiver into out. in must be non-nil.\n")
+		fmt.Fprintf(&b, "func (in *%s) DeepCopyInto(out *%s) {\n", s.name, s.name)
+		b.WriteString("\t*out = *in\n")
+		for _, fd := range s.fields {
+			if fd.typ.kind == goScalar {
+				continue
+			}
+			fmt.Fprintf(&b, "\t{\n\t\tin, out := &in.%s, &out.%s\n", fd.name, fd.name)
+			writeDeepCopy(&b, fd.typ, "\t\t")
+			b.WriteString("\t}\n")
+		}
+		b.WriteString("}\n\n")
+
+		fmt.Fprintf(&b, "// DeepCopy returns a deep copy of the receiver.\n")
+		fmt.Fprintf(&b, "func (in *%s) DeepCopy() *%s {\n", s.name, s.name)
+		b.WriteString("\tif in == nil {\n\t\treturn nil\n\t}\n")
+		fmt.Fprintf(&b, "\tout := new(%s)\n", s.name)
+		b.WriteString("\tin.DeepCopyInto(out)\n")
+		b.WriteString("\treturn out\n")
+		b.WriteString("}\n\n")
+
+		if !objects[s.name] {
+			continue
+		}
+		fmt.Fprintf(&b, "// DeepCopyObject returns a deep copy of the receiver as a runtime.Object.\n")
+		fmt.Fprintf(&b, "func (in *%s) DeepCopyObject() runtime.Object {\n", s.name)
+		b.WriteString("\tif c := in.DeepCopy(); c != nil {\n\t\treturn c\n\t}\n")
+		b.WriteString("\treturn nil\n")
+		b.WriteString("}\n\n")
+	}
+	return b.String()
+}
+
+// writeDeepCopy writes statements that set *out to a deep copy of *in, where
+// in and out are pointers to values of the supplied type.
+func writeDeepCopy(b *bytes.Buffer, t *goType, indent string) {
+	switch t.kind {
+	case goScalar:
+		fmt.Fprintf(b, "%s*out = *in\n", indent)
+	case goStruct:
+		fmt.Fprintf(b, "%sin.DeepCopyInto(out)\n", indent)
+	case goPointer:
+		fmt.Fprintf(b, "%sif *in != nil {\n", indent)
+		fmt.Fprintf(b, "%s\t*out = new(%s)\n", indent, t.elem)
+		fmt.Fprintf(b, "%s\tin, out := *in, *out\n", indent)
+		writeDeepCopy(b, t.elem, indent+"\t")
+		fmt.Fprintf(b, "%s}\n", indent)
+	case goSlice:
+		fmt.Fprintf(b, "%sif *in != nil {\n", indent)
+		fmt.Fprintf(b, "%s\t*out = make(%s, len(*in))\n", indent, t)
+		if t.elem.kind == goScalar {
+			fmt.Fprintf(b, "%s\tcopy(*out, *in)\n", indent)
+		} else {
+			fmt.Fprintf(b, "%s\tfor i := range *in {\n", indent)
+			fmt.Fprintf(b, "%s\t\tin, out := &(*in)[i], &(*out)[i]\n", indent)
+			writeDeepCopy(b, t.elem, indent+"\t\t")
+			fmt.Fprintf(b, "%s\t}\n", indent)
+		}
+		fmt.Fprintf(b, "%s}\n", indent)
+	case goMap:
+		fmt.Fprintf(b, "%sif *in != nil {\n", indent)
+		fmt.Fprintf(b, "%s\tm := make(%s, len(*in))\n", indent, t)
+		fmt.Fprintf(b, "%s\tfor key, val := range *in {\n", indent)
+		fmt.Fprintf(b, "%s\t\tvar c %s\n", indent, t.elem)
+		fmt.Fprintf(b, "%s\t\t{\n", indent)
+		fmt.Fprintf(b, "%s\t\t\tin, out := &val, &c\n", indent)
+		writeDeepCopy(b, t.elem, indent+"\t\t\t")
+		fmt.Fprintf(b, "%s\t\t}\n", indent)
+		fmt.Fprintf(b, "%s\t\tm[key] = c\n", indent)
+		fmt.Fprintf(b, "%s\t}\n", indent)
+		fmt.Fprintf(b, "%s\t*out = m\n", indent)
+		fmt.Fprintf(b, "%s}\n", indent)
+	}
+}
+
+// writeComment writes the supplied text as a Go comment.
+func writeComment(b *bytes.Buffer, indent, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, l := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimRightFunc(l, unicode.IsSpace))
+	}
+}
+
+// goName returns the exported Go name for the supplied JSON field name.
+// Common initialisms are capitalized as Go prefers.
+func goName(field string) string {
+	var b strings.Builder
+	for _, w := range words(field) {
+		if goInitialisms[strings.ToUpper(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	n := b.String()
+	if n == "" || unicode.IsDigit(rune(n[0])) {
+		n = "X" + n
+	}
+	return n
+}
+
+// words splits the supplied camel case name into words, dropping any
+// characters that are not letters or digits.
+func words(name string) []string {
+	ws := []string{}
+	var w []rune
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(w) > 0 {
+				ws = append(ws, string(w))
+			}
+			w = nil
+			continue
+		case unicode.IsUpper(r) && len(w) > 0 && !unicode.IsUpper(w[len(w)-1]):
+			ws = append(ws, string(w))
+			w = nil
+		}
+		w = append(w, r)
+	}
+	if len(w) > 0 {
+		ws = append(ws, string(w))
+	}
+	return ws
+}
+
+// contains returns true if the supplied slice contains the supplied string.
+func contains(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,337 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	hintTypeFmt = ": %s"
+
+	fieldMetadata = "metadata"
+
+	extPreserveUnknownFields = "x-kubernetes-preserve-unknown-fields"
+
+	tokenTypeProperty     = "property"
+	tokenModifierInvalid  = "invalid"
+	tokenModifierResolved = "resolved"
+)
+
+// InlayHintKind is the kind of an inlay hint.
+type InlayHintKind int
+
+const (
+	// InlayHintKindType is an inlay hint for a type annotation.
+	InlayHintKindType InlayHintKind = 1
+	// InlayHintKindParameter is an inlay hint for a parameter.
+	InlayHintKindParameter InlayHintKind = 2
+)
+
+// An InlayHint is an annotation rendered inline with the content of a file.
+// Inlay hints were introduced in version 3.17 of the protocol and are not
+// modelled by the protocol package.
+type InlayHint struct {
+	Position     protocol.Position `json:"position"`
+	Label        string            `json:"label"`
+	Kind         InlayHintKind     `json:"kind,omitempty"`
+	PaddingLeft  bool              `json:"paddingLeft,omitempty"`
+	PaddingRight bool              `json:"paddingRight,omitempty"`
+}
+
+// InlayHintParams are the parameters of an inlay hint request.
+type InlayHintParams struct {
+	TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	Range        protocol.Range                  `json:"range"`
+}
+
+var (
+	// SemanticTokenTypes are the types of the semantic tokens returned by
+	// SemanticTokens, in the order they are encoded.
+	SemanticTokenTypes = []string{tokenTypeProperty}
+	// SemanticTokenModifiers are the modifiers of the semantic tokens
+	// returned by SemanticTokens, in the order they are encoded.
+	SemanticTokenModifiers = []string{tokenModifierInvalid, tokenModifierResolved}
+)
+
+// A pathRef is a field path in a Composition that refers to a field of a
+// composite or composed resource.
+type pathRef struct {
+	// node is the scalar containing the field path.
+	node ast.Node
+	// known indicates whether the schema of the referenced resource is known.
+	known bool
+	// valid indicates whether the field path exists in the schema of the
+	// referenced resource. Field paths are always valid if the schema is
+	// unknown.
+	valid bool
+	// schema is the schema of the referenced field, if it could be
+	// determined.
+	schema *spec.Schema
+}
+
+// InlayHints returns the inlay hints for the file at the supplied uri that are
+// within the supplied range. The resolved type of each patch field path and
+// the GVK of each composed resource are hinted.
+func (s *Snapshot) InlayHints(uri span.URI, rng protocol.Range) ([]InlayHint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nodes, err := s.compositions(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	hints := []InlayHint{}
+	add := func(n ast.Node, label string) {
+		pos := tokenRange(n.GetToken()).End
+		if !inRange(rng, pos) {
+			return
+		}
+		hints = append(hints, InlayHint{
+			Position:    pos,
+			Label:       label,
+			Kind:        InlayHintKindType,
+			PaddingLeft: true,
+		})
+	}
+	for _, n := range nodes {
+		for _, ref := range s.pathRefs(n) {
+			if ref.schema != nil {
+				add(ref.node, fmt.Sprintf(hintTypeFmt, schemaType(ref.schema)))
+			}
+		}
+		p, ok := paved(n)
+		if !ok {
+			continue
+		}
+		for i := 0; i < lenAt(p, pathResources); i++ {
+			res := fmt.Sprintf(indexFmt, pathResources, i)
+			base, err := fieldpath.Parse(fmt.Sprintf(fieldFmt, res, "base"))
+			if err != nil {
+				continue
+			}
+			gvk := gvkAt(p, base)
+			if gvk.Kind == "" {
+				continue
+			}
+			// hint beside the name of the resource, falling back to the
+			// kind of its base.
+			at, ok := nodeAtPath(n.GetAST(), fmt.Sprintf(fieldFmt, res, fieldName))
+			if !ok {
+				if at, ok = nodeAtPath(n.GetAST(), fmt.Sprintf(fieldFmt, res, fieldBaseKind)); !ok {
+					continue
+				}
+			}
+			add(at, fmt.Sprintf(hintTypeFmt, fmt.Sprintf(detailFmt, gvk.Kind, gvk.GroupVersion())))
+		}
+	}
+	sort.SliceStable(hints, func(i, j int) bool {
+		return before(hints[i].Position, hints[j].Position)
+	})
+	return hints, nil
+}
+
+// SemanticTokens returns the semantic tokens for the patch field paths in the
+// file at the supplied uri. If a range is supplied only tokens within it are
+// returned. Field paths that do not exist in the schema of the resource they
+// refer to are marked invalid, while those that do are marked resolved.
+func (s *Snapshot) SemanticTokens(uri span.URI, rng *protocol.Range) (*protocol.SemanticTokens, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nodes, err := s.compositions(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	type semToken struct {
+		rng       protocol.Range
+		modifiers uint32
+	}
+	toks := []semToken{}
+	for _, n := range nodes {
+		for _, ref := range s.pathRefs(n) {
+			r := tokenRange(ref.node.GetToken())
+			if rng != nil && !inRange(*rng, r.Start) {
+				continue
+			}
+			var mods uint32
+			switch {
+			case !ref.valid:
+				mods |= 1 << modifierIndex(tokenModifierInvalid)
+			case ref.known:
+				mods |= 1 << modifierIndex(tokenModifierResolved)
+			}
+			toks = append(toks, semToken{rng: r, modifiers: mods})
+		}
+	}
+	sort.SliceStable(toks, func(i, j int) bool {
+		return before(toks[i].rng.Start, toks[j].rng.Start)
+	})
+
+	// tokens are encoded relative to the previous token, as described in the
+	// specification.
+	data := make([]uint32, 0, len(toks)*5)
+	var line, char uint32
+	for _, t := range toks {
+		dl := t.rng.Start.Line - line
+		dc := t.rng.Start.Character
+		if dl == 0 {
+			dc -= char
+		}
+		data = append(data, dl, dc, t.rng.End.Character-t.rng.Start.Character, 0, t.modifiers)
+		line, char = t.rng.Start.Line, t.rng.Start.Character
+	}
+	return &protocol.SemanticTokens{Data: data}, nil
+}
+
+// modifierIndex returns the index of the supplied modifier in the semantic
+// token legend.
+func modifierIndex(m string) int {
+	for i, mod := range SemanticTokenModifiers {
+		if mod == m {
+			return i
+		}
+	}
+	return 0
+}
+
+// compositions returns the Compositions in the file at the supplied uri.
+func (s *Snapshot) compositions(uri span.URI) ([]workspace.Node, error) {
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+	nodes := []workspace.Node{}
+	for id := range details.NodeIDs {
+		n, ok := s.wsview.Nodes()[id]
+		if !ok {
+			return nil, errors.New(errInvalidNodeID)
+		}
+		if n.GetAST() == nil || n.GetGVK().GroupKind() != xpextv1.CompositionGroupVersionKind.GroupKind() {
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// pathRefs returns the field paths within the supplied Composition that refer
+// to fields of the composite or composed resources.
+func (s *Snapshot) pathRefs(n workspace.Node) []pathRef {
+	refs := []pathRef{}
+	ast.Walk(nodeVisitor(func(an ast.Node) {
+		mv, ok := an.(*ast.MappingValueNode)
+		if !ok {
+			return
+		}
+		switch mv.Key.GetToken().Value {
+		case fieldFromFieldPath, fieldToFieldPath, fieldFieldPath:
+		default:
+			return
+		}
+		if _, ok := mv.Value.(*ast.StringNode); !ok {
+			return
+		}
+		gvk, path, ok := resolveField(n, mv.Value, false)
+		if !ok {
+			return
+		}
+		refs = append(refs, s.pathRef(mv.Value, gvk, path))
+	}), n.GetAST())
+	return refs
+}
+
+// pathRef resolves the supplied field path of the supplied GVK.
+func (s *Snapshot) pathRef(n ast.Node, gvk schema.GroupVersionKind, path fieldpath.Segments) pathRef {
+	def, ok := s.definitions[gvk]
+	if !ok || def.Schema == nil {
+		return pathRef{node: n, valid: true}
+	}
+	fs, valid := resolveSchema(def.Schema, path)
+	return pathRef{node: n, known: true, valid: valid, schema: fs}
+}
+
+// resolveSchema walks the supplied schema, returning the sub-schema found at
+// the supplied path and whether the path is valid. Paths into object metadata
+// and fields that preserve unknown fields are always valid, though their
+// schema may not be known.
+func resolveSchema(s *spec.Schema, path fieldpath.Segments) (*spec.Schema, bool) { // nolint:gocyclo
+	if len(path) > 0 && path[0].Type == fieldpath.SegmentField && path[0].Field == fieldMetadata {
+		return schemaForPath(s, path), true
+	}
+	curr := s
+	for _, seg := range path {
+		if open(curr) {
+			return nil, true
+		}
+		switch seg.Type {
+		case fieldpath.SegmentIndex:
+			if curr.Items == nil || curr.Items.Schema == nil {
+				return nil, false
+			}
+			curr = curr.Items.Schema
+		case fieldpath.SegmentField:
+			if prop, ok := curr.Properties[seg.Field]; ok {
+				curr = &prop
+				continue
+			}
+			if curr.AdditionalProperties != nil && curr.AdditionalProperties.Schema != nil {
+				curr = curr.AdditionalProperties.Schema
+				continue
+			}
+			return nil, false
+		}
+	}
+	return curr, true
+}
+
+// open returns true if the supplied schema accepts fields it does not
+// declare.
+func open(s *spec.Schema) bool {
+	if preserve, ok := s.Extensions.GetBool(extPreserveUnknownFields); ok && preserve {
+		return true
+	}
+	if len(s.Properties) > 0 || s.AdditionalProperties != nil || s.Items != nil {
+		return false
+	}
+	return len(s.Type) == 0 || s.Type.Contains("object")
+}
+
+// nodeVisitor is an ast.Visitor that calls a function for each node it
+// visits.
+type nodeVisitor func(ast.Node)
+
+// Visit implements ast.Visitor.
+func (v nodeVisitor) Visit(n ast.Node) ast.Visitor {
+	if n != nil {
+		v(n)
+	}
+	return v
+}
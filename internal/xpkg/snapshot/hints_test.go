@@ -0,0 +1,194 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+)
+
+var testHintsComp = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xnetworks.acme.io
+spec:
+  compositeTypeRef:
+    apiVersion: acme.io/v1alpha1
+    kind: XNetwork
+  resources:
+  - name: cert
+    base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    - fromFieldPath: spec.regoin
+      toFieldPath: metadata.labels[region]
+  - base:
+      apiVersion: example.org/v1
+      kind: Unknown
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.anything
+`)
+
+// testHintsFiles returns the files of the workspace that hints and patch
+// types are tested against.
+func testHintsFiles() map[string][]byte {
+	return map[string][]byte{
+		"/ws/xrd.yaml":         testXRD,
+		"/ws/crd.yaml":         testSingleVersionCRD,
+		"/ws/composition.yaml": testHintsComp,
+	}
+}
+
+func testHint(line, char uint32, label string) InlayHint {
+	return InlayHint{
+		Position:    protocol.Position{Line: line, Character: char},
+		Label:       label,
+		Kind:        InlayHintKindType,
+		PaddingLeft: true,
+	}
+}
+
+func TestInlayHints(t *testing.T) {
+	snap := newTestSnapshot(t, testHintsFiles())
+
+	type args struct {
+		file string
+		rng  protocol.Range
+	}
+	type want struct {
+		hints []InlayHint
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Composition": {
+			reason: "Resolved patch field paths should be hinted with their type, and composed resources with their GVK.",
+			args: args{
+				file: "/ws/composition.yaml",
+				rng:  testMultiLineRange(0, 0, 30, 0),
+			},
+			want: want{
+				hints: []InlayHint{
+					testHint(9, 14, ": Certificate (acm.aws.crossplane.io/v1alpha1)"),
+					testHint(14, 32, ": string"),
+					testHint(15, 42, ": string"),
+					testHint(20, 19, ": Unknown (example.org/v1)"),
+					testHint(22, 32, ": string"),
+				},
+			},
+		},
+		"Range": {
+			reason: "Only hints within the requested range should be returned.",
+			args: args{
+				file: "/ws/composition.yaml",
+				rng:  testMultiLineRange(14, 0, 15, 0),
+			},
+			want: want{
+				hints: []InlayHint{
+					testHint(14, 32, ": string"),
+				},
+			},
+		},
+		"NotComposition": {
+			reason: "Files without Compositions should not be hinted.",
+			args: args{
+				file: "/ws/xrd.yaml",
+				rng:  testMultiLineRange(0, 0, 30, 0),
+			},
+			want: want{
+				hints: []InlayHint{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			hints, err := snap.InlayHints(span.URIFromPath(tc.args.file), tc.args.rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.want.hints, hints); diff != "" {
+				t.Errorf("\n%s\nInlayHints(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSemanticTokens(t *testing.T) {
+	snap := newTestSnapshot(t, testHintsFiles())
+
+	type args struct {
+		file string
+		rng  *protocol.Range
+	}
+	type want struct {
+		toks *protocol.SemanticTokens
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Composition": {
+			reason: "Patch field paths should be tokenized, with invalid paths marked invalid and paths of known resources marked resolved.",
+			args: args{
+				file: "/ws/composition.yaml",
+			},
+			want: want{
+				toks: &protocol.SemanticTokens{Data: []uint32{
+					14, 21, 11, 0, 2,
+					1, 19, 23, 0, 2,
+					1, 21, 11, 0, 1,
+					1, 19, 23, 0, 2,
+					5, 21, 11, 0, 2,
+					1, 19, 13, 0, 0,
+				}},
+			},
+		},
+		"Range": {
+			reason: "Only tokens within the requested range should be returned, encoded relative to the start of the file.",
+			args: args{
+				file: "/ws/composition.yaml",
+				rng:  testRangeRef(16, 0, 40),
+			},
+			want: want{
+				toks: &protocol.SemanticTokens{Data: []uint32{
+					16, 21, 11, 0, 1,
+				}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			toks, err := snap.SemanticTokens(span.URIFromPath(tc.args.file), tc.args.rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.want.toks, toks); diff != "" {
+				t.Errorf("\n%s\nSemanticTokens(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
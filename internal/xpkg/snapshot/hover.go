@@ -0,0 +1,269 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	fieldFromFieldPath = "fromFieldPath"
+	fieldToFieldPath   = "toFieldPath"
+	fieldFieldPath     = "fieldPath"
+
+	hoverDefinedByFmt = "Defined by `%s` (%s)"
+	hoverEnumFmt      = "Enum: %s"
+	hoverDefaultFmt   = "Default: `%s`"
+	hoverTitleFmt     = "**%s** `%s`"
+)
+
+// Hover returns the documentation for the field found at the supplied position
+// in the file at the supplied uri. Nil is returned if no documentation could be
+// found for the position.
+func (s *Snapshot) Hover(uri span.URI, pos protocol.Position) (*protocol.Hover, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, scalar, key := s.nodeAt(uri, pos)
+	if scalar == nil {
+		return nil, nil
+	}
+
+	gvk, path, ok := resolveField(n, scalar, key)
+	if !ok || len(path) == 0 {
+		return nil, nil
+	}
+
+	def, ok := s.definitions[gvk]
+	if !ok {
+		return nil, nil
+	}
+
+	fs := schemaForPath(def.Schema, path)
+	if fs == nil {
+		return nil, nil
+	}
+
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: hoverContent(path, gvk, def, fs),
+		},
+		Range: tokenRange(scalar.GetToken()),
+	}, nil
+}
+
+// resolveField determines the GVK and field path that the supplied scalar
+// refers to. For most documents this is the GVK of the document and the path
+// of the scalar within it. For Compositions, scalars within a resource base
+// refer to the base's GVK, while patch field paths refer to either the
+// composite or composed resource depending on the patch type. Patches in patch
+// sets refer to the base of the first resource that uses the patch set.
+func resolveField(n workspace.Node, scalar ast.Node, key bool) (schema.GroupVersionKind, fieldpath.Segments, bool) { // nolint:gocyclo
+	segs, err := fieldpath.Parse(fieldPath(scalar))
+	if err != nil {
+		return schema.GroupVersionKind{}, nil, false
+	}
+
+	gvk := n.GetGVK()
+	if gvk.GroupKind() != xpextv1.CompositionGroupVersionKind.GroupKind() {
+		return gvk, segs, true
+	}
+
+	p, ok := paved(n)
+	if !ok {
+		return schema.GroupVersionKind{}, nil, false
+	}
+
+	// spec.resources[i]... or spec.patchSets[i]...
+	if len(segs) < 4 || segs[0].Field != "spec" || segs[2].Type != fieldpath.SegmentIndex {
+		return schema.GroupVersionKind{}, nil, false
+	}
+	var baseGVK schema.GroupVersionKind
+	switch segs[1].Field {
+	case "resources":
+		baseGVK = gvkAt(p, append(copySegments(segs[:3]), fieldpath.Field("base")))
+	case "patchSets":
+		if segs[3].Field != "patches" {
+			return schema.GroupVersionKind{}, nil, false
+		}
+		baseGVK = patchSetBaseGVK(p, segs[:3])
+	default:
+		return schema.GroupVersionKind{}, nil, false
+	}
+	xrGVK := gvkAt(p, fieldpath.Segments{fieldpath.Field("spec"), fieldpath.Field("compositeTypeRef")})
+
+	rest := segs[3:]
+	switch rest[0].Field {
+	case "base":
+		return baseGVK, rest[1:], true
+	case "patches", "readinessChecks", "connectionDetails":
+	default:
+		return schema.GroupVersionKind{}, nil, false
+	}
+
+	// only field path values refer to other resources.
+	last := rest[len(rest)-1]
+	if key || last.Type != fieldpath.SegmentField {
+		return schema.GroupVersionKind{}, nil, false
+	}
+	target, err := fieldpath.Parse(scalarValue(scalar))
+	if err != nil {
+		return schema.GroupVersionKind{}, nil, false
+	}
+
+	switch rest[0].Field {
+	case "readinessChecks", "connectionDetails":
+		if last.Field == fieldFieldPath || last.Field == fieldFromFieldPath {
+			return baseGVK, target, true
+		}
+		return schema.GroupVersionKind{}, nil, false
+	}
+
+	// patches[j]...
+	if len(rest) < 3 {
+		return schema.GroupVersionKind{}, nil, false
+	}
+	toComposite := false
+	if pt, err := p.GetString(append(copySegments(segs[:5]), fieldpath.Field("type")).String()); err == nil {
+		toComposite = pt == string(xpextv1.PatchTypeToCompositeFieldPath) || pt == string(xpextv1.PatchTypeCombineToComposite)
+	}
+
+	from, to := xrGVK, baseGVK
+	if toComposite {
+		from, to = baseGVK, xrGVK
+	}
+	switch last.Field {
+	case fieldFromFieldPath:
+		return from, target, true
+	case fieldToFieldPath:
+		return to, target, true
+	}
+	return schema.GroupVersionKind{}, nil, false
+}
+
+// patchSetBaseGVK returns the GVK of the base of the first resource that uses
+// the patch set at the supplied path. Patch sets are not tied to a single
+// resource, so this is a best guess at what their toFieldPaths refer to.
+func patchSetBaseGVK(p *fieldpath.Paved, set fieldpath.Segments) schema.GroupVersionKind {
+	name, err := p.GetString(append(copySegments(set), fieldpath.Field(fieldName)).String())
+	if err != nil {
+		return schema.GroupVersionKind{}
+	}
+	for i := 0; i < lenAt(p, pathResources); i++ {
+		res := fmt.Sprintf(indexFmt, pathResources, i)
+		patches := fmt.Sprintf(fieldFmt, res, "patches")
+		for j := 0; j < lenAt(p, patches); j++ {
+			if n, _ := p.GetString(fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, patches, j), fieldPatchSetName)); n != name {
+				continue
+			}
+			segs, err := fieldpath.Parse(fmt.Sprintf(fieldFmt, res, "base"))
+			if err != nil {
+				return schema.GroupVersionKind{}
+			}
+			return gvkAt(p, segs)
+		}
+	}
+	return schema.GroupVersionKind{}
+}
+
+// gvkAt returns the GVK formed from the apiVersion and kind fields of the
+// object at the supplied path.
+func gvkAt(p *fieldpath.Paved, path fieldpath.Segments) schema.GroupVersionKind {
+	av, _ := p.GetString(append(copySegments(path), fieldpath.Field("apiVersion")).String())
+	k, _ := p.GetString(append(copySegments(path), fieldpath.Field("kind")).String())
+	return schema.FromAPIVersionAndKind(av, k)
+}
+
+// copySegments returns a copy of the supplied segments that can be safely
+// appended to.
+func copySegments(segs fieldpath.Segments) fieldpath.Segments {
+	out := make(fieldpath.Segments, len(segs))
+	copy(out, segs)
+	return out
+}
+
+// scalarValue returns the unquoted value of the supplied scalar.
+func scalarValue(scalar ast.Node) string {
+	tok := scalar.GetToken()
+	if tok == nil {
+		return ""
+	}
+	return tok.Value
+}
+
+// hoverContent renders the markdown documentation for the supplied field
+// schema.
+func hoverContent(path fieldpath.Segments, gvk schema.GroupVersionKind, def *TypeDefinition, fs *spec.Schema) string {
+	name := gvk.Kind
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].Type == fieldpath.SegmentField {
+			name = path[i].Field
+			break
+		}
+	}
+
+	sections := []string{fmt.Sprintf(hoverTitleFmt, name, schemaType(fs))}
+	if fs.Description != "" {
+		sections = append(sections, fs.Description)
+	}
+	if len(fs.Enum) > 0 {
+		vals := make([]string, len(fs.Enum))
+		for i, e := range fs.Enum {
+			vals[i] = fmt.Sprintf("`%v`", e)
+		}
+		sections = append(sections, fmt.Sprintf(hoverEnumFmt, strings.Join(vals, ", ")))
+	}
+	if fs.Default != nil {
+		if b, err := json.Marshal(fs.Default); err == nil {
+			sections = append(sections, fmt.Sprintf(hoverDefaultFmt, string(b)))
+		}
+	}
+	sections = append(sections, fmt.Sprintf(hoverDefinedByFmt, def.Package, gvk))
+
+	return strings.Join(sections, "\n\n")
+}
+
+// schemaType returns a human readable representation of the type described by
+// the supplied schema.
+func schemaType(fs *spec.Schema) string {
+	if fs.Type.Contains("array") && fs.Items != nil && fs.Items.Schema != nil {
+		return "[]" + schemaType(fs.Items.Schema)
+	}
+	if fs.Type.Contains("object") && len(fs.Properties) == 0 && fs.AdditionalProperties != nil && fs.AdditionalProperties.Schema != nil {
+		return "map[string]" + schemaType(fs.AdditionalProperties.Schema)
+	}
+	t := strings.Join(fs.Type, "|")
+	if t == "" {
+		t = "any"
+	}
+	if fs.Format != "" {
+		t = fmt.Sprintf("%s (%s)", t, fs.Format)
+	}
+	return t
+}
@@ -0,0 +1,230 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	testXRD = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xnetworks.acme.io
+spec:
+  group: acme.io
+  names:
+    kind: XNetwork
+    plural: xnetworks
+  claimNames:
+    kind: Network
+    plural: networks
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              region:
+                type: string
+                description: Region the network is created in.
+                enum:
+                - us-east-1
+                - us-west-2
+                default: us-east-1
+            required:
+            - region
+`)
+
+	testClaim = []byte(`apiVersion: acme.io/v1alpha1
+kind: Network
+metadata:
+  name: example
+spec:
+  region: us-west-2
+`)
+
+	testComp = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xnetworks.acme.io
+spec:
+  compositeTypeRef:
+    apiVersion: acme.io/v1alpha1
+    kind: XNetwork
+  resources:
+  - name: cert
+    base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+      spec:
+        forProvider:
+          domainName: example.com
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+    - type: PatchSet
+      patchSetName: common
+  patchSets:
+  - name: common
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+`)
+)
+
+func TestHover(t *testing.T) {
+	type args struct {
+		file string
+		pos  protocol.Position
+	}
+	type want struct {
+		hover *protocol.Hover
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClaimField": {
+			reason: "Hovering a claim field should return its documentation from the XRD.",
+			args: args{
+				file: "/ws/examples/claim.yaml",
+				pos:  protocol.Position{Line: 5, Character: 4},
+			},
+			want: want{
+				hover: &protocol.Hover{
+					Contents: protocol.MarkupContent{
+						Kind:  protocol.Markdown,
+						Value: "**region** `string`\n\nRegion the network is created in.\n\nEnum: `us-east-1`, `us-west-2`\n\nDefault: `\"us-east-1\"`\n\nDefined by `workspace` (acme.io/v1alpha1, Kind=Network)",
+					},
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 5, Character: 2},
+						End:   protocol.Position{Line: 5, Character: 8},
+					},
+				},
+			},
+		},
+		"CompositionBaseField": {
+			reason: "Hovering a field in a Composition base should return its documentation from the composed CRD.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 15, Character: 12},
+			},
+			want: want{
+				hover: &protocol.Hover{
+					Contents: protocol.MarkupContent{
+						Kind:  protocol.Markdown,
+						Value: "**domainName** `string`\n\nFully qualified domain name (FQDN),that to secure with an ACM certificate.\n\nDefined by `workspace` (acm.aws.crossplane.io/v1alpha1, Kind=Certificate)",
+					},
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 15, Character: 10},
+						End:   protocol.Position{Line: 15, Character: 20},
+					},
+				},
+			},
+		},
+		"CompositionPatchFromFieldPath": {
+			reason: "Hovering a fromFieldPath should return documentation for the field in the composite resource.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 17, Character: 22},
+			},
+			want: want{
+				hover: &protocol.Hover{
+					Contents: protocol.MarkupContent{
+						Kind:  protocol.Markdown,
+						Value: "**region** `string`\n\nRegion the network is created in.\n\nEnum: `us-east-1`, `us-west-2`\n\nDefault: `\"us-east-1\"`\n\nDefined by `workspace` (acme.io/v1alpha1, Kind=XNetwork)",
+					},
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 17, Character: 21},
+						End:   protocol.Position{Line: 17, Character: 32},
+					},
+				},
+			},
+		},
+		"PatchSetFromFieldPath": {
+			reason: "Hovering a fromFieldPath in a patch set should return documentation for the field in the composite resource.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 24, Character: 22},
+			},
+			want: want{
+				hover: &protocol.Hover{
+					Contents: protocol.MarkupContent{
+						Kind:  protocol.Markdown,
+						Value: "**region** `string`\n\nRegion the network is created in.\n\nEnum: `us-east-1`, `us-west-2`\n\nDefault: `\"us-east-1\"`\n\nDefined by `workspace` (acme.io/v1alpha1, Kind=XNetwork)",
+					},
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 24, Character: 21},
+						End:   protocol.Position{Line: 24, Character: 32},
+					},
+				},
+			},
+		},
+		"PatchSetToFieldPath": {
+			reason: "Hovering a toFieldPath in a patch set should return documentation for the field in the base of the resource that uses it.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 25, Character: 22},
+			},
+			want: want{
+				hover: &protocol.Hover{
+					Contents: protocol.MarkupContent{
+						Kind:  protocol.Markdown,
+						Value: "**region** `string`\n\nRegion is the region you'd like your Certificate to be created in.\n\nDefined by `workspace` (acm.aws.crossplane.io/v1alpha1, Kind=Certificate)",
+					},
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 25, Character: 19},
+						End:   protocol.Position{Line: 25, Character: 42},
+					},
+				},
+			},
+		},
+		"UnknownField": {
+			reason: "Hovering a field that is not in the schema should not return any details.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 2, Character: 2},
+			},
+			want: want{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, map[string][]byte{
+				"/ws/xrd.yaml":            testXRD,
+				"/ws/crd.yaml":            testSingleVersionCRD,
+				"/ws/composition.yaml":    testComp,
+				"/ws/examples/claim.yaml": testClaim,
+			})
+
+			hover, _ := snap.Hover(span.URIFromPath(tc.args.file), tc.args.pos)
+
+			if diff := cmp.Diff(tc.want.hover, hover); diff != "" {
+				t.Errorf("\n%s\nHover(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -48,6 +48,7 @@ const (
 	errPackageDNEFmt           = "Package %s does not exist locally. Please run `up xpkg dep` to fix."
 	errVersionDENFmt           = "Version matching %s does not exist locally. Please run `up xpkg dep` to fix."
 	errWrongPkgTypeFmt         = "Incorrect package type. '%s' does not match type for %s of '%s'"
+	errNoMetaFile              = "workspace does not contain a crossplane.yaml"
 )
 
 // MetaValidator defines a validator for meta files.
@@ -144,6 +145,7 @@ func validateAPIVersion(o runtime.Object) error {
 				metav1.ConfigurationGroupVersionKind.GroupVersion(),
 			),
 			TypeCode: validator.WarningTypeCode,
+			Reason:   validator.ReasonDeprecatedAPIVersion,
 		}
 	case *v1alpha1.Provider:
 		return &validator.Validation{
@@ -154,6 +156,7 @@ func validateAPIVersion(o runtime.Object) error {
 				metav1.ProviderGroupVersionKind.GroupVersion(),
 			),
 			TypeCode: validator.WarningTypeCode,
+			Reason:   validator.ReasonDeprecatedAPIVersion,
 		}
 	}
 	return nil
@@ -218,12 +221,14 @@ func (v *VersionValidator) validate(i int, d v1beta1.Dependency) error {
 		return &validator.Validation{
 			Name:    fmt.Sprintf(dependsOnPathFmt, i, strings.ToLower(string(d.Type))),
 			Message: fmt.Sprintf(errPackageDNEFmt, d.Package),
+			Reason:  validator.ReasonPackageNotFound,
 		}
 	}
 	if !versionMatch(d.Constraints, vers) {
 		return &validator.Validation{
 			Name:    fmt.Sprintf(dependsOnPathFmt, i, versionField),
 			Message: fmt.Sprintf(errVersionDENFmt, d.Constraints),
+			Reason:  validator.ReasonVersionNotFound,
 		}
 	}
 	return nil
@@ -258,3 +263,19 @@ func versionMatch(constraint string, vers []string) bool {
 	}
 	return found
 }
+
+// AddDependency adds the supplied dependency to the crossplane.yaml of the
+// workspace, replacing any existing dependency on the same package.
+func (s *Snapshot) AddDependency(d v1beta1.Dependency) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta := s.wsview.Meta()
+	if meta == nil {
+		return errors.New(errNoMetaFile)
+	}
+	if err := meta.Upsert(d); err != nil {
+		return err
+	}
+	return s.w.Write(meta)
+}
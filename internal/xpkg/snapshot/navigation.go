@@ -0,0 +1,470 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	xpextv1beta1 "github.com/crossplane/crossplane/apis/apiextensions/v1beta1"
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	// NOTE(hasheddan): objects in the cache are written to files named after
+	// the object.
+	cachedObjFmt = "%s.yaml"
+
+	fieldKind         = "kind"
+	fieldName         = "name"
+	fieldPatchSetName = "patchSetName"
+
+	pathClaimNamesKind   = "spec.claimNames.kind"
+	pathCompositeTypeRef = "spec.compositeTypeRef"
+	pathGroup            = "spec.group"
+	pathNamesKind        = "spec.names.kind"
+	pathPatchSets        = "spec.patchSets"
+	pathResources        = "spec.resources"
+
+	indexFmt = "%s[%d]"
+	fieldFmt = "%s.%s"
+)
+
+// crdGroupKind is the GroupKind of a CustomResourceDefinition.
+var crdGroupKind = extv1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind()
+
+// builtinKinds are the kinds defined by Kubernetes and Crossplane that are
+// used to define packages, rather than being instances of package types.
+var builtinKinds = map[schema.GroupKind]struct{}{
+	xpextv1.CompositeResourceDefinitionGroupVersionKind.GroupKind(): {},
+	xpextv1.CompositionGroupVersionKind.GroupKind():                 {},
+	pkgmetav1.ConfigurationGroupVersionKind.GroupKind():             {},
+	pkgmetav1.ProviderGroupVersionKind.GroupKind():                  {},
+	crdGroupKind: {},
+}
+
+// symbolType is the type of a symbol that can be referenced.
+type symbolType int
+
+const (
+	// symbolKind is a type, referenced by its GroupKind.
+	symbolKind symbolType = iota
+	// symbolPatchSet is a patch set, referenced by name within the
+	// Composition that defines it.
+	symbolPatchSet
+	// symbolField is a field in the schema of an XRD, referenced by its path
+	// within the composite resource.
+	symbolField
+)
+
+// A symbol is something in the workspace that can be defined and referenced.
+type symbol struct {
+	typ  symbolType
+	gk   schema.GroupKind
+	name string
+	// node is the Composition that defines a patch set.
+	node workspace.Node
+	// path is the path of a field within a composite resource. Index
+	// segments match any index.
+	path fieldpath.Segments
+}
+
+// Definition returns the locations where the symbol found at the supplied
+// position in the file at the supplied uri is defined. Types that are not
+// defined in the workspace resolve to the cached definition of the dependency
+// that defines them.
+func (s *Snapshot) Definition(uri span.URI, pos protocol.Position) ([]protocol.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, scalar, key := s.nodeAt(uri, pos)
+	if scalar == nil || key {
+		return nil, nil
+	}
+	sym := symbolAt(n, scalar)
+	if sym == nil {
+		return nil, nil
+	}
+
+	locs := s.declarations(sym)
+	if len(locs) == 0 && sym.typ == symbolKind {
+		locs = s.depDeclarations(sym.gk)
+	}
+	sortLocations(locs)
+	return locs, nil
+}
+
+// References returns the locations where the symbol found at the supplied
+// position in the file at the supplied uri is referenced. If includeDecl is
+// true, the locations where the symbol is declared in the workspace are
+// included.
+func (s *Snapshot) References(uri span.URI, pos protocol.Position, includeDecl bool) ([]protocol.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, scalar, key := s.nodeAt(uri, pos)
+	if scalar == nil || key {
+		return nil, nil
+	}
+	sym := symbolAt(n, scalar)
+	if sym == nil {
+		return nil, nil
+	}
+
+	locs := s.references(sym)
+	if includeDecl {
+		locs = append(locs, s.declarations(sym)...)
+	}
+	sortLocations(locs)
+	return locs, nil
+}
+
+// symbolAt returns the symbol that the supplied scalar value refers to, or
+// nil if it does not refer to a symbol.
+func symbolAt(n workspace.Node, scalar ast.Node) *symbol { // nolint:gocyclo
+	segs, err := fieldpath.Parse(fieldPath(scalar))
+	if err != nil {
+		return nil
+	}
+	p, ok := paved(n)
+	if !ok {
+		return nil
+	}
+	gvk := n.GetGVK()
+	val := scalarValue(scalar)
+
+	if matchPath(segs, fieldKind) || matchPath(segs, apiVersionField) {
+		if _, ok := builtinKinds[gvk.GroupKind()]; !ok {
+			return &symbol{typ: symbolKind, gk: gvk.GroupKind()}
+		}
+	}
+
+	switch gvk.GroupKind() {
+	case xpextv1.CompositeResourceDefinitionGroupVersionKind.GroupKind(),
+		crdGroupKind:
+		if matchPath(segs, "spec", "names", fieldKind) || matchPath(segs, "spec", "claimNames", fieldKind) {
+			group, _ := p.GetString(pathGroup)
+			return &symbol{typ: symbolKind, gk: schema.GroupKind{Group: group, Kind: val}}
+		}
+	case xpextv1.CompositionGroupVersionKind.GroupKind():
+		switch {
+		case matchPath(segs, "spec", "compositeTypeRef", fieldKind), matchPath(segs, "spec", "compositeTypeRef", apiVersionField):
+			return &symbol{typ: symbolKind, gk: gvkAt(p, segs[:2]).GroupKind()}
+		case matchPath(segs, "spec", "resources", "*", "base", fieldKind), matchPath(segs, "spec", "resources", "*", "base", apiVersionField):
+			return &symbol{typ: symbolKind, gk: gvkAt(p, segs[:4]).GroupKind()}
+		case matchPath(segs, "spec", "resources", "*", "patches", "*", fieldPatchSetName), matchPath(segs, "spec", "patchSets", "*", fieldName):
+			return &symbol{typ: symbolPatchSet, name: val, node: n}
+		}
+	}
+	return nil
+}
+
+// matchPath returns true if the supplied segments match the supplied pattern.
+// A pattern element of "*" matches any index.
+func matchPath(segs fieldpath.Segments, pattern ...string) bool {
+	if len(segs) != len(pattern) {
+		return false
+	}
+	for i, p := range pattern {
+		switch {
+		case p == "*" && segs[i].Type == fieldpath.SegmentIndex:
+		case p != "*" && segs[i].Type == fieldpath.SegmentField && segs[i].Field == p:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// declarations returns the locations in the workspace where the supplied
+// symbol is declared.
+func (s *Snapshot) declarations(sym *symbol) []protocol.Location { // nolint:gocyclo
+	locs := []protocol.Location{}
+
+	switch sym.typ {
+	case symbolKind:
+		for _, n := range s.rootNodes() {
+			switch n.GetGVK().GroupKind() {
+			case xpextv1.CompositeResourceDefinitionGroupVersionKind.GroupKind(),
+				crdGroupKind:
+			default:
+				continue
+			}
+			p, ok := paved(n)
+			if !ok {
+				continue
+			}
+			if group, _ := p.GetString(pathGroup); group != sym.gk.Group {
+				continue
+			}
+			for _, path := range []string{pathNamesKind, pathClaimNamesKind} {
+				if kind, _ := p.GetString(path); kind == sym.gk.Kind {
+					if loc, ok := locationOf(n, path); ok {
+						locs = append(locs, loc)
+					}
+				}
+			}
+		}
+	case symbolPatchSet:
+		p, ok := paved(sym.node)
+		if !ok {
+			return locs
+		}
+		for i := 0; i < lenAt(p, pathPatchSets); i++ {
+			path := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathPatchSets, i), fieldName)
+			if name, _ := p.GetString(path); name == sym.name {
+				if loc, ok := locationOf(sym.node, path); ok {
+					locs = append(locs, loc)
+				}
+			}
+		}
+	}
+	return locs
+}
+
+// references returns the locations in the workspace where the supplied symbol
+// is referenced.
+func (s *Snapshot) references(sym *symbol) []protocol.Location { // nolint:gocyclo
+	locs := []protocol.Location{}
+
+	switch sym.typ {
+	case symbolKind:
+		for _, n := range s.rootNodes() {
+			if n.GetGVK().GroupKind() == sym.gk {
+				if loc, ok := locationOf(n, fieldKind); ok {
+					locs = append(locs, loc)
+				}
+				continue
+			}
+			if n.GetGVK().GroupKind() != xpextv1.CompositionGroupVersionKind.GroupKind() {
+				continue
+			}
+			p, ok := paved(n)
+			if !ok {
+				continue
+			}
+			if gvkAt(p, fieldpath.Segments{fieldpath.Field("spec"), fieldpath.Field("compositeTypeRef")}).GroupKind() == sym.gk {
+				if loc, ok := locationOf(n, fmt.Sprintf(fieldFmt, pathCompositeTypeRef, fieldKind)); ok {
+					locs = append(locs, loc)
+				}
+			}
+			for i := 0; i < lenAt(p, pathResources); i++ {
+				base := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), "base")
+				segs, err := fieldpath.Parse(base)
+				if err != nil {
+					continue
+				}
+				if gvkAt(p, segs).GroupKind() != sym.gk {
+					continue
+				}
+				if loc, ok := locationOf(n, fmt.Sprintf(fieldFmt, base, fieldKind)); ok {
+					locs = append(locs, loc)
+				}
+			}
+		}
+	case symbolPatchSet:
+		p, ok := paved(sym.node)
+		if !ok {
+			return locs
+		}
+		for i := 0; i < lenAt(p, pathResources); i++ {
+			patches := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), "patches")
+			for j := 0; j < lenAt(p, patches); j++ {
+				path := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, patches, j), fieldPatchSetName)
+				if name, _ := p.GetString(path); name == sym.name {
+					if loc, ok := locationOf(sym.node, path); ok {
+						locs = append(locs, loc)
+					}
+				}
+			}
+		}
+	}
+	return locs
+}
+
+// depDeclarations returns the locations of the cached definitions for the
+// supplied GroupKind in the workspace's dependencies.
+func (s *Snapshot) depDeclarations(gk schema.GroupKind) []protocol.Location {
+	locs := []protocol.Location{}
+	for _, pkg := range s.packages {
+		if pkg.Location() == "" {
+			continue
+		}
+		for _, o := range pkg.Objects() {
+			name, path, ok := declaresKind(o, gk)
+			if !ok {
+				continue
+			}
+			file := filepath.Join(pkg.Location(), fmt.Sprintf(cachedObjFmt, name))
+			if loc, ok := fileLocationOf(file, path); ok {
+				locs = append(locs, loc)
+			}
+		}
+	}
+	return locs
+}
+
+// declaresKind determines whether the supplied CRD or XRD declares the supplied
+// GroupKind. If it does, the name of the object and the path to the field that
+// declares the kind are returned.
+func declaresKind(o runtime.Object, gk schema.GroupKind) (string, string, bool) { // nolint:gocyclo
+	switch d := o.(type) {
+	case *extv1.CustomResourceDefinition:
+		if d.Spec.Group == gk.Group && d.Spec.Names.Kind == gk.Kind {
+			return d.GetName(), pathNamesKind, true
+		}
+	case *extv1beta1.CustomResourceDefinition:
+		if d.Spec.Group == gk.Group && d.Spec.Names.Kind == gk.Kind {
+			return d.GetName(), pathNamesKind, true
+		}
+	case *xpextv1.CompositeResourceDefinition:
+		if d.Spec.Group != gk.Group {
+			return "", "", false
+		}
+		if d.Spec.Names.Kind == gk.Kind {
+			return d.GetName(), pathNamesKind, true
+		}
+		if d.Spec.ClaimNames != nil && d.Spec.ClaimNames.Kind == gk.Kind {
+			return d.GetName(), pathClaimNamesKind, true
+		}
+	case *xpextv1beta1.CompositeResourceDefinition:
+		if d.Spec.Group != gk.Group {
+			return "", "", false
+		}
+		if d.Spec.Names.Kind == gk.Kind {
+			return d.GetName(), pathNamesKind, true
+		}
+		if d.Spec.ClaimNames != nil && d.Spec.ClaimNames.Kind == gk.Kind {
+			return d.GetName(), pathClaimNamesKind, true
+		}
+	}
+	return "", "", false
+}
+
+// rootNodes returns the nodes in the workspace that correspond to top level
+// documents, excluding nodes embedded in other documents.
+func (s *Snapshot) rootNodes() []workspace.Node {
+	nodes := []workspace.Node{}
+	for _, d := range s.wsview.FileDetails() {
+		for id := range d.NodeIDs {
+			if n, ok := s.wsview.Nodes()[id]; ok {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
+
+// paved returns the supplied node's object as a fieldpath.Paved.
+func paved(n workspace.Node) (*fieldpath.Paved, bool) {
+	u, ok := n.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return nil, false
+	}
+	return fieldpath.Pave(u.Object), true
+}
+
+// lenAt returns the length of the list found at the supplied path. Zero is
+// returned if no list exists at the path.
+func lenAt(p *fieldpath.Paved, path string) int {
+	v, err := p.GetValue(path)
+	if err != nil {
+		return 0
+	}
+	l, ok := v.([]any)
+	if !ok {
+		return 0
+	}
+	return len(l)
+}
+
+// locationOf returns the location of the value at the supplied path within the
+// supplied node.
+func locationOf(n workspace.Node, path string) (protocol.Location, bool) {
+	found, ok := nodeAtPath(n.GetAST(), path)
+	if !ok {
+		return protocol.Location{}, false
+	}
+	return protocol.Location{
+		URI:   protocol.URIFromSpanURI(span.URIFromPath(n.GetFileName())),
+		Range: tokenRange(found.GetToken()),
+	}, true
+}
+
+// fileLocationOf returns the location of the value at the supplied path within
+// the first document of the file at the supplied path on disk.
+func fileLocationOf(file, path string) (protocol.Location, bool) {
+	b, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return protocol.Location{}, false
+	}
+	loc := protocol.Location{
+		URI: protocol.URIFromSpanURI(span.URIFromPath(file)),
+	}
+	f, err := parser.ParseBytes(b, 0)
+	if err != nil || len(f.Docs) == 0 {
+		// we can still send the user to the file.
+		return loc, true
+	}
+	if found, ok := nodeAtPath(f.Docs[0].Body, path); ok {
+		loc.Range = tokenRange(found.GetToken())
+	}
+	return loc, true
+}
+
+// nodeAtPath returns the node found at the supplied path within the supplied
+// AST.
+func nodeAtPath(n ast.Node, path string) (ast.Node, bool) {
+	yp, err := yaml.PathString(rootPathPrefix + path)
+	if err != nil {
+		return nil, false
+	}
+	found, err := yp.FilterNode(n)
+	if err != nil || found == nil {
+		return nil, false
+	}
+	return found, true
+}
+
+// sortLocations sorts the supplied locations by file and position so that
+// results are deterministic.
+func sortLocations(locs []protocol.Location) {
+	sort.SliceStable(locs, func(i, j int) bool {
+		if locs[i].URI != locs[j].URI {
+			return locs[i].URI < locs[j].URI
+		}
+		if locs[i].Range.Start.Line != locs[j].Range.Start.Line {
+			return locs[i].Range.Start.Line < locs[j].Range.Start.Line
+		}
+		return locs[i].Range.Start.Character < locs[j].Range.Start.Character
+	})
+}
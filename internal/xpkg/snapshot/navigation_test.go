@@ -0,0 +1,208 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+)
+
+var testPatchSetComp = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xnetworks.acme.io
+spec:
+  compositeTypeRef:
+    apiVersion: acme.io/v1alpha1
+    kind: XNetwork
+  patchSets:
+  - name: common
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+  resources:
+  - name: cert
+    base:
+      apiVersion: acm.aws.crossplane.io/v1alpha1
+      kind: Certificate
+    patches:
+    - type: PatchSet
+      patchSetName: common
+`)
+
+// testNavigationFiles returns the files of the workspace that navigation is
+// tested against.
+func testNavigationFiles() map[string][]byte {
+	return map[string][]byte{
+		"/ws/xrd.yaml":            testXRD,
+		"/ws/crd.yaml":            testSingleVersionCRD,
+		"/ws/composition.yaml":    testPatchSetComp,
+		"/ws/examples/claim.yaml": testClaim,
+	}
+}
+
+func testLocation(file string, line, start, end uint32) protocol.Location {
+	return protocol.Location{
+		URI: protocol.URIFromSpanURI(span.URIFromPath(file)),
+		Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		},
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	type args struct {
+		file string
+		pos  protocol.Position
+	}
+	type want struct {
+		locs []protocol.Location
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CompositeTypeRef": {
+			reason: "A Composition's compositeTypeRef should resolve to the XRD that defines the composite resource.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 7, Character: 12},
+			},
+			want: want{
+				locs: []protocol.Location{testLocation("/ws/xrd.yaml", 7, 10, 18)},
+			},
+		},
+		"ClaimKind": {
+			reason: "A claim's kind should resolve to the claimNames of the XRD that defines it.",
+			args: args{
+				file: "/ws/examples/claim.yaml",
+				pos:  protocol.Position{Line: 1, Character: 8},
+			},
+			want: want{
+				locs: []protocol.Location{testLocation("/ws/xrd.yaml", 10, 10, 17)},
+			},
+		},
+		"PatchSetName": {
+			reason: "A patchSetName should resolve to the patch set it references.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 20, Character: 22},
+			},
+			want: want{
+				locs: []protocol.Location{testLocation("/ws/composition.yaml", 9, 10, 16)},
+			},
+		},
+		"ComposedResourceKind": {
+			reason: "A base resource's kind should resolve to the CRD that defines it.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 17, Character: 14},
+			},
+			want: want{
+				locs: []protocol.Location{testLocation("/ws/crd.yaml", 12, 10, 21)},
+			},
+		},
+		"NotASymbol": {
+			reason: "A value that does not reference a symbol should not resolve.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 3, Character: 10},
+			},
+			want: want{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, testNavigationFiles())
+
+			locs, _ := snap.Definition(span.URIFromPath(tc.args.file), tc.args.pos)
+
+			if diff := cmp.Diff(tc.want.locs, locs); diff != "" {
+				t.Errorf("\n%s\nDefinition(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReferences(t *testing.T) {
+	type args struct {
+		file        string
+		pos         protocol.Position
+		includeDecl bool
+	}
+	type want struct {
+		locs []protocol.Location
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CompositeKind": {
+			reason: "References to a composite resource kind should include the Compositions that compose it.",
+			args: args{
+				file:        "/ws/xrd.yaml",
+				pos:         protocol.Position{Line: 7, Character: 12},
+				includeDecl: true,
+			},
+			want: want{
+				locs: []protocol.Location{
+					testLocation("/ws/composition.yaml", 7, 10, 18),
+					testLocation("/ws/xrd.yaml", 7, 10, 18),
+				},
+			},
+		},
+		"ClaimKind": {
+			reason: "References to a claim kind should include example claims.",
+			args: args{
+				file: "/ws/xrd.yaml",
+				pos:  protocol.Position{Line: 10, Character: 12},
+			},
+			want: want{
+				locs: []protocol.Location{
+					testLocation("/ws/examples/claim.yaml", 1, 6, 13),
+				},
+			},
+		},
+		"PatchSet": {
+			reason: "References to a patch set should include each patch that uses it.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 9, Character: 12},
+			},
+			want: want{
+				locs: []protocol.Location{
+					testLocation("/ws/composition.yaml", 20, 20, 26),
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, testNavigationFiles())
+
+			locs, _ := snap.References(span.URIFromPath(tc.args.file), tc.args.pos, tc.args.includeDecl)
+
+			if diff := cmp.Diff(tc.want.locs, locs); diff != "" {
+				t.Errorf("\n%s\nReferences(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
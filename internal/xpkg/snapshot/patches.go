@@ -0,0 +1,342 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"fmt"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/xcrd"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+const (
+	fieldTransforms = "transforms"
+
+	typeString  = "string"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+	typeObject  = "object"
+	typeArray   = "array"
+
+	extIntOrString = "x-kubernetes-int-or-string"
+
+	errFieldNotFoundFmt  = "%s is not a field of %s"
+	errInvalidPathFmt    = "invalid field path %s: %s"
+	errTransformInputFmt = "%s transform does not accept input of type %s"
+	errPatchTypeFmt      = "cannot patch value of type %s to %s of type %s (%s)"
+)
+
+// convertTypes maps the output types of convert transforms to the schema
+// types they produce.
+var convertTypes = map[string]string{
+	xpextv1.ConvertTransformTypeString:  typeString,
+	xpextv1.ConvertTransformTypeBool:    typeBoolean,
+	xpextv1.ConvertTransformTypeInt:     typeInteger,
+	xpextv1.ConvertTransformTypeInt64:   typeInteger,
+	xpextv1.ConvertTransformTypeFloat64: typeNumber,
+}
+
+// PatchTypeChecker checks the patches of a Composition against the schemas of
+// the composite and composed resources they refer to. Field paths must exist
+// in the schema of the resource they read from or write to, and the type of
+// each patched value must be accepted by its transforms and by the field it
+// is ultimately written to. Patches that refer to resources whose schema is
+// not known are not checked.
+type PatchTypeChecker struct {
+	s *Snapshot
+	// composite is the schema of the fields Crossplane adds to every
+	// composite resource, which are not declared by its XRD.
+	composite *spec.Schema
+}
+
+// NewPatchTypeChecker returns a new PatchTypeChecker.
+func NewPatchTypeChecker(s *Snapshot) *PatchTypeChecker {
+	_, cs, err := newV1SchemaValidator(extv1.JSONSchemaProps{
+		Type: typeObject,
+		Properties: map[string]extv1.JSONSchemaProps{
+			fieldSpec: {
+				Type:       typeObject,
+				Properties: xcrd.CompositeResourceSpecProps(),
+			},
+		},
+	})
+	if err != nil {
+		cs = nil
+	}
+	return &PatchTypeChecker{
+		s:         s,
+		composite: cs,
+	}
+}
+
+// patchTarget is a resource that a patch reads from or writes to.
+type patchTarget struct {
+	gvk       schema.GroupVersionKind
+	composite bool
+}
+
+// Check returns the errors found in the patches of the supplied Composition.
+// Patches within patch sets are checked against each resource that uses
+// them.
+func (p *PatchTypeChecker) Check(comp *xpextv1.Composition) []error {
+	xr := patchTarget{
+		gvk:       schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind),
+		composite: true,
+	}
+	sets := make(map[string]int, len(comp.Spec.PatchSets))
+	for i, ps := range comp.Spec.PatchSets {
+		sets[ps.Name] = i
+	}
+
+	errs := []error{}
+	seen := map[string]struct{}{}
+	add := func(es []error) {
+		for _, e := range es {
+			v, ok := e.(*validator.Validation) //nolint:errorlint // checks only return validations
+			if !ok {
+				continue
+			}
+			// patch sets shared by resources of the same kind produce the
+			// same errors.
+			key := v.Name + v.Message
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			errs = append(errs, e)
+		}
+	}
+	for i, res := range comp.Spec.Resources {
+		cd := patchTarget{gvk: baseGVK(res)}
+		patches := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), fieldPatches)
+		for j, pt := range res.Patches {
+			if pt.Type != xpextv1.PatchTypePatchSet {
+				add(p.checkPatch(pt, fmt.Sprintf(indexFmt, patches, j), xr, cd))
+				continue
+			}
+			if pt.PatchSetName == nil {
+				continue
+			}
+			k, ok := sets[*pt.PatchSetName]
+			if !ok {
+				continue
+			}
+			setPatches := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathPatchSets, k), fieldPatches)
+			for l, spt := range comp.Spec.PatchSets[k].Patches {
+				add(p.checkPatch(spt, fmt.Sprintf(indexFmt, setPatches, l), xr, cd))
+			}
+		}
+	}
+	return errs
+}
+
+// checkPatch checks the supplied patch, found at the supplied path, that
+// patches between the supplied composite and composed resources.
+func (p *PatchTypeChecker) checkPatch(pt xpextv1.Patch, path string, xr, cd patchTarget) []error { // nolint:gocyclo
+	from, to := xr, cd
+	if pt.Type == xpextv1.PatchTypeToCompositeFieldPath || pt.Type == xpextv1.PatchTypeCombineToComposite {
+		from, to = cd, xr
+	}
+
+	errs := []error{}
+	var in string
+	switch pt.Type {
+	case xpextv1.PatchTypeFromCompositeFieldPath, xpextv1.PatchTypeToCompositeFieldPath, "":
+		if pt.FromFieldPath == nil {
+			return errs
+		}
+		t, err := p.typeAt(from, *pt.FromFieldPath, fmt.Sprintf(fieldFmt, path, fieldFromFieldPath))
+		if err != nil {
+			return append(errs, err)
+		}
+		in = t
+	case xpextv1.PatchTypeCombineFromComposite, xpextv1.PatchTypeCombineToComposite:
+		if pt.Combine == nil {
+			return errs
+		}
+		for k, v := range pt.Combine.Variables {
+			name := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, fmt.Sprintf(fieldFmt, path, fieldCombineVariables), k), fieldFromFieldPath)
+			if _, err := p.typeAt(from, v.FromFieldPath, name); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		// the string strategy is the only supported combine strategy.
+		in = typeString
+	default:
+		return errs
+	}
+
+	out, err := transformsType(pt.Transforms, in, path)
+	if err != nil {
+		return append(errs, err)
+	}
+
+	toPath, toName := pt.ToFieldPath, fmt.Sprintf(fieldFmt, path, fieldToFieldPath)
+	if toPath == nil {
+		// the toFieldPath of a patch defaults to its fromFieldPath.
+		toPath, toName = pt.FromFieldPath, fmt.Sprintf(fieldFmt, path, fieldFromFieldPath)
+	}
+	if toPath == nil {
+		return errs
+	}
+	t, err := p.typeAt(to, *toPath, toName)
+	if err != nil {
+		return append(errs, err)
+	}
+	if !assignable(out, t) {
+		errs = append(errs, &validator.Validation{
+			TypeCode: validator.FieldErrorTypeCode,
+			Message:  fmt.Sprintf(errPatchTypeFmt, out, *toPath, t, to.gvk),
+			Name:     toName,
+			Reason:   validator.ReasonTypeMismatch,
+		})
+	}
+	return errs
+}
+
+// typeAt returns the type of the field at the supplied path in the schema
+// of the supplied resource. An error named after the supplied name is
+// returned if the field does not exist. An empty type is returned if the
+// schema of the resource or the type of the field is not known.
+func (p *PatchTypeChecker) typeAt(t patchTarget, path, name string) (string, error) {
+	def, ok := p.s.definitions[t.gvk]
+	if !ok || def.Schema == nil {
+		return "", nil
+	}
+	segs, err := fieldpath.Parse(path)
+	if err != nil {
+		return "", &validator.Validation{
+			TypeCode: validator.FieldErrorTypeCode,
+			Message:  fmt.Sprintf(errInvalidPathFmt, path, err),
+			Name:     name,
+		}
+	}
+	fs, valid := resolveSchema(def.Schema, segs)
+	if !valid && t.composite && p.composite != nil {
+		fs, valid = resolveSchema(p.composite, segs)
+	}
+	if !valid {
+		return "", &validator.Validation{
+			TypeCode: validator.FieldErrorTypeCode,
+			Message:  fmt.Sprintf(errFieldNotFoundFmt, path, t.gvk),
+			Name:     name,
+			Reason:   validator.ReasonFieldNotFound,
+		}
+	}
+	if fs == nil {
+		return metadataType(segs), nil
+	}
+	return valueType(fs), nil
+}
+
+// transformsType returns the type output by the supplied transforms of the
+// patch at the supplied path when given input of the supplied type. An error
+// is returned if a transform does not accept its input.
+func transformsType(ts []xpextv1.Transform, in, path string) (string, error) {
+	for k, t := range ts {
+		var out string
+		accepts := true
+		switch t.Type {
+		case xpextv1.TransformTypeMath:
+			// multiplying preserves the type of the input.
+			accepts = in == "" || in == typeInteger || in == typeNumber
+			out = in
+		case xpextv1.TransformTypeMap:
+			accepts = in == "" || in == typeString
+			out = mapType(t.Map)
+		case xpextv1.TransformTypeString:
+			out = typeString
+		case xpextv1.TransformTypeConvert:
+			accepts = in != typeObject && in != typeArray
+			if t.Convert != nil {
+				out = convertTypes[t.Convert.ToType]
+			}
+		}
+		if !accepts {
+			return "", &validator.Validation{
+				TypeCode: validator.FieldErrorTypeCode,
+				Message:  fmt.Sprintf(errTransformInputFmt, t.Type, in),
+				Name:     fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, fmt.Sprintf(fieldFmt, path, fieldTransforms), k), fieldType),
+				Reason:   validator.ReasonTypeMismatch,
+			}
+		}
+		in = out
+	}
+	return in, nil
+}
+
+// mapType returns the type of the values output by the supplied map
+// transform. Map values are always strings, so the type is only unknown if the
+// map has no values.
+func mapType(m *xpextv1.MapTransform) string {
+	if m == nil || len(m.Pairs) == 0 {
+		return ""
+	}
+	return typeString
+}
+
+// assignable returns true if a value of the supplied type may be written to a
+// field of the supplied type. Unknown types are always assignable.
+func assignable(from, to string) bool {
+	if from == "" || to == "" || from == to {
+		return true
+	}
+	return from == typeInteger && to == typeNumber
+}
+
+// valueType returns the type of the values described by the supplied schema,
+// or an empty string if it is not a single known type.
+func valueType(s *spec.Schema) string {
+	if ios, ok := s.Extensions.GetBool(extIntOrString); ok && ios {
+		return ""
+	}
+	if len(s.Type) != 1 {
+		return ""
+	}
+	return s.Type[0]
+}
+
+// metadataType returns the type of the supplied object metadata field, which
+// is often not described by the schema of a resource.
+func metadataType(segs fieldpath.Segments) string {
+	if len(segs) < 2 || segs[0].Field != fieldMetadata {
+		return ""
+	}
+	switch {
+	case len(segs) == 2 && (segs[1].Field == "name" || segs[1].Field == "namespace" || segs[1].Field == "uid" || segs[1].Field == "generateName"):
+		return typeString
+	case len(segs) == 3 && (segs[1].Field == "labels" || segs[1].Field == "annotations"):
+		return typeString
+	}
+	return ""
+}
+
+// baseGVK returns the GVK of the base of the supplied composed template.
+func baseGVK(res xpextv1.ComposedTemplate) schema.GroupVersionKind {
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(res.Base.Raw); err != nil {
+		return schema.GroupVersionKind{}
+	}
+	return u.GroupVersionKind()
+}
@@ -0,0 +1,370 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+var (
+	testCertificateBase = runtime.RawExtension{Raw: []byte(`{"apiVersion": "acm.aws.crossplane.io/v1alpha1", "kind": "Certificate"}`)}
+	testUnknownBase     = runtime.RawExtension{Raw: []byte(`{"apiVersion": "example.org/v1", "kind": "Unknown"}`)}
+	testPoolBase        = runtime.RawExtension{Raw: []byte(`{"apiVersion": "acme.io/v1alpha1", "kind": "XPool"}`)}
+
+	testPoolXRD = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xpools.acme.io
+spec:
+  group: acme.io
+  names:
+    kind: XPool
+    plural: xpools
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+              ratio:
+                type: number
+              tier:
+                type: string
+              enabled:
+                type: boolean
+`)
+)
+
+// testPatchesFiles returns the files of the workspace that patches are
+// checked against.
+func testPatchesFiles() map[string][]byte {
+	files := testHintsFiles()
+	files["/ws/pool.yaml"] = testPoolXRD
+	return files
+}
+
+// testComposition returns a composition of the supplied acme.io/v1alpha1
+// composite kind that holds the supplied patch sets and resources.
+func testComposition(kind string, sets []v1.PatchSet, res ...v1.ComposedTemplate) *v1.Composition {
+	return &v1.Composition{
+		Spec: v1.CompositionSpec{
+			CompositeTypeRef: v1.TypeReference{
+				APIVersion: "acme.io/v1alpha1",
+				Kind:       kind,
+			},
+			PatchSets: sets,
+			Resources: res,
+		},
+	}
+}
+
+func testPatch(from, to string, ts ...v1.Transform) v1.Patch {
+	return v1.Patch{
+		FromFieldPath: pointer.String(from),
+		ToFieldPath:   pointer.String(to),
+		Transforms:    ts,
+	}
+}
+
+func TestPatchTypeChecker(t *testing.T) {
+	snap := newTestSnapshot(t, testPatchesFiles())
+
+	type args struct {
+		comp *v1.Composition
+	}
+	type want struct {
+		errs []error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Patches between fields that exist and have compatible types should not return errors.",
+			args: args{
+				comp: testComposition("XNetwork", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					Patches: []v1.Patch{
+						testPatch("spec.region", "spec.forProvider.region"),
+						testPatch("metadata.uid", "spec.forProvider.domainName", v1.Transform{
+							Type:   v1.TransformTypeString,
+							String: &v1.StringTransform{Format: pointer.String("%s.acme.io")},
+						}),
+						testPatch("spec.claimRef.namespace", "metadata.labels[namespace]"),
+						testPatch("spec.region", "spec.forProvider.renewCertificate",
+							v1.Transform{
+								Type: v1.TransformTypeMap,
+								Map:  &v1.MapTransform{Pairs: map[string]string{"us-east-1": "true"}},
+							},
+							v1.Transform{
+								Type:    v1.TransformTypeConvert,
+								Convert: &v1.ConvertTransform{ToType: "bool"},
+							},
+						),
+						{
+							Type:          v1.PatchTypeToCompositeFieldPath,
+							FromFieldPath: pointer.String("spec.forProvider.region"),
+							ToFieldPath:   pointer.String("spec.region"),
+						},
+					},
+				}),
+			},
+			want: want{
+				errs: []error{},
+			},
+		},
+		"FromFieldPathNotFound": {
+			reason: "A fromFieldPath that does not exist in the composite resource should return an error.",
+			args: args{
+				comp: testComposition("XNetwork", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					Patches: []v1.Patch{
+						testPatch("spec.paramters.region", "spec.forProvider.region"),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "spec.paramters.region is not a field of acme.io/v1alpha1, Kind=XNetwork",
+						Name:     "spec.resources[0].patches[0].fromFieldPath",
+						Reason:   validator.ReasonFieldNotFound,
+					},
+				},
+			},
+		},
+		"ToFieldPathNotFound": {
+			reason: "A toFieldPath that does not exist in the composed resource should return an error.",
+			args: args{
+				comp: testComposition("XNetwork", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					Patches: []v1.Patch{
+						testPatch("spec.region", "spec.forProvider.regoin"),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "spec.forProvider.regoin is not a field of acm.aws.crossplane.io/v1alpha1, Kind=Certificate",
+						Name:     "spec.resources[0].patches[0].toFieldPath",
+						Reason:   validator.ReasonFieldNotFound,
+					},
+				},
+			},
+		},
+		"TypeMismatch": {
+			reason: "Patching a value to a field of an incompatible type should return an error.",
+			args: args{
+				comp: testComposition("XNetwork", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					Patches: []v1.Patch{
+						testPatch("spec.region", "spec.forProvider.renewCertificate"),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "cannot patch value of type string to spec.forProvider.renewCertificate of type boolean (acm.aws.crossplane.io/v1alpha1, Kind=Certificate)",
+						Name:     "spec.resources[0].patches[0].toFieldPath",
+						Reason:   validator.ReasonTypeMismatch,
+					},
+				},
+			},
+		},
+		"TransformInputMismatch": {
+			reason: "A transform that does not accept the type of its input should return an error.",
+			args: args{
+				comp: testComposition("XNetwork", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					Patches: []v1.Patch{
+						testPatch("spec.region", "spec.forProvider.region", v1.Transform{
+							Type: v1.TransformTypeMath,
+							Math: &v1.MathTransform{Multiply: pointer.Int64(2)},
+						}),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "math transform does not accept input of type string",
+						Name:     "spec.resources[0].patches[0].transforms[0].type",
+						Reason:   validator.ReasonTypeMismatch,
+					},
+				},
+			},
+		},
+		"MathIntegerInput": {
+			reason: "A math transform of an integer should output an integer.",
+			args: args{
+				comp: testComposition("XPool", nil, v1.ComposedTemplate{
+					Base: testPoolBase,
+					Patches: []v1.Patch{
+						testPatch("spec.size", "spec.size", v1.Transform{
+							Type: v1.TransformTypeMath,
+							Math: &v1.MathTransform{Multiply: pointer.Int64(2)},
+						}),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{},
+			},
+		},
+		"MathNumberInput": {
+			reason: "A math transform of a number should output a number, which cannot be patched to an integer field.",
+			args: args{
+				comp: testComposition("XPool", nil, v1.ComposedTemplate{
+					Base: testPoolBase,
+					Patches: []v1.Patch{
+						testPatch("spec.ratio", "spec.ratio", v1.Transform{
+							Type: v1.TransformTypeMath,
+							Math: &v1.MathTransform{Multiply: pointer.Int64(2)},
+						}),
+						testPatch("spec.ratio", "spec.size", v1.Transform{
+							Type: v1.TransformTypeMath,
+							Math: &v1.MathTransform{Multiply: pointer.Int64(2)},
+						}),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "cannot patch value of type number to spec.size of type integer (acme.io/v1alpha1, Kind=XPool)",
+						Name:     "spec.resources[0].patches[1].toFieldPath",
+						Reason:   validator.ReasonTypeMismatch,
+					},
+				},
+			},
+		},
+		"MapValues": {
+			reason: "A map transform should output the type of its values.",
+			args: args{
+				comp: testComposition("XPool", nil, v1.ComposedTemplate{
+					Base: testPoolBase,
+					Patches: []v1.Patch{
+						testPatch("spec.tier", "spec.enabled", v1.Transform{
+							Type: v1.TransformTypeMap,
+							Map:  &v1.MapTransform{Pairs: map[string]string{"gold": "true"}},
+						}),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "cannot patch value of type string to spec.enabled of type boolean (acme.io/v1alpha1, Kind=XPool)",
+						Name:     "spec.resources[0].patches[0].toFieldPath",
+						Reason:   validator.ReasonTypeMismatch,
+					},
+				},
+			},
+		},
+		"MapNoValues": {
+			reason: "A map transform without values outputs a value of unknown type, which should not be checked.",
+			args: args{
+				comp: testComposition("XPool", nil, v1.ComposedTemplate{
+					Base: testPoolBase,
+					Patches: []v1.Patch{
+						testPatch("spec.tier", "spec.size", v1.Transform{
+							Type: v1.TransformTypeMap,
+							Map:  &v1.MapTransform{},
+						}),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{},
+			},
+		},
+		"PatchSet": {
+			reason: "Patches in patch sets should be checked once against each resource that uses them.",
+			args: args{
+				comp: testComposition("XNetwork",
+					[]v1.PatchSet{{
+						Name:    "common",
+						Patches: []v1.Patch{testPatch("spec.region", "spec.forProvider.regoin")},
+					}},
+					v1.ComposedTemplate{
+						Base:    testCertificateBase,
+						Patches: []v1.Patch{{Type: v1.PatchTypePatchSet, PatchSetName: pointer.String("common")}},
+					},
+					v1.ComposedTemplate{
+						Base:    testCertificateBase,
+						Patches: []v1.Patch{{Type: v1.PatchTypePatchSet, PatchSetName: pointer.String("common")}},
+					},
+				),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "spec.forProvider.regoin is not a field of acm.aws.crossplane.io/v1alpha1, Kind=Certificate",
+						Name:     "spec.patchSets[0].patches[0].toFieldPath",
+						Reason:   validator.ReasonFieldNotFound,
+					},
+				},
+			},
+		},
+		"UnknownSchema": {
+			reason: "Patches to resources whose schema is not known should not be checked.",
+			args: args{
+				comp: testComposition("XNetwork", nil, v1.ComposedTemplate{
+					Base: testUnknownBase,
+					Patches: []v1.Patch{
+						testPatch("spec.region", "spec.anything"),
+					},
+				}),
+			},
+			want: want{
+				errs: []error{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs := NewPatchTypeChecker(snap).Check(tc.args.comp)
+
+			if diff := cmp.Diff(tc.want.errs, errs); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
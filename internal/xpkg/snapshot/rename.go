@@ -0,0 +1,483 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	fieldCombineVariables = "combine.variables"
+	fieldItems            = "items"
+	fieldPatches          = "patches"
+	fieldProperties       = "properties"
+	fieldRequired         = "required"
+	fieldSchema           = "schema.openAPIV3Schema"
+	fieldType             = "type"
+
+	errNotRenameable  = "the element at the supplied position cannot be renamed"
+	errInvalidNameFmt = "%q is not a valid name"
+)
+
+var (
+	kindNameRegex     = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+	fieldNameRegex    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	patchSetNameRegex = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_.]*$`)
+)
+
+// PrepareRename returns the range of the name at the supplied position in the
+// file at the supplied uri if it can be renamed. Nil is returned otherwise.
+func (s *Snapshot) PrepareRename(uri span.URI, pos protocol.Position) (*protocol.Range, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, rng, ok := s.renameableAt(uri, pos)
+	if !ok {
+		return nil, nil
+	}
+	return &rng, nil
+}
+
+// Rename returns the edits across the workspace required to rename the XRD
+// field, composite resource or claim kind, or patch set found at the supplied
+// position in the file at the supplied uri.
+func (s *Snapshot) Rename(uri span.URI, pos protocol.Position, name string) (*protocol.WorkspaceEdit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sym, _, ok := s.renameableAt(uri, pos)
+	if !ok {
+		return nil, errors.New(errNotRenameable)
+	}
+
+	edits := textEdits{}
+	switch sym.typ {
+	case symbolKind:
+		if !kindNameRegex.MatchString(name) {
+			return nil, fmt.Errorf(errInvalidNameFmt, name)
+		}
+		for _, loc := range append(s.declarations(sym), s.references(sym)...) {
+			edits.add(loc.URI, loc.Range, name)
+		}
+	case symbolPatchSet:
+		if !patchSetNameRegex.MatchString(name) {
+			return nil, fmt.Errorf(errInvalidNameFmt, name)
+		}
+		for _, loc := range append(s.declarations(sym), s.references(sym)...) {
+			edits.add(loc.URI, loc.Range, name)
+		}
+	case symbolField:
+		if !fieldNameRegex.MatchString(name) {
+			return nil, fmt.Errorf(errInvalidNameFmt, name)
+		}
+		s.fieldEdits(sym, name, edits)
+	}
+	for _, e := range edits {
+		sort.SliceStable(e, func(i, j int) bool {
+			return before(e[i].Range.Start, e[j].Range.Start)
+		})
+	}
+	return &protocol.WorkspaceEdit{Changes: edits}, nil
+}
+
+// renameableAt returns the symbol found at the supplied position in the file
+// at the supplied uri, along with the range of its name at the position.
+// Only symbols that are declared in the workspace can be renamed.
+func (s *Snapshot) renameableAt(uri span.URI, pos protocol.Position) (*symbol, protocol.Range, bool) { // nolint:gocyclo
+	n, scalar, key := s.nodeAt(uri, pos)
+	if scalar == nil {
+		return nil, protocol.Range{}, false
+	}
+	tok := scalar.GetToken()
+
+	if key {
+		sym := s.fieldKeySymbol(n, scalar)
+		if sym == nil {
+			return nil, protocol.Range{}, false
+		}
+		return sym, unquoted(tokenRange(tok), tok), true
+	}
+
+	if sym := symbolAt(n, scalar); sym != nil {
+		segs, err := fieldpath.Parse(fieldPath(scalar))
+		if err != nil {
+			return nil, protocol.Range{}, false
+		}
+		// kinds may only be renamed from kind fields, not apiVersions.
+		last := segs[len(segs)-1]
+		if sym.typ == symbolKind && last.Field != fieldKind {
+			return nil, protocol.Range{}, false
+		}
+		if len(s.declarations(sym)) == 0 {
+			return nil, protocol.Range{}, false
+		}
+		return sym, unquoted(tokenRange(tok), tok), true
+	}
+
+	return s.patchFieldSymbol(n, scalar, pos)
+}
+
+// fieldKeySymbol returns the XRD field symbol for the supplied mapping key,
+// which may be a property in the schema of an XRD or a field in an instance of
+// a composite resource or claim defined by an XRD in the workspace.
+func (s *Snapshot) fieldKeySymbol(n workspace.Node, key ast.Node) *symbol {
+	segs, err := fieldpath.Parse(fieldPath(key))
+	if err != nil {
+		return nil
+	}
+
+	var sym *symbol
+	if n.GetGVK().GroupKind() == xpextv1.CompositeResourceDefinitionGroupVersionKind.GroupKind() {
+		if len(segs) < 5 || !matchPath(segs[:5], "spec", "versions", "*", "schema", "openAPIV3Schema") {
+			return nil
+		}
+		path, ok := schemaFieldPath(segs[5:])
+		if !ok {
+			return nil
+		}
+		p, ok := paved(n)
+		if !ok {
+			return nil
+		}
+		group, _ := p.GetString(pathGroup)
+		kind, _ := p.GetString(pathNamesKind)
+		sym = &symbol{typ: symbolField, gk: schema.GroupKind{Group: group, Kind: kind}, path: path}
+	} else {
+		_, xr, _, ok := s.xrdFor(n.GetGVK().GroupKind())
+		if !ok {
+			return nil
+		}
+		sym = &symbol{typ: symbolField, gk: xr, path: segs}
+	}
+
+	// only fields within the spec of a composite resource may be renamed.
+	if len(sym.path) < 2 || sym.path[0].Field != "spec" || sym.path[len(sym.path)-1].Type != fieldpath.SegmentField {
+		return nil
+	}
+	if _, _, _, ok := s.xrdFor(sym.gk); !ok {
+		return nil
+	}
+	return sym
+}
+
+// patchFieldSymbol returns the XRD field symbol for the segment found at the
+// supplied position in a Composition patch field path that refers to the
+// composite resource.
+func (s *Snapshot) patchFieldSymbol(n workspace.Node, scalar ast.Node, pos protocol.Position) (*symbol, protocol.Range, bool) {
+	if n.GetGVK().GroupKind() != xpextv1.CompositionGroupVersionKind.GroupKind() {
+		return nil, protocol.Range{}, false
+	}
+	p, ok := paved(n)
+	if !ok {
+		return nil, protocol.Range{}, false
+	}
+	if _, ok := compositePaths(p)[fieldPath(scalar)]; !ok {
+		return nil, protocol.Range{}, false
+	}
+	vs, ok := canonicalPath(scalar)
+	if !ok {
+		return nil, protocol.Range{}, false
+	}
+
+	// find the segment at the supplied position.
+	for k := range vs {
+		rng := segmentRange(scalar, vs, k)
+		if pos.Character > rng.End.Character {
+			continue
+		}
+		path := copySegments(vs[:k+1])
+		if len(path) < 2 || path[0].Field != "spec" || path[k].Type != fieldpath.SegmentField {
+			return nil, protocol.Range{}, false
+		}
+		xr := gvkAt(p, fieldpath.Segments{fieldpath.Field("spec"), fieldpath.Field("compositeTypeRef")}).GroupKind()
+		if _, _, _, ok := s.xrdFor(xr); !ok {
+			return nil, protocol.Range{}, false
+		}
+		return &symbol{typ: symbolField, gk: xr, path: path}, rng, true
+	}
+	return nil, protocol.Range{}, false
+}
+
+// fieldEdits adds the edits required to rename the supplied field symbol to
+// the supplied name. The field is renamed in the schema of the XRD that
+// defines it, in instances of the composite resource and its claim, and in the
+// patches of Compositions for the composite resource.
+func (s *Snapshot) fieldEdits(sym *symbol, name string, edits textEdits) { // nolint:gocyclo
+	xrd, _, claim, ok := s.xrdFor(sym.gk)
+	if !ok {
+		return
+	}
+	old := sym.path[len(sym.path)-1].Field
+	xrdURI := protocol.URIFromSpanURI(span.URIFromPath(xrd.GetFileName()))
+
+	// rename the property in each version of the XRD schema, as well as any
+	// required entries for it.
+	p, ok := paved(xrd)
+	if !ok {
+		return
+	}
+	keys := mappingKeys(xrd.GetAST())
+	for i := 0; i < lenAt(p, pathVersions); i++ {
+		root := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathVersions, i), fieldSchema)
+		if key, ok := keys[root+schemaPath(sym.path)]; ok {
+			edits.add(xrdURI, unquoted(tokenRange(key.GetToken()), key.GetToken()), name)
+		}
+		required := fmt.Sprintf(fieldFmt, root+schemaPath(sym.path[:len(sym.path)-1]), fieldRequired)
+		for j := 0; j < lenAt(p, required); j++ {
+			path := fmt.Sprintf(indexFmt, required, j)
+			if v, _ := p.GetString(path); v != old {
+				continue
+			}
+			if rn, ok := nodeAtPath(xrd.GetAST(), path); ok {
+				edits.add(xrdURI, unquoted(tokenRange(rn.GetToken()), rn.GetToken()), name)
+			}
+		}
+	}
+
+	for _, n := range s.rootNodes() {
+		uri := protocol.URIFromSpanURI(span.URIFromPath(n.GetFileName()))
+		switch gk := n.GetGVK().GroupKind(); {
+		case gk == sym.gk || gk == claim:
+			// rename the field in instances of the composite resource and
+			// its claim.
+			for path, key := range mappingKeys(n.GetAST()) {
+				segs, err := fieldpath.Parse(path)
+				if err != nil || !matchField(segs, sym.path) {
+					continue
+				}
+				edits.add(uri, unquoted(tokenRange(key.GetToken()), key.GetToken()), name)
+			}
+		case gk == xpextv1.CompositionGroupVersionKind.GroupKind():
+			// rename the field in patches that refer to the composite
+			// resource.
+			cp, ok := paved(n)
+			if !ok {
+				continue
+			}
+			if gvkAt(cp, fieldpath.Segments{fieldpath.Field("spec"), fieldpath.Field("compositeTypeRef")}).GroupKind() != sym.gk {
+				continue
+			}
+			for path := range compositePaths(cp) {
+				scalar, ok := nodeAtPath(n.GetAST(), path)
+				if !ok {
+					continue
+				}
+				vs, ok := canonicalPath(scalar)
+				if !ok || len(vs) < len(sym.path) || !matchField(vs[:len(sym.path)], sym.path) {
+					continue
+				}
+				edits.add(uri, segmentRange(scalar, vs, len(sym.path)-1), name)
+			}
+		}
+	}
+}
+
+// xrdFor returns the XRD in the workspace that defines the supplied composite
+// resource or claim GroupKind, along with the GroupKinds of the composite
+// resource and claim that it defines.
+func (s *Snapshot) xrdFor(gk schema.GroupKind) (workspace.Node, schema.GroupKind, schema.GroupKind, bool) {
+	for _, n := range s.rootNodes() {
+		if n.GetGVK().GroupKind() != xpextv1.CompositeResourceDefinitionGroupVersionKind.GroupKind() {
+			continue
+		}
+		p, ok := paved(n)
+		if !ok {
+			continue
+		}
+		group, _ := p.GetString(pathGroup)
+		kind, _ := p.GetString(pathNamesKind)
+		claim, _ := p.GetString(pathClaimNamesKind)
+		if group != gk.Group || (gk.Kind != kind && (claim == "" || gk.Kind != claim)) {
+			continue
+		}
+		return n, schema.GroupKind{Group: group, Kind: kind}, schema.GroupKind{Group: group, Kind: claim}, true
+	}
+	return nil, schema.GroupKind{}, schema.GroupKind{}, false
+}
+
+// compositePaths returns the paths of the patch field paths within the
+// supplied Composition that refer to the composite resource.
+func compositePaths(p *fieldpath.Paved) map[string]struct{} {
+	lists := []string{}
+	for i := 0; i < lenAt(p, pathPatchSets); i++ {
+		lists = append(lists, fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathPatchSets, i), fieldPatches))
+	}
+	for i := 0; i < lenAt(p, pathResources); i++ {
+		lists = append(lists, fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), fieldPatches))
+	}
+
+	paths := map[string]struct{}{}
+	for _, l := range lists {
+		for j := 0; j < lenAt(p, l); j++ {
+			patch := fmt.Sprintf(indexFmt, l, j)
+			t, _ := p.GetString(fmt.Sprintf(fieldFmt, patch, fieldType))
+			switch xpextv1.PatchType(t) {
+			case "", xpextv1.PatchTypeFromCompositeFieldPath:
+				paths[fmt.Sprintf(fieldFmt, patch, fieldFromFieldPath)] = struct{}{}
+			case xpextv1.PatchTypeToCompositeFieldPath, xpextv1.PatchTypeCombineToComposite:
+				paths[fmt.Sprintf(fieldFmt, patch, fieldToFieldPath)] = struct{}{}
+			case xpextv1.PatchTypeCombineFromComposite:
+				vars := fmt.Sprintf(fieldFmt, patch, fieldCombineVariables)
+				for k := 0; k < lenAt(p, vars); k++ {
+					paths[fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, vars, k), fieldFromFieldPath)] = struct{}{}
+				}
+			case xpextv1.PatchTypePatchSet:
+			}
+		}
+	}
+	return paths
+}
+
+// schemaFieldPath converts the supplied path within an OpenAPI schema to the
+// path of the field it describes, e.g. properties.spec.properties.region is
+// converted to spec.region.
+func schemaFieldPath(segs fieldpath.Segments) (fieldpath.Segments, bool) {
+	path := fieldpath.Segments{}
+	for i := 0; i < len(segs); i++ {
+		switch {
+		case segs[i].Type != fieldpath.SegmentField:
+			return nil, false
+		case segs[i].Field == fieldProperties && i+1 < len(segs) && segs[i+1].Type == fieldpath.SegmentField:
+			path = append(path, fieldpath.Field(segs[i+1].Field))
+			i++
+		case segs[i].Field == fieldItems:
+			path = append(path, fieldpath.FieldOrIndex("0"))
+		default:
+			return nil, false
+		}
+	}
+	return path, len(path) > 0
+}
+
+// schemaPath converts the supplied field path to the path of the OpenAPI
+// schema that describes it. It is the inverse of schemaFieldPath.
+func schemaPath(segs fieldpath.Segments) string {
+	path := ""
+	for _, seg := range segs {
+		switch seg.Type {
+		case fieldpath.SegmentField:
+			path += fmt.Sprintf(".%s.%s", fieldProperties, seg.Field)
+		case fieldpath.SegmentIndex:
+			path += "." + fieldItems
+		}
+	}
+	return path
+}
+
+// matchField returns true if the supplied segments refer to the supplied field
+// path. Index segments in the field path match any index.
+func matchField(segs, field fieldpath.Segments) bool {
+	if len(segs) != len(field) {
+		return false
+	}
+	for i := range field {
+		if segs[i].Type != field[i].Type {
+			return false
+		}
+		if field[i].Type == fieldpath.SegmentField && segs[i].Field != field[i].Field {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalPath parses the field path value of the supplied scalar. Paths that
+// are not written in canonical form are skipped so that the offsets of their
+// segments can be computed.
+func canonicalPath(scalar ast.Node) (fieldpath.Segments, bool) {
+	val := scalarValue(scalar)
+	vs, err := fieldpath.Parse(val)
+	if err != nil || len(vs) == 0 || vs.String() != val {
+		return nil, false
+	}
+	return vs, true
+}
+
+// segmentRange returns the range of the kth segment of the supplied field path
+// value within the supplied scalar.
+func segmentRange(scalar ast.Node, vs fieldpath.Segments, k int) protocol.Range {
+	tok := scalar.GetToken()
+	rng := unquoted(tokenRange(tok), tok)
+	start := rng.Start.Character
+	if k > 0 {
+		start += uint32(len(vs[:k].String()) + 1)
+	}
+	end := start + uint32(len(vs[k:k+1].String()))
+	rng.Start.Character, rng.End.Character = start, end
+	return rng
+}
+
+// mappingKeys returns the keys of all mappings within the supplied node,
+// indexed by their path.
+func mappingKeys(n ast.Node) map[string]ast.Node {
+	v := &keyVisitor{keys: map[string]ast.Node{}}
+	ast.Walk(v, n)
+	return v.keys
+}
+
+// keyVisitor accumulates the mapping keys of the nodes it visits.
+type keyVisitor struct {
+	keys map[string]ast.Node
+}
+
+// Visit implements ast.Visitor.
+func (v *keyVisitor) Visit(n ast.Node) ast.Visitor {
+	if mv, ok := n.(*ast.MappingValueNode); ok && mv.Key != nil {
+		v.keys[fieldPath(mv.Key)] = mv.Key
+	}
+	return v
+}
+
+// unquoted returns the supplied token range without its surrounding quotes,
+// if the supplied token is quoted.
+func unquoted(rng protocol.Range, tok *token.Token) protocol.Range {
+	if tok == nil {
+		return rng
+	}
+	switch tok.Type { // nolint:exhaustive
+	case token.DoubleQuoteType, token.SingleQuoteType:
+		rng.Start.Character++
+		rng.End.Character--
+	}
+	return rng
+}
+
+// textEdits are the edits to apply to each document in a workspace.
+type textEdits map[string][]protocol.TextEdit
+
+// add adds an edit replacing the supplied range in the document at the
+// supplied uri with the supplied text. Duplicate edits are ignored.
+func (t textEdits) add(uri protocol.DocumentURI, rng protocol.Range, text string) {
+	for _, e := range t[string(uri)] {
+		if e.Range == rng {
+			return
+		}
+	}
+	t[string(uri)] = append(t[string(uri)], protocol.TextEdit{Range: rng, NewText: text})
+}
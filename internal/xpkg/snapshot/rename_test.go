@@ -0,0 +1,220 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func testEdits(name string, locs ...protocol.Location) map[string][]protocol.TextEdit {
+	edits := map[string][]protocol.TextEdit{}
+	for _, l := range locs {
+		edits[string(l.URI)] = append(edits[string(l.URI)], protocol.TextEdit{Range: l.Range, NewText: name})
+	}
+	return edits
+}
+
+func testRangeRef(line, start, end uint32) *protocol.Range {
+	rng := testRange(line, start, end)
+	return &rng
+}
+
+func TestPrepareRename(t *testing.T) {
+	type args struct {
+		file string
+		pos  protocol.Position
+	}
+	type want struct {
+		rng *protocol.Range
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClaimKind": {
+			reason: "A claim kind declared in the workspace should be renameable.",
+			args: args{
+				file: "/ws/examples/claim.yaml",
+				pos:  protocol.Position{Line: 1, Character: 8},
+			},
+			want: want{
+				rng: testRangeRef(1, 6, 13),
+			},
+		},
+		"PatchField": {
+			reason: "Only the segment of a patch field path under the cursor should be renameable.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 11, Character: 28},
+			},
+			want: want{
+				rng: testRangeRef(11, 26, 32),
+			},
+		},
+		"NotRenameable": {
+			reason: "Fields that are not declared by an XRD should not be renameable.",
+			args: args{
+				file: "/ws/xrd.yaml",
+				pos:  protocol.Position{Line: 4, Character: 3},
+			},
+			want: want{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, testNavigationFiles())
+			rng, err := snap.PrepareRename(span.URIFromPath(tc.args.file), tc.args.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want.rng, rng); diff != "" {
+				t.Errorf("\n%s\nPrepareRename(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	fieldEdits := testEdits("location",
+		testLocation("/ws/composition.yaml", 11, 26, 32),
+		testLocation("/ws/examples/claim.yaml", 5, 2, 8),
+		testLocation("/ws/xrd.yaml", 23, 14, 20),
+		testLocation("/ws/xrd.yaml", 31, 14, 20),
+	)
+
+	type args struct {
+		file string
+		pos  protocol.Position
+		name string
+	}
+	type want struct {
+		edit *protocol.WorkspaceEdit
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClaimKind": {
+			reason: "Renaming a claim kind should rename its declaration in the XRD and its instances.",
+			args: args{
+				file: "/ws/xrd.yaml",
+				pos:  protocol.Position{Line: 10, Character: 12},
+				name: "Subnet",
+			},
+			want: want{
+				edit: &protocol.WorkspaceEdit{
+					Changes: testEdits("Subnet",
+						testLocation("/ws/examples/claim.yaml", 1, 6, 13),
+						testLocation("/ws/xrd.yaml", 10, 10, 17),
+					),
+				},
+			},
+		},
+		"PatchSet": {
+			reason: "Renaming a patch set should rename its declaration and references.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 9, Character: 12},
+				name: "shared",
+			},
+			want: want{
+				edit: &protocol.WorkspaceEdit{
+					Changes: testEdits("shared",
+						testLocation("/ws/composition.yaml", 9, 10, 16),
+						testLocation("/ws/composition.yaml", 20, 20, 26),
+					),
+				},
+			},
+		},
+		"FieldFromXRD": {
+			reason: "Renaming a property in an XRD schema should rename it in the schema, required list, instances and patches.",
+			args: args{
+				file: "/ws/xrd.yaml",
+				pos:  protocol.Position{Line: 23, Character: 16},
+				name: "location",
+			},
+			want: want{
+				edit: &protocol.WorkspaceEdit{Changes: fieldEdits},
+			},
+		},
+		"FieldFromClaim": {
+			reason: "Renaming a field in a claim should rename the XRD property it is defined by.",
+			args: args{
+				file: "/ws/examples/claim.yaml",
+				pos:  protocol.Position{Line: 5, Character: 4},
+				name: "location",
+			},
+			want: want{
+				edit: &protocol.WorkspaceEdit{Changes: fieldEdits},
+			},
+		},
+		"FieldFromPatch": {
+			reason: "Renaming a segment of a patch field path should rename the XRD property it refers to.",
+			args: args{
+				file: "/ws/composition.yaml",
+				pos:  protocol.Position{Line: 11, Character: 28},
+				name: "location",
+			},
+			want: want{
+				edit: &protocol.WorkspaceEdit{Changes: fieldEdits},
+			},
+		},
+		"InvalidName": {
+			reason: "Renaming a field to an invalid name should return an error.",
+			args: args{
+				file: "/ws/xrd.yaml",
+				pos:  protocol.Position{Line: 23, Character: 16},
+				name: "bad name",
+			},
+			want: want{
+				err: fmt.Errorf(errInvalidNameFmt, "bad name"),
+			},
+		},
+		"NotRenameable": {
+			reason: "Renaming an element that cannot be renamed should return an error.",
+			args: args{
+				file: "/ws/xrd.yaml",
+				pos:  protocol.Position{Line: 4, Character: 3},
+				name: "status",
+			},
+			want: want{
+				err: errors.New(errNotRenameable),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, testNavigationFiles())
+			edit, err := snap.Rename(span.URIFromPath(tc.args.file), tc.args.pos, tc.args.name)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRename(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.edit, edit); diff != "" {
+				t.Errorf("\n%s\nRename(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/golang/tools/span"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	icomposite "github.com/crossplane/crossplane/controller/apiextensions/composite"
+	"github.com/crossplane/crossplane/xcrd"
+)
+
+const (
+	renderSeparator   = "---\n"
+	renderResourceFmt = "# %s\n"
+	renderErrorFmt    = "# error: %s\n"
+
+	errNoCompositionFmt   = "no Composition found in %s"
+	errExampleNotFoundFmt = "no example of %s found in %s"
+)
+
+// Render renders the resources composed by the first Composition in the file
+// at the supplied uri. The composite resource is taken from the examples in
+// the workspace, restricted to the file at the supplied example uri if one is
+// supplied. Examples of either the composite resource or its claim are used.
+// The rendered resources are returned as a YAML stream, with any errors
+// encountered rendering a resource included as comments.
+func (s *Snapshot) Render(ctx context.Context, uri, example span.URI) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nodes, err := s.compositions(uri)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf(errNoCompositionFmt, uri.Filename())
+	}
+	comp, err := compositionFrom(nodes[0].GetObject())
+	if err != nil {
+		return nil, err
+	}
+
+	xrGVK := schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind)
+	cp := composite.New(composite.WithGroupVersionKind(xrGVK))
+	if ex, ok := s.exampleFor(xrGVK, example); ok {
+		cp.SetUnstructuredContent(ex)
+	} else if example != "" {
+		return nil, fmt.Errorf(errExampleNotFoundFmt, xrGVK.Kind, example.Filename())
+	}
+	if cp.GetName() == "" {
+		cp.SetName(icomposite.PlaceholderName)
+	}
+	cp.SetUID(types.UID(icomposite.PlaceholderUID))
+
+	cfg := icomposite.NewConfiguratorChain(icomposite.NewAPINamingConfigurator(), icomposite.NewAPIConfigurator())
+	if err := cfg.Configure(ctx, cp, comp); err != nil {
+		return nil, err
+	}
+	cts, err := comp.Spec.ComposedTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	r := icomposite.NewAPIDryRunRenderer()
+	var buf bytes.Buffer
+	for i, t := range cts {
+		if i > 0 {
+			buf.WriteString(renderSeparator)
+		}
+		if t.Name != nil {
+			buf.WriteString(fmt.Sprintf(renderResourceFmt, *t.Name))
+		}
+		cd := composed.New()
+		if err := r.Render(ctx, cp, cd, t); err != nil {
+			buf.WriteString(fmt.Sprintf(renderErrorFmt, err))
+		}
+		b, err := yaml.Marshal(cd.UnstructuredContent())
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// exampleFor returns the content of an example of the composite resource with
+// the supplied GVK. If no example of the composite resource exists, the spec
+// of an example of its claim is used. Examples are restricted to the file at
+// the supplied uri if one is supplied.
+func (s *Snapshot) exampleFor(xr schema.GroupVersionKind, uri span.URI) (map[string]any, bool) {
+	gvks := []schema.GroupVersionKind{xr}
+	if claim, ok := s.wsview.XRClaimsRefs()[xr]; ok {
+		gvks = append(gvks, claim)
+	}
+	for _, gvk := range gvks {
+		examples := s.wsview.Examples()[gvk]
+		sort.SliceStable(examples, func(i, j int) bool {
+			return examples[i].GetFileName() < examples[j].GetFileName()
+		})
+		for _, n := range examples {
+			if uri != "" && n.GetFileName() != uri.Filename() {
+				continue
+			}
+			u, ok := n.GetObject().(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			content := u.DeepCopy().UnstructuredContent()
+			if gvk == xr {
+				return content, true
+			}
+			// claims share the spec of their composite resource.
+			xru := composite.New(composite.WithGroupVersionKind(xr))
+			xru.SetName(u.GetName())
+			xru.SetLabels(map[string]string{
+				xcrd.LabelKeyClaimName:      u.GetName(),
+				xcrd.LabelKeyClaimNamespace: u.GetNamespace(),
+			})
+			if spec, ok := content[fieldSpec]; ok {
+				xru.Object[fieldSpec] = spec
+			}
+			return xru.UnstructuredContent(), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var testRenderComp = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xnetworks.acme.io
+spec:
+  compositeTypeRef:
+    apiVersion: acme.io/v1alpha1
+    kind: XNetwork
+  resources:
+  - name: vpc
+    base:
+      apiVersion: ec2.aws.crossplane.io/v1beta1
+      kind: VPC
+      spec:
+        forProvider:
+          cidrBlock: 10.0.0.0/16
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+`)
+
+var testRendered = `# vpc
+apiVersion: ec2.aws.crossplane.io/v1beta1
+kind: VPC
+metadata:
+  annotations:
+    crossplane.io/composition-resource-name: vpc
+  generateName: example-
+  labels:
+    crossplane.io/claim-name: example
+    crossplane.io/claim-namespace: ""
+    crossplane.io/composite: example
+  ownerReferences:
+  - apiVersion: acme.io/v1alpha1
+    controller: true
+    kind: XNetwork
+    name: example
+    uid: placeholder-uid
+spec:
+  forProvider:
+    cidrBlock: 10.0.0.0/16
+    region: us-west-2
+`
+
+func TestRender(t *testing.T) {
+	snap := newTestSnapshot(t, map[string][]byte{
+		"/ws/xrd.yaml":            testXRD,
+		"/ws/composition.yaml":    testRenderComp,
+		"/ws/examples/claim.yaml": testClaim,
+	})
+
+	type args struct {
+		file    string
+		example string
+	}
+	type want struct {
+		out string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Claim": {
+			reason: "Resources should be rendered from the spec of an example of the composite resource's claim.",
+			args: args{
+				file: "/ws/composition.yaml",
+			},
+			want: want{
+				out: testRendered,
+			},
+		},
+		"Example": {
+			reason: "Resources should be rendered from the example in the supplied file.",
+			args: args{
+				file:    "/ws/composition.yaml",
+				example: "/ws/examples/claim.yaml",
+			},
+			want: want{
+				out: testRendered,
+			},
+		},
+		"ExampleNotFound": {
+			reason: "Rendering should fail if the supplied file contains no example of the composite resource.",
+			args: args{
+				file:    "/ws/composition.yaml",
+				example: "/ws/xrd.yaml",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"NotComposition": {
+			reason: "Rendering should fail if the file contains no Composition.",
+			args: args{
+				file: "/ws/xrd.yaml",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var example span.URI
+			if tc.args.example != "" {
+				example = span.URIFromPath(tc.args.example)
+			}
+			out, err := snap.Render(context.Background(), span.URIFromPath(tc.args.file), example)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, string(out)); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
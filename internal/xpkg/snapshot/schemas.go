@@ -0,0 +1,166 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	xpextv1beta1 "github.com/crossplane/crossplane/apis/apiextensions/v1beta1"
+)
+
+const (
+	// wsPackage is the package name used for types defined in the workspace
+	// when the workspace does not have a meta file.
+	wsPackage = "workspace"
+)
+
+// A TypeDefinition is the OpenAPI schema for a GVK along with details about
+// where it was defined.
+type TypeDefinition struct {
+	// Package is the name of the package that defined the type.
+	Package string
+	// Schema is the OpenAPI schema for the type.
+	Schema *spec.Schema
+	// Object is the CRD or XRD that defined the type.
+	Object runtime.Object
+}
+
+// definitionsForObj returns a mapping of GVK -> TypeDefinition for the given
+// runtime.Object. Objects that do not define types return an empty mapping.
+func definitionsForObj(o runtime.Object, pkg string) map[schema.GroupVersionKind]*TypeDefinition { // nolint:gocyclo
+	defs := make(map[schema.GroupVersionKind]*TypeDefinition)
+
+	add := func(gvk schema.GroupVersionKind, s *spec.Schema) {
+		defs[gvk] = &TypeDefinition{
+			Package: pkg,
+			Schema:  s,
+			Object:  o,
+		}
+	}
+
+	switch rd := o.(type) {
+	case *extv1beta1.CustomResourceDefinition:
+		internal := &apiextensions.CustomResourceDefinition{}
+		if err := extv1beta1.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(rd, internal, nil); err != nil {
+			return defs
+		}
+		if internal.Spec.Validation != nil {
+			_, s, err := validation.NewSchemaValidator(internal.Spec.Validation)
+			if err != nil {
+				return defs
+			}
+			for _, v := range internal.Spec.Versions {
+				add(gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind), s)
+			}
+			return defs
+		}
+		for _, v := range internal.Spec.Versions {
+			_, s, err := validation.NewSchemaValidator(v.Schema)
+			if err != nil {
+				continue
+			}
+			add(gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind), s)
+		}
+	case *extv1.CustomResourceDefinition:
+		for _, v := range rd.Spec.Versions {
+			if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			_, s, err := newV1SchemaValidator(*v.Schema.OpenAPIV3Schema)
+			if err != nil {
+				continue
+			}
+			add(gvk(rd.Spec.Group, v.Name, rd.Spec.Names.Kind), s)
+		}
+	case *xpextv1beta1.CompositeResourceDefinition:
+		for _, v := range rd.Spec.Versions {
+			if v.Schema == nil {
+				continue
+			}
+			s, err := xrdSchema(v.Schema.OpenAPIV3Schema)
+			if err != nil {
+				continue
+			}
+			if rd.Spec.ClaimNames != nil {
+				add(gvk(rd.Spec.Group, v.Name, rd.Spec.ClaimNames.Kind), s)
+			}
+			add(gvk(rd.Spec.Group, v.Name, rd.Spec.Names.Kind), s)
+		}
+	case *xpextv1.CompositeResourceDefinition:
+		for _, v := range rd.Spec.Versions {
+			if v.Schema == nil {
+				continue
+			}
+			s, err := xrdSchema(v.Schema.OpenAPIV3Schema)
+			if err != nil {
+				continue
+			}
+			if rd.Spec.ClaimNames != nil {
+				add(gvk(rd.Spec.Group, v.Name, rd.Spec.ClaimNames.Kind), s)
+			}
+			add(gvk(rd.Spec.Group, v.Name, rd.Spec.Names.Kind), s)
+		}
+	}
+
+	return defs
+}
+
+// xrdSchema builds the full OpenAPI schema for an XRD version schema.
+func xrdSchema(raw runtime.RawExtension) (*spec.Schema, error) {
+	props, err := buildSchema(raw)
+	if err != nil {
+		return nil, err
+	}
+	_, s, err := newV1SchemaValidator(*props)
+	return s, err
+}
+
+// schemaForPath walks the supplied schema, returning the sub-schema found at
+// the supplied path. Nil is returned if the path does not exist in the schema.
+func schemaForPath(s *spec.Schema, path fieldpath.Segments) *spec.Schema {
+	curr := s
+	for _, seg := range path {
+		if curr == nil {
+			return nil
+		}
+		switch seg.Type {
+		case fieldpath.SegmentIndex:
+			if curr.Items == nil {
+				return nil
+			}
+			curr = curr.Items.Schema
+		case fieldpath.SegmentField:
+			if prop, ok := curr.Properties[seg.Field]; ok {
+				curr = &prop
+				continue
+			}
+			if curr.AdditionalProperties != nil && curr.AdditionalProperties.Schema != nil {
+				curr = curr.AdditionalProperties.Schema
+				continue
+			}
+			return nil
+		}
+	}
+	return curr
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -36,10 +37,10 @@ import (
 	apimachyaml "k8s.io/apimachinery/pkg/util/yaml"
 	verrors "k8s.io/kube-openapi/pkg/validation/errors"
 
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
-	"github.com/goccy/go-yaml"
-	"github.com/goccy/go-yaml/token"
 	"github.com/golang/tools/lsp/protocol"
 	"github.com/golang/tools/span"
 
@@ -66,6 +67,7 @@ const (
 type DepManager interface {
 	View(context.Context, []v1beta1.Dependency) (*manager.View, error)
 	Versions(context.Context, v1beta1.Dependency) ([]string, error)
+	Packages(context.Context) ([]*mxpkg.ParsedPackage, error)
 	Watch() <-chan cache.Event
 }
 
@@ -90,7 +92,17 @@ type Snapshot struct {
 	// validators includes validators for both the workspace as well as
 	// the external dependencies defined in the crossplane.yaml.
 	validators map[schema.GroupVersionKind]validator.Validator
-	wsview     *workspace.View
+	// definitions includes the type definitions for both the workspace as
+	// well as the external dependencies defined in the crossplane.yaml.
+	definitions map[schema.GroupVersionKind]*TypeDefinition
+	wsview      *workspace.View
+
+	// dmu guards diags, which may be populated while holding a read lock on
+	// the snapshot.
+	dmu sync.Mutex
+	// diags caches the diagnostics for each file in the workspace. Entries
+	// are invalidated when the file, or a file it depends on, changes.
+	diags map[span.URI][]protocol.Diagnostic
 }
 
 // Factory is used to "stamp out" Snapshots while allowing
@@ -145,10 +157,12 @@ func (f *Factory) New(opts ...Option) (*Snapshot, error) {
 	s := &Snapshot{
 		// log is not set to a default so that we can share the logger consistently
 		// with the corresponding subsystems.
-		log:        f.log,
-		objScheme:  f.objScheme,
-		metaScheme: f.metaScheme,
-		validators: make(map[schema.GroupVersionKind]validator.Validator),
+		log:         f.log,
+		objScheme:   f.objScheme,
+		metaScheme:  f.metaScheme,
+		validators:  make(map[schema.GroupVersionKind]validator.Validator),
+		definitions: make(map[schema.GroupVersionKind]*TypeDefinition),
+		diags:       make(map[span.URI][]protocol.Diagnostic),
 	}
 
 	// use the manager instance from the Factory
@@ -202,16 +216,8 @@ func (s *Snapshot) init() error {
 
 		// add external dependency validators to snapshot validators
 		for _, pkg := range extView.Packages() {
-
 			for _, o := range pkg.Objects() {
-				validators, err := ValidatorsForObj(o, s)
-				if err != nil {
-					// skip adding the validator
-					continue
-				}
-				for gvk, v := range validators {
-					s.validators[gvk] = v
-				}
+				s.loadObj(o, pkg.Name())
 			}
 		}
 
@@ -260,19 +266,94 @@ func (s *Snapshot) Validator(gvk schema.GroupVersionKind) validator.Validator {
 	return s.validators[gvk]
 }
 
+// TypeDefinition returns the TypeDefinition corresponding to the provided GVK
+// within the Snapshot, if one exists. Nil otherwise.
+func (s *Snapshot) TypeDefinition(gvk schema.GroupVersionKind) *TypeDefinition {
+	return s.definitions[gvk]
+}
+
 // Package returns the ParsedPackage corresponding to the supplied package name
 // as defined in the crossplane.yaml, if one exists. Nil otherwise.
 func (s *Snapshot) Package(name string) *mxpkg.ParsedPackage {
 	return s.packages[name]
 }
 
+// Dependencies returns the dependencies declared in the workspace's
+// crossplane.yaml. Nil is returned if the workspace does not have a
+// crossplane.yaml.
+func (s *Snapshot) Dependencies() ([]v1beta1.Dependency, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	meta := s.wsview.Meta()
+	if meta == nil {
+		return nil, nil
+	}
+	return meta.DependsOn()
+}
+
 // ReParseFile re-parses the file at the given path. This is only useful in
 // cases where our snapshot representation has changed prior to the given file
-// being saved.
-func (s *Snapshot) ReParseFile(path string) error {
+// being saved. The URIs of the files whose diagnostics were invalidated by the
+// change are returned.
+func (s *Snapshot) ReParseFile(path string) ([]span.URI, error) {
+	return s.refresh(path, func() error {
+		return s.wsview.ParseFile(path)
+	})
+}
+
+// LoadFile loads the file at the given path from the filesystem, replacing
+// any in-memory content for it. The URIs of the files whose diagnostics were
+// invalidated by the change are returned.
+func (s *Snapshot) LoadFile(path string) ([]span.URI, error) {
+	return s.refresh(path, func() error {
+		return s.w.LoadFile(path)
+	})
+}
+
+// RemoveFile removes the file at the given path from the snapshot. The URIs of
+// the remaining files whose diagnostics were invalidated by the removal are
+// returned.
+func (s *Snapshot) RemoveFile(path string) ([]span.URI, error) {
+	uris, err := s.refresh(path, func() error {
+		s.w.RemoveFile(path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uris[1:], nil
+}
+
+// refresh applies the supplied update to the file at the given path, replaces
+// the validators it defines, and invalidates the cached diagnostics for the
+// file and its dependants. Dependants are determined using the kinds defined
+// in the file both before and after the update, such that instances of a kind
+// that is removed are revalidated.
+func (s *Snapshot) refresh(path string, update func() error) ([]span.URI, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.wsview.ParseFile(path)
+
+	uri := span.URIFromPath(path)
+	kinds := s.definedKinds(uri)
+	if err := update(); err != nil {
+		return nil, err
+	}
+	s.unloadKinds(kinds)
+	for gk := range s.definedKinds(uri) {
+		kinds[gk] = struct{}{}
+	}
+
+	if d, ok := s.wsview.FileDetails()[uri]; ok {
+		s.loadBody(d.Body, s.wsPackageName())
+	}
+
+	uris := append([]span.URI{uri}, s.dependants(uri, kinds)...)
+	s.dmu.Lock()
+	defer s.dmu.Unlock()
+	for _, u := range uris {
+		delete(s.diags, u)
+	}
+	return uris, nil
 }
 
 // UpdateContent updates the current in-memory content representation for the
@@ -318,8 +399,10 @@ func (s *Snapshot) updateChanges(_ context.Context, uri span.URI, changes []prot
 			Content:   content,
 		}
 
+		// a change without a range replaces the full content of the file.
 		if c.Range == nil {
-			return nil, errors.New(errInvalidRange)
+			content = []byte(c.Text)
+			continue
 		}
 
 		spn, err := m.RangeSpan(*c.Range)
@@ -348,23 +431,189 @@ func (s *Snapshot) updateChanges(_ context.Context, uri span.URI, changes []prot
 	return content, nil
 }
 
+// definedKinds returns the kinds defined by the XRDs and CRDs in the file at
+// the supplied uri.
+func (s *Snapshot) definedKinds(uri span.URI) map[schema.GroupKind]struct{} {
+	kinds := map[schema.GroupKind]struct{}{}
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return kinds
+	}
+	for id := range details.NodeIDs {
+		n, ok := s.wsview.Nodes()[id]
+		if !ok {
+			continue
+		}
+		gk := n.GetGVK().GroupKind()
+		if gk != xpextv1.CompositeResourceDefinitionGroupVersionKind.GroupKind() && gk != crdGroupKind {
+			continue
+		}
+		p, ok := paved(n)
+		if !ok {
+			continue
+		}
+		group, _ := p.GetString(pathGroup)
+		for _, path := range []string{pathNamesKind, pathClaimNamesKind} {
+			if kind, _ := p.GetString(path); kind != "" {
+				kinds[schema.GroupKind{Group: group, Kind: kind}] = struct{}{}
+			}
+		}
+	}
+	return kinds
+}
+
+// dependants returns the files, other than the file at the supplied uri, that
+// contain instances of the supplied kinds or Compositions that compose or
+// reference them.
+func (s *Snapshot) dependants(uri span.URI, kinds map[schema.GroupKind]struct{}) []span.URI {
+	if len(kinds) == 0 {
+		return nil
+	}
+	uris := []span.URI{}
+	for u, details := range s.wsview.FileDetails() {
+		if u == uri {
+			continue
+		}
+		for id := range details.NodeIDs {
+			n, ok := s.wsview.Nodes()[id]
+			if ok && dependsOn(n, kinds) {
+				uris = append(uris, u)
+				break
+			}
+		}
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+	return uris
+}
+
+// dependsOn returns true if the supplied node is an instance of one of the
+// supplied kinds, or is a Composition whose composite type or composed
+// resources are of one of the supplied kinds.
+func dependsOn(n workspace.Node, kinds map[schema.GroupKind]struct{}) bool {
+	if _, ok := kinds[n.GetGVK().GroupKind()]; ok {
+		return true
+	}
+	if n.GetGVK().GroupKind() != xpextv1.CompositionGroupVersionKind.GroupKind() {
+		return false
+	}
+	p, ok := paved(n)
+	if !ok {
+		return false
+	}
+	refs := []fieldpath.Segments{{fieldpath.Field("spec"), fieldpath.Field("compositeTypeRef")}}
+	for i := 0; i < lenAt(p, pathResources); i++ {
+		refs = append(refs, fieldpath.Segments{fieldpath.Field("spec"), fieldpath.Field("resources"), fieldpath.FieldOrIndex(fmt.Sprint(i)), fieldpath.Field("base")})
+	}
+	for _, r := range refs {
+		if _, ok := kinds[gvkAt(p, r).GroupKind()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // loadWSValidators processes the details from the parsed workspace, extracting
 // the corresponding validators and applying them to the workspace.
-func (s *Snapshot) loadWSValidators() error { // nolint:gocyclo
+func (s *Snapshot) loadWSValidators() error {
+	pkg := s.wsPackageName()
 	for _, d := range s.wsview.FileDetails() {
-		validators, err := s.validatorsFromBytes(d.Body)
+		s.loadBody(d.Body, pkg)
+	}
+	return nil
+}
+
+// wsPackageName returns the name of the package defined by the workspace.
+func (s *Snapshot) wsPackageName() string {
+	if m := s.wsview.Meta(); m != nil && m.Name() != "" {
+		return m.Name()
+	}
+	return wsPackage
+}
+
+// unloadKinds removes the validators and type definitions for the supplied
+// kinds from the snapshot. Any of the kinds that are also defined by a
+// dependency are restored from it.
+func (s *Snapshot) unloadKinds(kinds map[schema.GroupKind]struct{}) {
+	if len(kinds) == 0 {
+		return
+	}
+	for gvk := range s.validators {
+		if _, ok := kinds[gvk.GroupKind()]; ok {
+			delete(s.validators, gvk)
+		}
+	}
+	for gvk := range s.definitions {
+		if _, ok := kinds[gvk.GroupKind()]; ok {
+			delete(s.definitions, gvk)
+		}
+	}
+	for _, pkg := range s.packages {
+		for _, o := range pkg.Objects() {
+			for gvk := range definitionsForObj(o, pkg.Name()) {
+				if _, ok := kinds[gvk.GroupKind()]; ok {
+					s.loadObj(o, pkg.Name())
+					break
+				}
+			}
+		}
+	}
+}
+
+// loadBody adds the validators and type definitions for the objects in the
+// supplied file body, defined by the supplied package, to the snapshot.
+func (s *Snapshot) loadBody(b []byte, pkg string) {
+	objs, err := s.objsFromBytes(b)
+	if err != nil {
+		return
+	}
+	for _, o := range objs {
+		s.loadObj(o, pkg)
+	}
+}
+
+// loadObj adds the validators and type definitions for the supplied object,
+// defined by the supplied package, to the snapshot.
+func (s *Snapshot) loadObj(o runtime.Object, pkg string) {
+	validators, err := ValidatorsForObj(o, s)
+	if err != nil {
+		// skip adding the validator
+		return
+	}
+	for gvk, v := range validators {
+		s.validators[gvk] = v
+	}
+	for gvk, d := range definitionsForObj(o, pkg) {
+		s.definitions[gvk] = d
+	}
+}
+
+func (s *Snapshot) validatorsFromBytes(b []byte) (map[schema.GroupVersionKind]validator.Validator, error) {
+	result := map[schema.GroupVersionKind]validator.Validator{}
+
+	objs, err := s.objsFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range objs {
+		validators, err := ValidatorsForObj(o, s)
 		if err != nil {
+			// skip YAML document if we cannot acquire validators for object
 			continue
 		}
+
 		for gvk, v := range validators {
-			s.validators[gvk] = v
+			result[gvk] = v
 		}
 	}
-	return nil
+
+	return result, nil
 }
 
-func (s *Snapshot) validatorsFromBytes(b []byte) (map[schema.GroupVersionKind]validator.Validator, error) {
-	result := map[schema.GroupVersionKind]validator.Validator{}
+// objsFromBytes decodes the YAML documents in the supplied bytes into
+// runtime.Objects, skipping any documents that are not known types.
+func (s *Snapshot) objsFromBytes(b []byte) ([]runtime.Object, error) {
+	objs := []runtime.Object{}
 
 	yr := apimachyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
 	do := json.NewSerializerWithOptions(json.DefaultMetaFactory, s.objScheme, s.objScheme, json.SerializerOptions{Yaml: true})
@@ -391,18 +640,23 @@ func (s *Snapshot) validatorsFromBytes(b []byte) (map[schema.GroupVersionKind]va
 			}
 		}
 
-		validators, err := ValidatorsForObj(o, s)
-		if err != nil {
-			// skip YAML document if we cannot acquire validators for object
-			continue
-		}
-
-		for gvk, v := range validators {
-			result[gvk] = v
-		}
+		objs = append(objs, o)
 	}
 
-	return result, nil
+	return objs, nil
+}
+
+// Files returns the URIs of all files in the Snapshot.
+func (s *Snapshot) Files() []span.URI {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	uris := make([]span.URI, 0, len(s.wsview.FileDetails()))
+	for uri := range s.wsview.FileDetails() {
+		uris = append(uris, uri)
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+	return uris
 }
 
 // ValidateAllFiles performs validations on all files in Snapshot.
@@ -428,13 +682,41 @@ func (s *Snapshot) ValidateMeta() (span.URI, []protocol.Diagnostic, error) {
 	return uri, diags, nil
 }
 
+// Diagnostics returns the diagnostics for the file at the supplied uri. Cached
+// diagnostics are returned unless they have been invalidated by a change to
+// the file or a file it depends on.
+func (s *Snapshot) Diagnostics(uri span.URI) ([]protocol.Diagnostic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.dmu.Lock()
+	diags, ok := s.diags[uri]
+	s.dmu.Unlock()
+	if ok {
+		return diags, nil
+	}
+
+	diags, err := s.validate(uri)
+	if err != nil {
+		return nil, err
+	}
+	s.dmu.Lock()
+	defer s.dmu.Unlock()
+	s.diags[uri] = diags
+	return diags, nil
+}
+
 // Validate performs validation on all filtered nodes and returns diagnostics
 // for any validation errors encountered.
-// TODO(hasheddan): consider decoupling forming diagnostics from getting
-// validation errors.
-func (s *Snapshot) Validate(uri span.URI) ([]protocol.Diagnostic, error) { // nolint:gocyclo
+func (s *Snapshot) Validate(uri span.URI) ([]protocol.Diagnostic, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.validate(uri)
+}
+
+// validate performs validation on the file at the supplied uri. Callers must
+// hold a lock on the snapshot.
+func (s *Snapshot) validate(uri span.URI) ([]protocol.Diagnostic, error) {
 	diags := []protocol.Diagnostic{}
 	details, ok := s.wsview.FileDetails()[uri]
 	if !ok {
@@ -446,119 +728,120 @@ func (s *Snapshot) Validate(uri span.URI) ([]protocol.Diagnostic, error) { // no
 		if !ok {
 			return nil, errors.New(errInvalidNodeID)
 		}
-		gvk := n.GetGVK()
-		v, ok := s.validators[gvk]
-		if !ok {
-			// we couldn't find a validator for the given GVK, surface a warning
-			dneResult := &validate.Result{
-				Errors: gvkDNEWarning(gvk, "apiVersion"),
-			}
+		diags = append(diags, validationDiagnostics(s.validateNode(n), n.GetAST(), n.GetGVK())...)
+	}
+	return diags, nil
+}
 
-			diags = append(diags, validationDiagnostics(dneResult, n.GetAST(), n.GetGVK())...)
-			continue
+// validateNode validates the supplied node against the validator for its GVK.
+// A warning is returned if no validator exists for the GVK.
+func (s *Snapshot) validateNode(n workspace.Node) *validate.Result {
+	v, ok := s.validators[n.GetGVK()]
+	if !ok {
+		// we couldn't find a validator for the given GVK, surface a warning
+		return &validate.Result{
+			Errors: gvkDNEWarning(n.GetGVK(), apiVersionField),
 		}
-
-		diags = append(diags, validationDiagnostics(v.Validate(n.GetObject()), n.GetAST(), n.GetGVK())...)
 	}
-	return diags, nil
+	return v.Validate(n.GetObject())
 }
 
 // validationDiagnostics generates language server diagnostics from validation
 // errors.
-// TODO(@tnthornton) this function is getting pretty complex. We should work
-// towards breaking it up.
-func validationDiagnostics(res *validate.Result, n ast.Node, gvk schema.GroupVersionKind) []protocol.Diagnostic { // nolint:gocyclo
+func validationDiagnostics(res *validate.Result, n ast.Node, gvk schema.GroupVersionKind) []protocol.Diagnostic {
 	diags := []protocol.Diagnostic{}
+	for _, e := range validationErrors(res, gvk) {
+		// TODO(hasheddan): a general error should be surfaced if we
+		// cannot determine the location in the document causing the
+		// error.
+		node, ok := errorNode(e, n)
+		if !ok {
+			continue
+		}
+		diags = append(diags, newDiagnostic(e, node))
+	}
+	return diags
+}
+
+// newDiagnostic returns a diagnostic for the supplied error, surfaced at the
+// supplied node.
+func newDiagnostic(e *verror, node ast.Node) protocol.Diagnostic {
+	// handle different types of diagnostic notifications
+	var sev protocol.DiagnosticSeverity
+	switch c := e.code; {
+	case c == validator.WarningTypeCode:
+		sev = protocol.SeverityWarning
+	case c == validator.ErrorTypeCode:
+		sev = protocol.SeverityError
+	case c >= 422:
+		sev = protocol.SeverityError
+	}
+
+	diag := protocol.Diagnostic{
+		Range:    tokenRange(node.GetToken()),
+		Message:  e.Error(),
+		Severity: sev,
+		Source:   serverName,
+	}
+	if e.reason != "" {
+		diag.Code = e.reason
+	}
+	return diag
+}
+
+// validationErrors normalizes the errors in the supplied validation result.
+// Errors of types we weren't expecting are skipped.
+func validationErrors(res *validate.Result, gvk schema.GroupVersionKind) []*verror {
+	errs := []*verror{}
 	for _, err := range res.Errors {
-		var e *verror
 		switch et := err.(type) { //nolint:errorlint
 		case *verrors.Validation:
-			e = &verror{
+			errs = append(errs, &verror{
 				code:    et.Code(),
 				message: fmt.Sprintf("%s (%s)", et.Error(), gvk),
 				name:    et.Name,
-			}
+			})
 		case *validator.Validation:
-			e = &verror{
+			errs = append(errs, &verror{
 				code:    et.Code(),
 				message: et.Error(),
 				name:    et.Name,
-			}
-		default:
-			// found an error type we weren't expecting
-			continue
+				reason:  et.Reason,
+			})
 		}
+	}
+	return errs
+}
 
-		// TODO(hasheddan): handle the case where error occurs and we
-		// don't have a valid path.
-		if len(e.name) > 0 && e.name != "." {
-			errPath := e.name
-			if e.code == verrors.RequiredFailCode || e.code == validator.ErrorTypeCode {
-				idx := strings.LastIndex(e.name, ".")
-				if idx != 0 {
-					errPath = e.name[:idx]
-				}
-			}
-			// TODO(hasheddan): a general error should be surfaced if we
-			// cannot determine the location in the document causing the
-			// error.
-			path, err := yaml.PathString("$." + errPath)
-			if err != nil {
-				continue
-			}
-			node, err := path.FilterNode(n)
-			if err != nil {
-				continue
-			}
-			if node == nil {
-				continue
-			}
-			tok := node.GetToken()
-			if tok != nil {
-				startCh, endCh := tok.Position.Column-1, 0
-
-				// end character can be unmatched if we have doublequotes
-				switch tok.Type { // nolint:exhaustive
-				case token.DoubleQuoteType:
-					endCh = tok.Position.Column + len(tok.Value) + 1
-				default:
-					endCh = tok.Position.Column + len(tok.Value) - 1
-				}
-
-				// handle different types of diagnostic notifications
-				var sev protocol.DiagnosticSeverity
-				switch c := e.code; {
-				case c == validator.WarningTypeCode:
-					sev = protocol.SeverityWarning
-				case c == validator.ErrorTypeCode:
-					sev = protocol.SeverityError
-				case c >= 422:
-					sev = protocol.SeverityError
-				}
-
-				// TODO(hasheddan): token position reflects file line
-				// and column by NOT being zero-indexed, but VSCode
-				// interprets ranges with zero-indexing. We should
-				// develop a more robust solution for this conversion.
-				diags = append(diags, protocol.Diagnostic{
-					Range: protocol.Range{
-						Start: protocol.Position{
-							Line:      uint32(tok.Position.Line - 1),
-							Character: uint32(startCh),
-						},
-						End: protocol.Position{
-							Line:      uint32(tok.Position.Line - 1),
-							Character: uint32(endCh),
-						},
-					},
-					Message:  e.Error(),
-					Severity: sev,
-					Source:   serverName,
-				})
-			}
+// errorPath returns the path within a document at which the supplied error
+// should be surfaced. Errors for missing fields are surfaced on the parent of
+// the missing field.
+func errorPath(e *verror) (string, bool) {
+	// TODO(hasheddan): handle the case where error occurs and we
+	// don't have a valid path.
+	if len(e.name) == 0 || e.name == "." {
+		return "", false
+	}
+	if e.code == verrors.RequiredFailCode || e.code == validator.ErrorTypeCode {
+		if idx := strings.LastIndex(e.name, "."); idx > 0 {
+			return e.name[:idx], true
 		}
 	}
-	return diags
+	return e.name, true
+}
+
+// errorNode returns the node within the supplied document at which the
+// supplied error should be surfaced.
+func errorNode(e *verror, n ast.Node) (ast.Node, bool) {
+	path, ok := errorPath(e)
+	if !ok {
+		return nil, false
+	}
+	node, ok := nodeAtPath(n, path)
+	if !ok || node.GetToken() == nil {
+		return nil, false
+	}
+	return node, true
 }
 
 // verror normalizes the different validation error types that we work with.
@@ -566,6 +849,7 @@ type verror struct {
 	code    int32
 	message string
 	name    string
+	reason  string
 }
 
 func (e *verror) Error() string {
@@ -15,11 +15,15 @@
 package snapshot
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"testing"
 
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/spf13/afero"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -30,6 +34,7 @@ import (
 
 	"github.com/upbound/up/internal/xpkg/dep/cache"
 	"github.com/upbound/up/internal/xpkg/dep/manager"
+	mxpkg "github.com/upbound/up/internal/xpkg/dep/marshaler/xpkg"
 	"github.com/upbound/up/internal/xpkg/workspace"
 )
 
@@ -114,17 +119,232 @@ func TestWSLoadValidators(t *testing.T) {
 	}
 }
 
-type MockDepManager struct{}
+func TestReParseFile(t *testing.T) {
+	xrd, comp, crd, claim := span.URIFromPath("/ws/xrd.yaml"), span.URIFromPath("/ws/composition.yaml"), span.URIFromPath("/ws/crd.yaml"), span.URIFromPath("/ws/examples/claim.yaml")
+
+	type args struct {
+		uri  span.URI
+		body []byte
+	}
+	type want struct {
+		uris []span.URI
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Claim": {
+			reason: "Changing a claim should only invalidate the claim.",
+			args: args{
+				uri:  claim,
+				body: testClaim,
+			},
+			want: want{
+				uris: []span.URI{claim},
+			},
+		},
+		"CRD": {
+			reason: "Changing a CRD should invalidate Compositions that compose its kind.",
+			args: args{
+				uri:  crd,
+				body: testSingleVersionCRD,
+			},
+			want: want{
+				uris: []span.URI{crd, comp},
+			},
+		},
+		"XRDRenamedKind": {
+			reason: "Changing an XRD should invalidate instances of the kinds it defined prior to the change.",
+			args: args{
+				uri:  xrd,
+				body: bytes.ReplaceAll(testXRD, []byte("kind: Network"), []byte("kind: Subnet")),
+			},
+			want: want{
+				uris: []span.URI{xrd, comp, claim},
+			},
+		},
+		"InvalidYAML": {
+			reason: "Changing a file to invalid YAML should return an error.",
+			args: args{
+				uri:  claim,
+				body: []byte("spec: : :"),
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, testNavigationFiles())
+			if err := snap.UpdateContent(context.Background(), tc.args.uri, []protocol.TextDocumentContentChangeEvent{{Text: string(tc.args.body)}}); err != nil {
+				t.Fatal(err)
+			}
+
+			uris, err := snap.ReParseFile(tc.args.uri.Filename())
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReParseFile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.uris, uris); diff != "" {
+				t.Errorf("\n%s\nReParseFile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRefreshDefinitions(t *testing.T) {
+	network := schema.GroupVersionKind{Group: "acme.io", Version: "v1alpha1", Kind: "Network"}
+	subnet := schema.GroupVersionKind{Group: "acme.io", Version: "v1alpha1", Kind: "Subnet"}
+	cert := schema.GroupVersionKind{Group: "acm.aws.crossplane.io", Version: "v1alpha1", Kind: "Certificate"}
+
+	type args struct {
+		refresh func(s *Snapshot) error
+	}
+	type want struct {
+		defined map[schema.GroupVersionKind]bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RemovedXRD": {
+			reason: "Removing an XRD should remove the validators and definitions of the kinds it defined.",
+			args: args{
+				refresh: func(s *Snapshot) error {
+					_, err := s.RemoveFile("/ws/xrd.yaml")
+					return err
+				},
+			},
+			want: want{
+				defined: map[schema.GroupVersionKind]bool{network: false, cert: true},
+			},
+		},
+		"RemovedCRD": {
+			reason: "Removing a CRD should remove the validators and definitions of the kinds it defined.",
+			args: args{
+				refresh: func(s *Snapshot) error {
+					_, err := s.RemoveFile("/ws/crd.yaml")
+					return err
+				},
+			},
+			want: want{
+				defined: map[schema.GroupVersionKind]bool{network: true, cert: false},
+			},
+		},
+		"RenamedClaimKind": {
+			reason: "Renaming a kind in an XRD should replace the validators and definitions of the old kind.",
+			args: args{
+				refresh: func(s *Snapshot) error {
+					xrd := span.URIFromPath("/ws/xrd.yaml")
+					body := bytes.ReplaceAll(testXRD, []byte("kind: Network"), []byte("kind: Subnet"))
+					if err := s.UpdateContent(context.Background(), xrd, []protocol.TextDocumentContentChangeEvent{{Text: string(body)}}); err != nil {
+						return err
+					}
+					_, err := s.ReParseFile(xrd.Filename())
+					return err
+				},
+			},
+			want: want{
+				defined: map[schema.GroupVersionKind]bool{network: false, subnet: true},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			snap := newTestSnapshot(t, testNavigationFiles())
+			if err := tc.args.refresh(snap); err != nil {
+				t.Fatal(err)
+			}
+
+			defined := map[schema.GroupVersionKind]bool{}
+			for gvk := range tc.want.defined {
+				defined[gvk] = snap.Validator(gvk) != nil && snap.TypeDefinition(gvk) != nil
+			}
+			if diff := cmp.Diff(tc.want.defined, defined); diff != "" {
+				t.Errorf("\n%s\nrefresh(...): -want defined, +got defined:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	snap := newTestSnapshot(t, testNavigationFiles())
+	claim := span.URIFromPath("/ws/examples/claim.yaml")
+
+	diags, err := snap.Diagnostics(claim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("Diagnostics(...): -want diagnostics: 0, +got diagnostics: %d", len(diags))
+	}
+
+	// cached diagnostics should be returned until the file is re-parsed.
+	invalid := bytes.ReplaceAll(testClaim, []byte("us-west-2"), []byte("eu-west-1"))
+	if err := snap.UpdateContent(context.Background(), claim, []protocol.TextDocumentContentChangeEvent{{Text: string(invalid)}}); err != nil {
+		t.Fatal(err)
+	}
+	diags, _ = snap.Diagnostics(claim)
+	if len(diags) != 0 {
+		t.Errorf("Diagnostics(...): -want cached diagnostics: 0, +got diagnostics: %d", len(diags))
+	}
+
+	if _, err := snap.ReParseFile(claim.Filename()); err != nil {
+		t.Fatal(err)
+	}
+	diags, _ = snap.Diagnostics(claim)
+	if len(diags) != 1 {
+		t.Errorf("Diagnostics(...): -want diagnostics: 1, +got diagnostics: %d", len(diags))
+	}
+}
+
+type MockDepManager struct {
+	packages []*mxpkg.ParsedPackage
+}
 
 func NewMockDepManager() *MockDepManager { return &MockDepManager{} }
 
 func (m *MockDepManager) View(context.Context, []v1beta1.Dependency) (*manager.View, error) {
-	return nil, nil
+	return &manager.View{}, nil
 }
 func (m *MockDepManager) Versions(context.Context, v1beta1.Dependency) ([]string, error) {
 	return nil, nil
 }
 
+func (m *MockDepManager) Packages(context.Context) ([]*mxpkg.ParsedPackage, error) {
+	return m.packages, nil
+}
+
 func (m *MockDepManager) Watch() <-chan cache.Event {
 	return make(<-chan cache.Event)
 }
+
+// newTestSnapshot returns a snapshot of a workspace rooted at /ws that holds
+// the supplied files, keyed by path. Dependencies are resolved by a mock
+// dependency manager without packages unless the supplied options override it.
+func newTestSnapshot(t *testing.T, files map[string][]byte, opts ...FactoryOption) *Snapshot {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	for path, body := range files {
+		if err := afero.WriteFile(fs, path, body, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ws, err := workspace.New("/ws", workspace.WithFS(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory, err := NewFactory("/ws", append([]FactoryOption{WithDepManager(NewMockDepManager())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := factory.New(WithWorkspace(ws))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snap
+}
@@ -0,0 +1,244 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/workspace"
+)
+
+const (
+	pathMetadataName = "metadata.name"
+	pathVersions     = "spec.versions"
+
+	fieldBaseAPIVersion = "base.apiVersion"
+	fieldBaseKind       = "base.kind"
+
+	detailFmt      = "%s (%s)"
+	detailPatchSet = "PatchSet"
+	detailVersion  = "Version"
+)
+
+// DocumentSymbols returns the outline of the file at the supplied uri. Each
+// document in the file is a symbol, with the resources and patch sets of
+// Compositions and the versions of XRDs and CRDs as its children.
+func (s *Snapshot) DocumentSymbols(uri span.URI) ([]protocol.DocumentSymbol, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	details, ok := s.wsview.FileDetails()[uri]
+	if !ok {
+		return nil, errors.New(errInvalidFileURI)
+	}
+
+	syms := []protocol.DocumentSymbol{}
+	for id := range details.NodeIDs {
+		n, ok := s.wsview.Nodes()[id]
+		if !ok {
+			return nil, errors.New(errInvalidNodeID)
+		}
+		if sym, ok := documentSymbol(n); ok {
+			syms = append(syms, sym)
+		}
+	}
+	sort.SliceStable(syms, func(i, j int) bool {
+		return before(syms[i].Range.Start, syms[j].Range.Start)
+	})
+	return syms, nil
+}
+
+// WorkspaceSymbols returns the symbols across all files in the workspace whose
+// name contains the supplied query, ignoring case. All symbols are returned
+// for an empty query.
+func (s *Snapshot) WorkspaceSymbols(query string) ([]protocol.SymbolInformation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	infos := []protocol.SymbolInformation{}
+	for _, n := range s.rootNodes() {
+		sym, ok := documentSymbol(n)
+		if !ok {
+			continue
+		}
+		uri := protocol.URIFromSpanURI(span.URIFromPath(n.GetFileName()))
+		infos = append(infos, symbolInformation(uri, sym, "", query)...)
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].Name != infos[j].Name {
+			return infos[i].Name < infos[j].Name
+		}
+		if infos[i].Location.URI != infos[j].Location.URI {
+			return infos[i].Location.URI < infos[j].Location.URI
+		}
+		return before(infos[i].Location.Range.Start, infos[j].Location.Range.Start)
+	})
+	return infos, nil
+}
+
+// symbolInformation flattens the supplied document symbol and its children
+// into symbol information, filtering by the supplied lower case query.
+func symbolInformation(uri protocol.DocumentURI, sym protocol.DocumentSymbol, container, query string) []protocol.SymbolInformation {
+	infos := []protocol.SymbolInformation{}
+	if strings.Contains(strings.ToLower(sym.Name), query) {
+		infos = append(infos, protocol.SymbolInformation{
+			Name: sym.Name,
+			Kind: sym.Kind,
+			Location: protocol.Location{
+				URI:   uri,
+				Range: sym.SelectionRange,
+			},
+			ContainerName: container,
+		})
+	}
+	for _, c := range sym.Children {
+		infos = append(infos, symbolInformation(uri, c, sym.Name, query)...)
+	}
+	return infos
+}
+
+// documentSymbol returns the symbol for the supplied root node.
+func documentSymbol(n workspace.Node) (protocol.DocumentSymbol, bool) {
+	root := n.GetAST()
+	if root == nil {
+		return protocol.DocumentSymbol{}, false
+	}
+	p, ok := paved(n)
+	if !ok {
+		return protocol.DocumentSymbol{}, false
+	}
+
+	gvk := n.GetGVK()
+	sym := protocol.DocumentSymbol{
+		Name:           gvk.Kind,
+		Detail:         fmt.Sprintf(detailFmt, gvk.Kind, gvk.GroupVersion()),
+		Kind:           protocol.Class,
+		Range:          nodeRange(root),
+		SelectionRange: nodeRange(root),
+	}
+	if name, err := p.GetString(pathMetadataName); err == nil && name != "" {
+		sym.Name = name
+		if sel, ok := nodeAtPath(root, pathMetadataName); ok {
+			sym.SelectionRange = tokenRange(sel.GetToken())
+		}
+	}
+	if sym.Name == "" {
+		return protocol.DocumentSymbol{}, false
+	}
+
+	switch gvk.GroupKind() {
+	case xpextv1.CompositionGroupVersionKind.GroupKind():
+		sym.Children = append(sym.Children, listSymbols(root, p, pathPatchSets, protocol.Function, func(path string) (string, string) {
+			name, _ := p.GetString(fmt.Sprintf(fieldFmt, path, fieldName))
+			return name, detailPatchSet
+		})...)
+		sym.Children = append(sym.Children, listSymbols(root, p, pathResources, protocol.Object, func(path string) (string, string) {
+			name, _ := p.GetString(fmt.Sprintf(fieldFmt, path, fieldName))
+			kind, _ := p.GetString(fmt.Sprintf(fieldFmt, path, fieldBaseKind))
+			av, _ := p.GetString(fmt.Sprintf(fieldFmt, path, fieldBaseAPIVersion))
+			if name == "" {
+				name = kind
+			}
+			return name, fmt.Sprintf(detailFmt, kind, av)
+		})...)
+	case xpextv1.CompositeResourceDefinitionGroupVersionKind.GroupKind(), crdGroupKind:
+		sym.Children = append(sym.Children, listSymbols(root, p, pathVersions, protocol.Struct, func(path string) (string, string) {
+			name, _ := p.GetString(fmt.Sprintf(fieldFmt, path, fieldName))
+			return name, detailVersion
+		})...)
+	}
+	return sym, true
+}
+
+// listSymbols returns a symbol for each element of the list found at the
+// supplied path. The name and detail of each symbol are determined by the
+// supplied function, which is passed the path of the element. Elements
+// without a name are named after their index.
+func listSymbols(root ast.Node, p *fieldpath.Paved, path string, kind protocol.SymbolKind, describe func(string) (string, string)) []protocol.DocumentSymbol {
+	syms := []protocol.DocumentSymbol{}
+	for i := 0; i < lenAt(p, path); i++ {
+		ip := fmt.Sprintf(indexFmt, path, i)
+		in, ok := nodeAtPath(root, ip)
+		if !ok {
+			continue
+		}
+		name, detail := describe(ip)
+		sel := nodeRange(in)
+		if nn, ok := nodeAtPath(root, fmt.Sprintf(fieldFmt, ip, fieldName)); ok {
+			sel = tokenRange(nn.GetToken())
+		}
+		if name == "" {
+			name = fmt.Sprintf(indexFmt, path, i)
+		}
+		sym := protocol.DocumentSymbol{
+			Name:           name,
+			Detail:         detail,
+			Kind:           kind,
+			Range:          nodeRange(in),
+			SelectionRange: sel,
+		}
+		syms = append(syms, sym)
+	}
+	return syms
+}
+
+// nodeRange returns the range spanned by all of the tokens in the supplied
+// node.
+func nodeRange(n ast.Node) protocol.Range {
+	v := &rangeVisitor{}
+	ast.Walk(v, n)
+	return v.rng
+}
+
+// rangeVisitor accumulates the range spanned by the nodes it visits.
+type rangeVisitor struct {
+	rng  protocol.Range
+	seen bool
+}
+
+// Visit implements ast.Visitor.
+func (v *rangeVisitor) Visit(n ast.Node) ast.Visitor {
+	if n == nil || n.GetToken() == nil {
+		return v
+	}
+	r := tokenRange(n.GetToken())
+	if !v.seen || before(r.Start, v.rng.Start) {
+		v.rng.Start = r.Start
+	}
+	if !v.seen || before(v.rng.End, r.End) {
+		v.rng.End = r.End
+	}
+	v.seen = true
+	return v
+}
+
+// before returns true if position a is before position b.
+func before(a, b protocol.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
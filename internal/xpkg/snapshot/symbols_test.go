@@ -0,0 +1,183 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testMultiLineRange(startLine, startChar, endLine, endChar uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: startLine, Character: startChar},
+		End:   protocol.Position{Line: endLine, Character: endChar},
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	snap := newTestSnapshot(t, testNavigationFiles())
+
+	type args struct {
+		file string
+	}
+	type want struct {
+		syms []protocol.DocumentSymbol
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Composition": {
+			reason: "A Composition should list its patch sets and resources.",
+			args: args{
+				file: "/ws/composition.yaml",
+			},
+			want: want{
+				syms: []protocol.DocumentSymbol{{
+					Name:           "xnetworks.acme.io",
+					Detail:         "Composition (apiextensions.crossplane.io/v1)",
+					Kind:           protocol.Class,
+					Range:          testMultiLineRange(0, 0, 20, 26),
+					SelectionRange: testRange(3, 8, 25),
+					Children: []protocol.DocumentSymbol{
+						{
+							Name:           "common",
+							Detail:         detailPatchSet,
+							Kind:           protocol.Function,
+							Range:          testMultiLineRange(9, 4, 12, 42),
+							SelectionRange: testRange(9, 10, 16),
+						},
+						{
+							Name:           "cert",
+							Detail:         "Certificate (acm.aws.crossplane.io/v1alpha1)",
+							Kind:           protocol.Object,
+							Range:          testMultiLineRange(14, 4, 20, 26),
+							SelectionRange: testRange(14, 10, 14),
+						},
+					},
+				}},
+			},
+		},
+		"XRD": {
+			reason: "An XRD should list its versions.",
+			args: args{
+				file: "/ws/xrd.yaml",
+			},
+			want: want{
+				syms: []protocol.DocumentSymbol{{
+					Name:           "xnetworks.acme.io",
+					Detail:         "CompositeResourceDefinition (apiextensions.crossplane.io/v1)",
+					Kind:           protocol.Class,
+					Range:          testMultiLineRange(0, 0, 31, 20),
+					SelectionRange: testRange(3, 8, 25),
+					Children: []protocol.DocumentSymbol{{
+						Name:           "v1alpha1",
+						Detail:         detailVersion,
+						Kind:           protocol.Struct,
+						Range:          testMultiLineRange(13, 4, 31, 20),
+						SelectionRange: testRange(13, 10, 18),
+					}},
+				}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			syms, err := snap.DocumentSymbols(span.URIFromPath(tc.args.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want.syms, syms); diff != "" {
+				t.Errorf("\n%s\nDocumentSymbols(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceSymbols(t *testing.T) {
+	snap := newTestSnapshot(t, testNavigationFiles())
+
+	type args struct {
+		query string
+	}
+	type want struct {
+		infos []protocol.SymbolInformation
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"PatchSet": {
+			reason: "Patch sets should be found by name, contained by their Composition.",
+			args: args{
+				query: "COMMON",
+			},
+			want: want{
+				infos: []protocol.SymbolInformation{{
+					Name:          "common",
+					Kind:          protocol.Function,
+					Location:      testLocation("/ws/composition.yaml", 9, 10, 16),
+					ContainerName: "xnetworks.acme.io",
+				}},
+			},
+		},
+		"Substring": {
+			reason: "All symbols containing the query should be returned, sorted by name.",
+			args: args{
+				query: "cert",
+			},
+			want: want{
+				infos: []protocol.SymbolInformation{
+					{
+						Name:          "cert",
+						Kind:          protocol.Object,
+						Location:      testLocation("/ws/composition.yaml", 14, 10, 14),
+						ContainerName: "xnetworks.acme.io",
+					},
+					{
+						Name:     "certificates.acm.aws.crossplane.io",
+						Kind:     protocol.Class,
+						Location: testLocation("/ws/crd.yaml", 4, 8, 42),
+					},
+				},
+			},
+		},
+		"NoMatch": {
+			reason: "An empty list should be returned if nothing matches the query.",
+			args: args{
+				query: "nothing",
+			},
+			want: want{
+				infos: []protocol.SymbolInformation{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			infos, err := snap.WorkspaceSymbols(tc.args.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want.infos, infos); diff != "" {
+				t.Errorf("\n%s\nWorkspaceSymbols(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
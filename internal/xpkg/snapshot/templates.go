@@ -0,0 +1,313 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	xpextv1beta1 "github.com/crossplane/crossplane/apis/apiextensions/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+const (
+	fieldReadinessChecks         = "readinessChecks"
+	fieldConnectionDetails       = "connectionDetails"
+	fieldFromConnectionSecretKey = "fromConnectionSecretKey"
+
+	pathWriteConnectionSecretToRef = "spec.writeConnectionSecretToRef"
+	pathCompositeTypeRefKind       = "spec.compositeTypeRef.kind"
+
+	errReadinessMatchFmt     = "%s readiness check cannot match %s of type %s (%s)"
+	errNoConnectionSecretFmt = "%s does not publish connection details"
+	errConnectionKeyFmt      = "%s does not publish connection secret key %s"
+	errDuplicateNameFmt      = "resource name %s is already used by %s"
+	errUndefinedPatchSetFmt  = "patch set %s is not defined"
+
+	warnConnectionKeyFmt = "connection secret key %s of %s is not published by any resource"
+)
+
+// ReadinessChecksValidator validates the readiness checks of the resources
+// in a Composition. The field path of each check must exist in the schema of
+// the composed resource, and the type of the field must be compatible with
+// the type of the check.
+type ReadinessChecksValidator struct {
+	types *PatchTypeChecker
+}
+
+// NewReadinessChecksValidator returns a new ReadinessChecksValidator.
+func NewReadinessChecksValidator(s *Snapshot) *ReadinessChecksValidator {
+	return &ReadinessChecksValidator{
+		types: NewPatchTypeChecker(s),
+	}
+}
+
+// Check returns the errors found in the readiness checks of the supplied
+// Composition.
+func (r *ReadinessChecksValidator) Check(comp *xpextv1.Composition) []error {
+	errs := []error{}
+	for i, res := range comp.Spec.Resources {
+		cd := patchTarget{gvk: baseGVK(res)}
+		checks := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), fieldReadinessChecks)
+		for j, rc := range res.ReadinessChecks {
+			if rc.Type == xpextv1.ReadinessCheckTypeNone || rc.FieldPath == "" {
+				continue
+			}
+			path := fmt.Sprintf(indexFmt, checks, j)
+			t, err := r.types.typeAt(cd, rc.FieldPath, fmt.Sprintf(fieldFmt, path, fieldFieldPath))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			var accepts bool
+			switch rc.Type {
+			case xpextv1.ReadinessCheckTypeMatchString:
+				accepts = assignable(typeString, t)
+			case xpextv1.ReadinessCheckTypeMatchInteger:
+				accepts = assignable(typeInteger, t)
+			default:
+				accepts = true
+			}
+			if !accepts {
+				errs = append(errs, &validator.Validation{
+					TypeCode: validator.FieldErrorTypeCode,
+					Message:  fmt.Sprintf(errReadinessMatchFmt, rc.Type, rc.FieldPath, t, cd.gvk),
+					Name:     fmt.Sprintf(fieldFmt, path, fieldType),
+					Reason:   validator.ReasonTypeMismatch,
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// ConnectionDetailsValidator validates the connection details of the
+// resources in a Composition. Connection secret keys must be published by the
+// composed resource they are read from, field paths must exist in its schema,
+// and every connection secret key declared by the composite resource should
+// be published by some resource.
+type ConnectionDetailsValidator struct {
+	s     *Snapshot
+	types *PatchTypeChecker
+}
+
+// NewConnectionDetailsValidator returns a new ConnectionDetailsValidator.
+func NewConnectionDetailsValidator(s *Snapshot) *ConnectionDetailsValidator {
+	return &ConnectionDetailsValidator{
+		s:     s,
+		types: NewPatchTypeChecker(s),
+	}
+}
+
+// Check returns the errors found in the connection details of the supplied
+// Composition.
+func (c *ConnectionDetailsValidator) Check(comp *xpextv1.Composition) []error { // nolint:gocyclo
+	errs := []error{}
+	published := map[string]struct{}{}
+	for i, res := range comp.Spec.Resources {
+		cd := patchTarget{gvk: baseGVK(res)}
+		details := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), fieldConnectionDetails)
+		for j, d := range res.ConnectionDetails {
+			path := fmt.Sprintf(indexFmt, details, j)
+			if d.Name != nil {
+				published[*d.Name] = struct{}{}
+			}
+			switch connectionDetailType(d) {
+			case xpextv1.ConnectionDetailTypeFromConnectionSecretKey:
+				if d.Name == nil {
+					published[*d.FromConnectionSecretKey] = struct{}{}
+				}
+				if err := c.checkSecretKey(cd, *d.FromConnectionSecretKey, fmt.Sprintf(fieldFmt, path, fieldFromConnectionSecretKey)); err != nil {
+					errs = append(errs, err)
+				}
+			case xpextv1.ConnectionDetailTypeFromFieldPath:
+				if _, err := c.types.typeAt(cd, *d.FromFieldPath, fmt.Sprintf(fieldFmt, path, fieldFromFieldPath)); err != nil {
+					errs = append(errs, err)
+				}
+			case xpextv1.ConnectionDetailTypeFromValue, xpextv1.ConnectionDetailTypeUnknown:
+			}
+		}
+	}
+
+	xr := patchTarget{gvk: schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind)}
+	keys, ok := c.secretKeys(xr)
+	if !ok {
+		return errs
+	}
+	for _, k := range keys {
+		if _, ok := published[k]; ok {
+			continue
+		}
+		errs = append(errs, &validator.Validation{
+			TypeCode: validator.WarningTypeCode,
+			Message:  fmt.Sprintf(warnConnectionKeyFmt, k, xr.gvk),
+			Name:     pathCompositeTypeRefKind,
+		})
+	}
+	return errs
+}
+
+// checkSecretKey returns an error if the supplied resource cannot publish the
+// supplied connection secret key. Composite resources declare the keys they
+// publish, while other resources must support writing a connection secret.
+func (c *ConnectionDetailsValidator) checkSecretKey(cd patchTarget, key, name string) error {
+	if keys, ok := c.secretKeys(cd); ok {
+		for _, k := range keys {
+			if k == key {
+				return nil
+			}
+		}
+		return &validator.Validation{
+			TypeCode: validator.FieldErrorTypeCode,
+			Message:  fmt.Sprintf(errConnectionKeyFmt, cd.gvk, key),
+			Name:     name,
+		}
+	}
+	def, ok := c.s.definitions[cd.gvk]
+	if !ok || def.Schema == nil {
+		return nil
+	}
+	segs, err := fieldpath.Parse(pathWriteConnectionSecretToRef)
+	if err != nil {
+		return nil
+	}
+	if _, ok := resolveSchema(def.Schema, segs); ok {
+		return nil
+	}
+	return &validator.Validation{
+		TypeCode: validator.FieldErrorTypeCode,
+		Message:  fmt.Sprintf(errNoConnectionSecretFmt, cd.gvk),
+		Name:     name,
+	}
+}
+
+// secretKeys returns the connection secret keys declared by the XRD that
+// defines the supplied resource. False is returned if the resource is not
+// defined by an XRD or its XRD does not restrict the keys it publishes.
+func (c *ConnectionDetailsValidator) secretKeys(t patchTarget) ([]string, bool) {
+	def, ok := c.s.definitions[t.gvk]
+	if !ok {
+		return nil, false
+	}
+	keys := xrdConnectionSecretKeys(def.Object)
+	return keys, len(keys) > 0
+}
+
+// xrdConnectionSecretKeys returns the connection secret keys declared by the
+// supplied object if it is an XRD.
+func xrdConnectionSecretKeys(o runtime.Object) []string {
+	switch xrd := o.(type) {
+	case *xpextv1.CompositeResourceDefinition:
+		return xrd.Spec.ConnectionSecretKeys
+	case *xpextv1beta1.CompositeResourceDefinition:
+		return xrd.Spec.ConnectionSecretKeys
+	}
+	return nil
+}
+
+// connectionDetailType returns the type of the supplied connection detail,
+// inferring it from the fields that are set if it is not specified, in the
+// same manner as Crossplane.
+func connectionDetailType(d xpextv1.ConnectionDetail) xpextv1.ConnectionDetailType {
+	switch {
+	case d.Type != nil:
+		t := *d.Type
+		// a type is only meaningful if its required field is set.
+		if (t == xpextv1.ConnectionDetailTypeFromConnectionSecretKey && d.FromConnectionSecretKey == nil) ||
+			(t == xpextv1.ConnectionDetailTypeFromFieldPath && d.FromFieldPath == nil) {
+			return xpextv1.ConnectionDetailTypeUnknown
+		}
+		return t
+	case d.Value != nil:
+		return xpextv1.ConnectionDetailTypeFromValue
+	case d.FromConnectionSecretKey != nil:
+		return xpextv1.ConnectionDetailTypeFromConnectionSecretKey
+	case d.FromFieldPath != nil:
+		return xpextv1.ConnectionDetailTypeFromFieldPath
+	}
+	return xpextv1.ConnectionDetailTypeUnknown
+}
+
+// ResourceNamesValidator validates that the names of the resources in a
+// Composition are unique.
+type ResourceNamesValidator struct{}
+
+// NewResourceNamesValidator returns a new ResourceNamesValidator.
+func NewResourceNamesValidator() *ResourceNamesValidator {
+	return &ResourceNamesValidator{}
+}
+
+// Check returns an error for each resource in the supplied Composition whose
+// name is used by an earlier resource.
+func (r *ResourceNamesValidator) Check(comp *xpextv1.Composition) []error {
+	errs := []error{}
+	seen := map[string]int{}
+	for i, res := range comp.Spec.Resources {
+		if res.Name == nil {
+			continue
+		}
+		if j, ok := seen[*res.Name]; ok {
+			errs = append(errs, &validator.Validation{
+				TypeCode: validator.FieldErrorTypeCode,
+				Message:  fmt.Sprintf(errDuplicateNameFmt, *res.Name, fmt.Sprintf(indexFmt, pathResources, j)),
+				Name:     fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), fieldName),
+			})
+			continue
+		}
+		seen[*res.Name] = i
+	}
+	return errs
+}
+
+// PatchSetsValidator validates that the patch sets referenced by the
+// resources in a Composition are defined.
+type PatchSetsValidator struct{}
+
+// NewPatchSetsValidator returns a new PatchSetsValidator.
+func NewPatchSetsValidator() *PatchSetsValidator {
+	return &PatchSetsValidator{}
+}
+
+// Check returns an error for each reference to an undefined patch set in the
+// supplied Composition.
+func (p *PatchSetsValidator) Check(comp *xpextv1.Composition) []error {
+	defined := make(map[string]struct{}, len(comp.Spec.PatchSets))
+	for _, ps := range comp.Spec.PatchSets {
+		defined[ps.Name] = struct{}{}
+	}
+	errs := []error{}
+	for i, res := range comp.Spec.Resources {
+		patches := fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, pathResources, i), fieldPatches)
+		for j, pt := range res.Patches {
+			if pt.Type != xpextv1.PatchTypePatchSet || pt.PatchSetName == nil {
+				continue
+			}
+			if _, ok := defined[*pt.PatchSetName]; ok {
+				continue
+			}
+			errs = append(errs, &validator.Validation{
+				TypeCode: validator.FieldErrorTypeCode,
+				Message:  fmt.Sprintf(errUndefinedPatchSetFmt, *pt.PatchSetName),
+				Name:     fmt.Sprintf(fieldFmt, fmt.Sprintf(indexFmt, patches, j), fieldPatchSetName),
+			})
+		}
+	}
+	return errs
+}
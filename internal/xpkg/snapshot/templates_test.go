@@ -0,0 +1,298 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/upbound/up/internal/xpkg/snapshot/validator"
+)
+
+var (
+	testDatabaseXRD = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xdatabases.acme.io
+spec:
+  group: acme.io
+  names:
+    kind: XDatabase
+    plural: xdatabases
+  connectionSecretKeys:
+  - endpoint
+  - password
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`)
+
+	testSubnetXRD = []byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xsubnets.acme.io
+spec:
+  group: acme.io
+  names:
+    kind: XSubnet
+    plural: xsubnets
+  connectionSecretKeys:
+  - id
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`)
+
+	testSubnetBase = runtime.RawExtension{Raw: []byte(`{"apiVersion": "acme.io/v1alpha1", "kind": "XSubnet"}`)}
+)
+
+// testTemplatesFiles returns the files of the workspace that composed
+// templates are validated against.
+func testTemplatesFiles() map[string][]byte {
+	return map[string][]byte{
+		"/ws/crd.yaml":      testSingleVersionCRD,
+		"/ws/database.yaml": testDatabaseXRD,
+		"/ws/subnet.yaml":   testSubnetXRD,
+	}
+}
+
+func TestReadinessChecksValidator(t *testing.T) {
+	snap := newTestSnapshot(t, testTemplatesFiles())
+
+	type args struct {
+		comp *v1.Composition
+	}
+	type want struct {
+		errs []error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Readiness checks of fields that exist with compatible types should not return errors.",
+			args: args{
+				comp: testComposition("XDatabase", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					ReadinessChecks: []v1.ReadinessCheck{
+						{Type: v1.ReadinessCheckTypeMatchString, FieldPath: "spec.forProvider.region", MatchString: "us-east-1"},
+						{Type: v1.ReadinessCheckTypeNonEmpty, FieldPath: "spec.forProvider.domainName"},
+						{Type: v1.ReadinessCheckTypeNone},
+					},
+				}),
+			},
+			want: want{
+				errs: []error{},
+			},
+		},
+		"FieldPathNotFound": {
+			reason: "Readiness checks of fields that do not exist should return an error.",
+			args: args{
+				comp: testComposition("XDatabase", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					ReadinessChecks: []v1.ReadinessCheck{
+						{Type: v1.ReadinessCheckTypeNonEmpty, FieldPath: "spec.forProvider.domain"},
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "spec.forProvider.domain is not a field of acm.aws.crossplane.io/v1alpha1, Kind=Certificate",
+						Name:     "spec.resources[0].readinessChecks[0].fieldPath",
+						Reason:   validator.ReasonFieldNotFound,
+					},
+				},
+			},
+		},
+		"TypeMismatch": {
+			reason: "Readiness checks that cannot match the type of their field should return an error.",
+			args: args{
+				comp: testComposition("XDatabase", nil, v1.ComposedTemplate{
+					Base: testCertificateBase,
+					ReadinessChecks: []v1.ReadinessCheck{
+						{Type: v1.ReadinessCheckTypeMatchInteger, FieldPath: "spec.forProvider.region", MatchInteger: 1},
+					},
+				}),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "MatchInteger readiness check cannot match spec.forProvider.region of type string (acm.aws.crossplane.io/v1alpha1, Kind=Certificate)",
+						Name:     "spec.resources[0].readinessChecks[0].type",
+						Reason:   validator.ReasonTypeMismatch,
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs := NewReadinessChecksValidator(snap).Check(tc.args.comp)
+
+			if diff := cmp.Diff(tc.want.errs, errs); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectionDetailsValidator(t *testing.T) {
+	snap := newTestSnapshot(t, testTemplatesFiles())
+
+	type args struct {
+		comp *v1.Composition
+	}
+	type want struct {
+		errs []error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Connection details that publish every key of the composite resource should not return errors.",
+			args: args{
+				comp: testComposition("XDatabase", nil,
+					v1.ComposedTemplate{
+						Base: testCertificateBase,
+						ConnectionDetails: []v1.ConnectionDetail{
+							{FromConnectionSecretKey: pointer.String("endpoint")},
+							{Name: pointer.String("region"), FromFieldPath: pointer.String("spec.forProvider.region")},
+						},
+					},
+					v1.ComposedTemplate{
+						Base: testSubnetBase,
+						ConnectionDetails: []v1.ConnectionDetail{
+							{Name: pointer.String("password"), FromConnectionSecretKey: pointer.String("id")},
+						},
+					},
+				),
+			},
+			want: want{
+				errs: []error{},
+			},
+		},
+		"Invalid": {
+			reason: "Keys not published by composed resources, unknown field paths and unpublished composite keys should be flagged.",
+			args: args{
+				comp: testComposition("XDatabase", nil,
+					v1.ComposedTemplate{
+						Base: testCertificateBase,
+						ConnectionDetails: []v1.ConnectionDetail{
+							{Name: pointer.String("region"), FromFieldPath: pointer.String("spec.forProvider.regoin")},
+						},
+					},
+					v1.ComposedTemplate{
+						Base: testSubnetBase,
+						ConnectionDetails: []v1.ConnectionDetail{
+							{FromConnectionSecretKey: pointer.String("endpoint")},
+						},
+					},
+				),
+			},
+			want: want{
+				errs: []error{
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "spec.forProvider.regoin is not a field of acm.aws.crossplane.io/v1alpha1, Kind=Certificate",
+						Name:     "spec.resources[0].connectionDetails[0].fromFieldPath",
+						Reason:   validator.ReasonFieldNotFound,
+					},
+					&validator.Validation{
+						TypeCode: validator.FieldErrorTypeCode,
+						Message:  "acme.io/v1alpha1, Kind=XSubnet does not publish connection secret key endpoint",
+						Name:     "spec.resources[1].connectionDetails[0].fromConnectionSecretKey",
+					},
+					&validator.Validation{
+						TypeCode: validator.WarningTypeCode,
+						Message:  "connection secret key password of acme.io/v1alpha1, Kind=XDatabase is not published by any resource",
+						Name:     "spec.compositeTypeRef.kind",
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs := NewConnectionDetailsValidator(snap).Check(tc.args.comp)
+
+			if diff := cmp.Diff(tc.want.errs, errs); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResourceNamesValidator(t *testing.T) {
+	comp := testComposition("XDatabase", nil,
+		v1.ComposedTemplate{Name: pointer.String("db")},
+		v1.ComposedTemplate{Name: pointer.String("subnet")},
+		v1.ComposedTemplate{Name: pointer.String("db")},
+	)
+	want := []error{
+		&validator.Validation{
+			TypeCode: validator.FieldErrorTypeCode,
+			Message:  "resource name db is already used by spec.resources[0]",
+			Name:     "spec.resources[2].name",
+		},
+	}
+
+	errs := NewResourceNamesValidator().Check(comp)
+	if diff := cmp.Diff(want, errs); diff != "" {
+		t.Errorf("\nDuplicate resource names should return an error.\nCheck(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestPatchSetsValidator(t *testing.T) {
+	comp := testComposition("XDatabase",
+		[]v1.PatchSet{{Name: "common"}},
+		v1.ComposedTemplate{
+			Patches: []v1.Patch{
+				{Type: v1.PatchTypePatchSet, PatchSetName: pointer.String("common")},
+				{Type: v1.PatchTypePatchSet, PatchSetName: pointer.String("comon")},
+			},
+		},
+	)
+	want := []error{
+		&validator.Validation{
+			TypeCode: validator.FieldErrorTypeCode,
+			Message:  "patch set comon is not defined",
+			Name:     "spec.resources[0].patches[1].patchSetName",
+		},
+	}
+
+	errs := NewPatchSetsValidator().Check(comp)
+	if diff := cmp.Diff(want, errs); diff != "" {
+		t.Errorf("\nReferences to undefined patch sets should return an error.\nCheck(...): -want, +got:\n%s", diff)
+	}
+}
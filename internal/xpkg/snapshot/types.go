@@ -0,0 +1,220 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	xpextv1beta1 "github.com/crossplane/crossplane/apis/apiextensions/v1beta1"
+	"github.com/crossplane/crossplane/xcrd"
+)
+
+const (
+	// LangGo generates Go types.
+	LangGo = "go"
+	// LangJSONSchema generates JSON Schema documents.
+	LangJSONSchema = "jsonschema"
+
+	jsonSchemaDraft   = "http://json-schema.org/draft-07/schema#"
+	jsonSchemaFileFmt = "%s_%s.json"
+
+	errUnknownLangFmt     = "unknown language %s"
+	errBuildTypeSchemaFmt = "cannot build schema for %s version %s"
+	errNoXRDs             = "no CompositeResourceDefinitions found"
+)
+
+// A GeneratedFile is a file generated from the definitions in a Snapshot. Its
+// path is relative to the directory it is generated in.
+type GeneratedFile struct {
+	Path    string
+	Content []byte
+}
+
+// typeVersion is a version of a type defined by an XRD.
+type typeVersion struct {
+	group      string
+	version    string
+	kind       string
+	listKind   string
+	namespaced bool
+	schema     *extv1.JSONSchemaProps
+}
+
+// GenerateTypes generates types in the supplied language for the composite
+// resources and claims defined by the XRDs in the workspace. The XRDs of
+// resolved dependencies are also included if deps is true.
+func (s *Snapshot) GenerateTypes(lang string, deps bool) ([]GeneratedFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tvs, err := s.typeVersions(deps)
+	if err != nil {
+		return nil, err
+	}
+	if len(tvs) == 0 {
+		return nil, errors.New(errNoXRDs)
+	}
+	switch lang {
+	case LangGo:
+		return goTypes(tvs)
+	case LangJSONSchema:
+		return jsonSchemas(tvs)
+	}
+	return nil, errors.Errorf(errUnknownLangFmt, lang)
+}
+
+// typeVersions returns the versions of the types defined by the XRDs in the
+// workspace and, if deps is true, its resolved dependencies. Versions are
+// sorted by group, version and kind.
+func (s *Snapshot) typeVersions(deps bool) ([]typeVersion, error) {
+	pkg := s.wsPackageName()
+	xrds := map[string]runtime.Object{}
+	for _, def := range s.definitions {
+		if !deps && def.Package != pkg {
+			continue
+		}
+		switch x := def.Object.(type) {
+		case *xpextv1.CompositeResourceDefinition:
+			xrds[x.GetName()] = x
+		case *xpextv1beta1.CompositeResourceDefinition:
+			xrds[x.GetName()] = x
+		}
+	}
+
+	tvs := []typeVersion{}
+	for _, o := range xrds {
+		vs, err := xrdTypeVersions(o)
+		if err != nil {
+			return nil, err
+		}
+		tvs = append(tvs, vs...)
+	}
+	sort.Slice(tvs, func(i, j int) bool {
+		if tvs[i].group != tvs[j].group {
+			return tvs[i].group < tvs[j].group
+		}
+		if tvs[i].version != tvs[j].version {
+			return tvs[i].version < tvs[j].version
+		}
+		return tvs[i].kind < tvs[j].kind
+	})
+	return tvs, nil
+}
+
+// xrdTypeVersions returns the versions of the composite resource and claim
+// types defined by the supplied XRD.
+func xrdTypeVersions(o runtime.Object) ([]typeVersion, error) {
+	var group string
+	var names extv1.CustomResourceDefinitionNames
+	var claimNames *extv1.CustomResourceDefinitionNames
+	schemas := map[string]runtime.RawExtension{}
+
+	switch x := o.(type) {
+	case *xpextv1.CompositeResourceDefinition:
+		group, names, claimNames = x.Spec.Group, x.Spec.Names, x.Spec.ClaimNames
+		for _, v := range x.Spec.Versions {
+			if v.Schema != nil {
+				schemas[v.Name] = v.Schema.OpenAPIV3Schema
+			}
+		}
+	case *xpextv1beta1.CompositeResourceDefinition:
+		group, names, claimNames = x.Spec.Group, x.Spec.Names, x.Spec.ClaimNames
+		for _, v := range x.Spec.Versions {
+			if v.Schema != nil {
+				schemas[v.Name] = v.Schema.OpenAPIV3Schema
+			}
+		}
+	}
+
+	tvs := []typeVersion{}
+	for v, raw := range schemas {
+		xr, err := buildSchemaWithSpecProps(raw, xcrd.CompositeResourceSpecProps())
+		if err != nil {
+			return nil, errors.Wrapf(err, errBuildTypeSchemaFmt, names.Kind, v)
+		}
+		tvs = append(tvs, typeVersion{
+			group:    group,
+			version:  v,
+			kind:     names.Kind,
+			listKind: listKind(names),
+			schema:   xr,
+		})
+		if claimNames == nil {
+			continue
+		}
+		claim, err := buildSchemaWithSpecProps(raw, xcrd.CompositeResourceClaimSpecProps())
+		if err != nil {
+			return nil, errors.Wrapf(err, errBuildTypeSchemaFmt, claimNames.Kind, v)
+		}
+		tvs = append(tvs, typeVersion{
+			group:      group,
+			version:    v,
+			kind:       claimNames.Kind,
+			listKind:   listKind(*claimNames),
+			namespaced: true,
+			schema:     claim,
+		})
+	}
+	return tvs, nil
+}
+
+// listKind returns the list kind of the supplied names, defaulting it as the
+// API server would.
+func listKind(n extv1.CustomResourceDefinitionNames) string {
+	if n.ListKind != "" {
+		return n.ListKind
+	}
+	return n.Kind + "List"
+}
+
+// jsonSchemas returns a standalone JSON Schema document for each of the
+// supplied type versions.
+func jsonSchemas(tvs []typeVersion) ([]GeneratedFile, error) {
+	files := make([]GeneratedFile, len(tvs))
+	for i, tv := range tvs {
+		b, err := json.Marshal(tv.schema)
+		if err != nil {
+			return nil, err
+		}
+		doc := map[string]any{}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		doc["$schema"] = jsonSchemaDraft
+		doc["title"] = tv.kind
+		if props, ok := doc["properties"].(map[string]any); ok {
+			props["apiVersion"] = map[string]any{"type": typeString, "enum": []string{tv.group + "/" + tv.version}}
+			props["kind"] = map[string]any{"type": typeString, "enum": []string{tv.kind}}
+		}
+		out, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[i] = GeneratedFile{
+			Path:    filepath.Join(tv.group, fmt.Sprintf(jsonSchemaFileFmt, strings.ToLower(tv.kind), tv.version)),
+			Content: append(out, '\n'),
+		}
+	}
+	return files, nil
+}
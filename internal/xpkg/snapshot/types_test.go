@@ -0,0 +1,127 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestGenerateTypes(t *testing.T) {
+	snap := newTestSnapshot(t, testGenerateFiles())
+
+	type args struct {
+		lang string
+	}
+	type want struct {
+		paths []string
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Go": {
+			reason: "A Go package should be generated for each group version, with a file of types for each kind.",
+			args: args{
+				lang: LangGo,
+			},
+			want: want{
+				paths: []string{
+					"acme.io/v1alpha1/cluster_types.go",
+					"acme.io/v1alpha1/xcluster_types.go",
+					"acme.io/v1alpha1/xdatabase_types.go",
+					"acme.io/v1alpha1/zz_generated.deepcopy.go",
+					"acme.io/v1alpha1/groupversion_info.go",
+				},
+			},
+		},
+		"JSONSchema": {
+			reason: "A JSON Schema document should be generated for each version of each kind.",
+			args: args{
+				lang: LangJSONSchema,
+			},
+			want: want{
+				paths: []string{
+					"acme.io/cluster_v1alpha1.json",
+					"acme.io/xcluster_v1alpha1.json",
+					"acme.io/xdatabase_v1alpha1.json",
+				},
+			},
+		},
+		"UnknownLang": {
+			reason: "An error should be returned for languages that are not supported.",
+			args: args{
+				lang: "rust",
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			files, err := snap.GenerateTypes(tc.args.lang, false)
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGenerateTypes(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			var paths []string
+			for _, f := range files {
+				paths = append(paths, f.Path)
+				switch {
+				case strings.HasSuffix(f.Path, ".go"):
+					if _, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, parser.AllErrors); err != nil {
+						t.Errorf("\n%s\nGenerateTypes(...): invalid Go source %s: %s", tc.reason, f.Path, err)
+					}
+				case strings.HasSuffix(f.Path, ".json"):
+					if !json.Valid(f.Content) {
+						t.Errorf("\n%s\nGenerateTypes(...): invalid JSON %s", tc.reason, f.Path)
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.want.paths, paths); diff != "" {
+				t.Errorf("\n%s\nGenerateTypes(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGoName(t *testing.T) {
+	cases := map[string]struct {
+		field string
+		want  string
+	}{
+		"Lower":      {field: "region", want: "Region"},
+		"Camel":      {field: "nodeCount", want: "NodeCount"},
+		"Initialism": {field: "apiVersion", want: "APIVersion"},
+		"Separators": {field: "node-pool_size", want: "NodePoolSize"},
+		"Digit":      {field: "3az", want: "X3az"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, goName(tc.field)); diff != "" {
+				t.Errorf("\ngoName(%q): -want, +got:\n%s", tc.field, diff)
+			}
+		})
+	}
+}
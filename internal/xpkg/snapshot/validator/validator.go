@@ -25,6 +25,9 @@ const (
 	WarningTypeCode = 100
 	// ErrorTypeCode indicates an error is being returned.
 	ErrorTypeCode = 500
+	// FieldErrorTypeCode indicates an error is being returned for the named
+	// field itself, rather than for its parent.
+	FieldErrorTypeCode = 501
 
 	// NOTE(@tnthornton) api-server uses error code 422 and 600+ to indicate
 	// validation errors. As long as we're deferring to their logic for
@@ -32,6 +35,32 @@ const (
 	// codes.
 )
 
+// Reasons can be used to identify the underlying cause of a Validation so
+// that consumers can offer remediations.
+const (
+	// ReasonDefinitionNotFound indicates that no definition exists for the
+	// GVK of a resource.
+	ReasonDefinitionNotFound = "DefinitionNotFound"
+	// ReasonDeprecatedAPIVersion indicates that a deprecated apiVersion is
+	// in use.
+	ReasonDeprecatedAPIVersion = "DeprecatedAPIVersion"
+	// ReasonPackageNotFound indicates that a dependency does not exist in
+	// the local cache.
+	ReasonPackageNotFound = "PackageNotFound"
+	// ReasonVersionNotFound indicates that no version matching a
+	// dependency's constraints exists in the local cache.
+	ReasonVersionNotFound = "VersionNotFound"
+	// ReasonFieldNotFound indicates that a field path refers to a field that
+	// does not exist in the schema of a resource.
+	ReasonFieldNotFound = "FieldNotFound"
+	// ReasonTypeMismatch indicates that the type of a value is not
+	// compatible with the field it is written to.
+	ReasonTypeMismatch = "TypeMismatch"
+	// ReasonRuleFailed indicates that a value does not satisfy an
+	// x-kubernetes-validations rule of its schema.
+	ReasonRuleFailed = "RuleFailed"
+)
+
 // Nop is used for no-op validator results.
 var Nop = &validate.Result{}
 
@@ -45,6 +74,7 @@ type Validation struct {
 	TypeCode int32
 	Message  string
 	Name     string
+	Reason   string
 }
 
 // Code returns the code corresponding to the MetaValidation.
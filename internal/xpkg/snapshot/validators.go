@@ -113,8 +113,10 @@ func validatorsFromV1Beta1CRD(c *extv1beta1.CustomResourceDefinition, acc map[sc
 		if err != nil {
 			return err
 		}
+		cv := newCELValidator(internal.Spec.Validation.OpenAPIV3Schema)
 		for _, v := range internal.Spec.Versions {
 			appendToValidators(gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind), acc, sv)
+			appendCELValidator(gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind), acc, cv)
 		}
 		return nil
 	}
@@ -124,6 +126,9 @@ func validatorsFromV1Beta1CRD(c *extv1beta1.CustomResourceDefinition, acc map[sc
 			return err
 		}
 		appendToValidators(gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind), acc, sv)
+		if v.Schema != nil {
+			appendCELValidator(gvk(internal.Spec.Group, v.Name, internal.Spec.Names.Kind), acc, newCELValidator(v.Schema.OpenAPIV3Schema))
+		}
 	}
 
 	return nil
@@ -137,6 +142,7 @@ func validatorsFromV1CRD(c *extv1.CustomResourceDefinition, acc map[schema.Group
 			return err
 		}
 		appendToValidators(gvk(c.Spec.Group, v.Name, c.Spec.Names.Kind), acc, sv)
+		appendCELValidator(gvk(c.Spec.Group, v.Name, c.Spec.Names.Kind), acc, newV1CELValidator(*v.Schema.OpenAPIV3Schema))
 	}
 
 	return nil
@@ -157,10 +163,13 @@ func validatorsFromV1Beta1XRD(x *xpextv1beta1.CompositeResourceDefinition, acc m
 				return err
 			}
 
+			cv := newV1CELValidator(*schema)
 			if x.Spec.ClaimNames != nil {
 				appendToValidators(gvk(x.Spec.Group, v.Name, x.Spec.ClaimNames.Kind), acc, sv)
+				appendCELValidator(gvk(x.Spec.Group, v.Name, x.Spec.ClaimNames.Kind), acc, cv)
 			}
 			appendToValidators(gvk(x.Spec.Group, v.Name, x.Spec.Names.Kind), acc, sv)
+			appendCELValidator(gvk(x.Spec.Group, v.Name, x.Spec.Names.Kind), acc, cv)
 		}
 	}
 	return nil
@@ -190,10 +199,13 @@ func validatorsFromV1XRD(x *xpextv1.CompositeResourceDefinition, acc map[schema.
 				return err
 			}
 
+			cv := newV1CELValidator(*schema)
 			if x.Spec.ClaimNames != nil {
 				appendToValidators(gvk(x.Spec.Group, v.Name, x.Spec.ClaimNames.Kind), acc, sv)
+				appendCELValidator(gvk(x.Spec.Group, v.Name, x.Spec.ClaimNames.Kind), acc, cv)
 			}
 			appendToValidators(gvk(x.Spec.Group, v.Name, x.Spec.Names.Kind), acc, sv)
+			appendCELValidator(gvk(x.Spec.Group, v.Name, x.Spec.Names.Kind), acc, cv)
 		}
 	}
 	return nil
@@ -272,7 +284,22 @@ func appendToValidators(gvk schema.GroupVersionKind, acc map[schema.GroupVersion
 	acc[gvk] = curr
 }
 
+// appendCELValidator appends the supplied CEL validator, if any, to the
+// validators for the supplied GVK.
+func appendCELValidator(gvk schema.GroupVersionKind, acc map[schema.GroupVersionKind]*validator.ObjectValidator, cv *CELValidator) {
+	if cv == nil {
+		return
+	}
+	appendToValidators(gvk, acc, cv)
+}
+
 func buildSchema(s runtime.RawExtension) (*extv1.JSONSchemaProps, error) {
+	return buildSchemaWithSpecProps(s, xcrd.CompositeResourceClaimSpecProps())
+}
+
+// buildSchemaWithSpecProps builds the full schema for an XRD version schema,
+// adding the supplied props that Crossplane expects in the spec.
+func buildSchemaWithSpecProps(s runtime.RawExtension, xpProps map[string]extv1.JSONSchemaProps) (*extv1.JSONSchemaProps, error) {
 	schema := xcrd.BaseProps()
 
 	p, required, err := getProps("spec", s)
@@ -284,9 +311,12 @@ func buildSchema(s runtime.RawExtension) (*extv1.JSONSchemaProps, error) {
 	for k, v := range p {
 		specProps.Properties[k] = v
 	}
-	for k, v := range xcrd.CompositeResourceClaimSpecProps() {
+	for k, v := range xpProps {
 		specProps.Properties[k] = v
 	}
+	if specProps.XValidations, err = getValidations("spec", s); err != nil {
+		return nil, errors.Wrapf(err, errFmtGetProps, "spec")
+	}
 
 	schema.Properties["spec"] = specProps
 
@@ -302,9 +332,16 @@ func buildSchema(s runtime.RawExtension) (*extv1.JSONSchemaProps, error) {
 	for k, v := range xcrd.CompositeResourceStatusProps() {
 		statusProps.Properties[k] = v
 	}
+	if statusProps.XValidations, err = getValidations("status", s); err != nil {
+		return nil, errors.Wrapf(err, errFmtGetProps, "status")
+	}
 
 	schema.Properties["status"] = statusProps
 
+	if schema.XValidations, err = getValidations("", s); err != nil {
+		return nil, err
+	}
+
 	return schema, nil
 }
 
@@ -322,6 +359,16 @@ func newV1SchemaValidator(schema extv1.JSONSchemaProps) (*validate.SchemaValidat
 	return validate.NewSchemaValidator(openapiSchema, nil, "", strfmt.Default), openapiSchema, nil
 }
 
+// newV1CELValidator creates a CEL validator for the x-kubernetes-validations
+// rules of the given JSONSchemaProps.
+func newV1CELValidator(schema extv1.JSONSchemaProps) *CELValidator {
+	out := new(apiextensions.JSONSchemaProps)
+	if err := extv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&schema, out, nil); err != nil {
+		return nil
+	}
+	return newCELValidator(out)
+}
+
 func gvk(group, version, kind string) schema.GroupVersionKind {
 	return schema.GroupVersionKind{
 		Group:   group,
@@ -343,3 +390,17 @@ func getProps(field string, v runtime.RawExtension) (map[string]extv1.JSONSchema
 
 	return spec.Properties, spec.Required, nil
 }
+
+// getValidations returns the x-kubernetes-validations rules of the supplied
+// field of the validation schema, or of the root of the schema if the field is
+// empty.
+func getValidations(field string, v runtime.RawExtension) (extv1.ValidationRules, error) {
+	s := &extv1.JSONSchemaProps{}
+	if err := json.Unmarshal(v.Raw, s); err != nil {
+		return nil, errors.Wrap(err, errParseValidation)
+	}
+	if field == "" {
+		return s.XValidations, nil
+	}
+	return s.Properties[field].XValidations, nil
+}
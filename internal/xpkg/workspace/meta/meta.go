@@ -50,6 +50,15 @@ func New(obj runtime.Object) *Meta {
 	}
 }
 
+// Name returns the name of the package defined by the meta file.
+func (m *Meta) Name() string {
+	o, ok := m.obj.(apimetav1.Object)
+	if !ok {
+		return ""
+	}
+	return o.GetName()
+}
+
 // DependsOn returns a slice of v1beta1.Dependency that this workspace depends on.
 func (m *Meta) DependsOn() ([]v1beta1.Dependency, error) {
 	pkg, ok := scheme.TryConvertToPkg(m.obj, &v1.Provider{}, &v1.Configuration{})
@@ -184,6 +184,38 @@ func (w *Workspace) Parse() error {
 	})
 }
 
+// LoadFile reads the file at the given path from the filesystem and parses
+// it, replacing any existing content for the file in the workspace.
+func (w *Workspace) LoadFile(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b, err := afero.ReadFile(w.fs, path)
+	if err != nil {
+		return err
+	}
+
+	uri := span.URIFromPath(path)
+	if _, ok := w.view.uriToDetails[uri]; !ok {
+		w.view.uriToDetails[uri] = &Details{
+			NodeIDs: make(map[NodeIdentifier]struct{}),
+		}
+	}
+	w.view.uriToDetails[uri].Body = b
+
+	return w.view.ParseFile(path)
+}
+
+// RemoveFile removes the file at the given path, and all nodes parsed from
+// it, from the workspace.
+func (w *Workspace) RemoveFile(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.view.resetFile(path)
+	delete(w.view.uriToDetails, span.URIFromPath(path))
+}
+
 // View returns the Workspace's View. Note: this will only exist _after_
 // the Workspace has been parsed.
 func (w *Workspace) View() *View {
@@ -202,6 +234,9 @@ func (v *View) ParseFile(path string) error {
 	if err != nil {
 		return err
 	}
+	// drop any state from a previous parse of the file so that nodes that
+	// have since been removed do not linger.
+	v.resetFile(path)
 	for _, doc := range f.Docs {
 		if doc.Body != nil {
 			ctx := parseContext{
@@ -221,6 +256,31 @@ func (v *View) ParseFile(path string) error {
 	return nil
 }
 
+// resetFile removes the nodes and examples parsed from the file at the given
+// path from the view.
+func (v *View) resetFile(path string) {
+	for id, n := range v.nodes {
+		if n.GetFileName() == path {
+			delete(v.nodes, id)
+		}
+	}
+	for gvk, nodes := range v.examples {
+		keep := make([]Node, 0, len(nodes))
+		for _, n := range nodes {
+			if n.GetFileName() != path {
+				keep = append(keep, n)
+			}
+		}
+		v.examples[gvk] = keep
+	}
+	if details, ok := v.uriToDetails[span.URIFromPath(path)]; ok {
+		details.NodeIDs = make(map[NodeIdentifier]struct{})
+	}
+	if path == filepath.Join(v.metaLocation, xpkg.MetaFile) {
+		v.meta = nil
+	}
+}
+
 type parseContext struct {
 	docBytes []byte
 	node     ast.Node
@@ -140,6 +140,69 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestLoadFile(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		update func(afero.Fs, *Workspace) error
+		nodes  map[NodeIdentifier]struct{}
+		err    error
+	}{
+		"ErrorFileNotExist": {
+			reason: "Should return an error if the file does not exist.",
+			update: func(_ afero.Fs, w *Workspace) error {
+				return w.LoadFile("/ws/missing.yaml")
+			},
+			nodes: map[NodeIdentifier]struct{}{
+				nodeID("", schema.FromAPIVersionAndKind("ec2.aws.crossplane.io/v1beta1", "VPC")):               {},
+				nodeID("", schema.FromAPIVersionAndKind("ec2.aws.crossplane.io/v1beta1", "Subnet")):            {},
+				nodeID("vpcpostgresqlinstances.aws.database.example.org", xpextv1.CompositionGroupVersionKind): {},
+			},
+			err: &os.PathError{Op: "open", Path: "/ws/missing.yaml", Err: afero.ErrFileNotFound},
+		},
+		"SuccessfulReplaceNodes": {
+			reason: "Should replace the nodes parsed from a file when its content changes.",
+			update: func(fs afero.Fs, w *Workspace) error {
+				_ = afero.WriteFile(fs, "/ws/composition.yaml", testMultipleObject, os.ModePerm)
+				return w.LoadFile("/ws/composition.yaml")
+			},
+			nodes: map[NodeIdentifier]struct{}{
+				nodeID("compositepostgresqlinstances.database.example.org", xpextv1.CompositeResourceDefinitionGroupVersionKind): {},
+				nodeID("some.other.xrd", xpextv1.CompositeResourceDefinitionGroupVersionKind):                                    {},
+			},
+		},
+		"SuccessfulRemoveFile": {
+			reason: "Should remove all nodes parsed from a file when it is removed.",
+			update: func(_ afero.Fs, w *Workspace) error {
+				w.RemoveFile("/ws/composition.yaml")
+				return nil
+			},
+			nodes: map[NodeIdentifier]struct{}{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			_ = afero.WriteFile(fs, "/ws/composition.yaml", testComposition, os.ModePerm)
+			ws, _ := New("/ws", WithFS(fs))
+			if err := ws.Parse(); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.err, tc.update(fs, ws), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLoadFile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if len(tc.nodes) != len(ws.view.nodes) {
+				t.Errorf("\n%s\nLoadFile(...): -want node count: %d, +got node count: %d", tc.reason, len(tc.nodes), len(ws.view.nodes))
+			}
+			for id := range ws.view.nodes {
+				if _, ok := tc.nodes[id]; !ok {
+					t.Errorf("\n%s\nLoadFile(...): missing node:\n%v", tc.reason, id)
+				}
+			}
+		})
+	}
+}
+
 func TestRWMetaFile(t *testing.T) {
 
 	cfgMetaFile := &metav1.Configuration{
@@ -17,15 +17,34 @@ package dispatcher
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/golang/tools/lsp/protocol"
 	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/upbound/up/internal/xpkg/snapshot"
 )
 
 const (
+	errParseInitializeParams = "failed to parse initialize parameters"
 	errParseSaveParameters   = "failed to parse document save parameters"
 	errParseChangeParameters = "failed to parse document change parameters"
+	errParseHoverParameters  = "failed to parse hover parameters"
+	errParseDefinitionParams = "failed to parse definition parameters"
+	errParseReferencesParams = "failed to parse references parameters"
+	errParseCodeActionParams = "failed to parse code action parameters"
+	errParseSymbolParams     = "failed to parse symbol parameters"
+	errParseCommandParams    = "failed to parse execute command parameters"
+	errParseRenameParams     = "failed to parse rename parameters"
+	errParseFoldersParams    = "failed to parse workspace folders parameters"
+	errParseFormatParams     = "failed to parse formatting parameters"
+	errParseInlayHintParams  = "failed to parse inlay hint parameters"
+	errParseTokensParams     = "failed to parse semantic tokens parameters"
+	errReplyShutdown         = "failed to reply to request after shutdown"
+	errReplyInvalidParams    = "failed to reply to request with invalid parameters"
+
+	errShutdownFmt = "cannot handle %s after shutdown"
 )
 
 // Server defines the set of LSP methods we currently support.
@@ -34,13 +53,34 @@ type Server interface {
 	DidOpen(context.Context, *protocol.DidOpenTextDocumentParams)
 	DidSave(context.Context, *protocol.DidSaveTextDocumentParams)
 	DidChangeWatchedFiles(context.Context, *protocol.DidChangeWatchedFilesParams)
+	DidChangeWorkspaceFolders(context.Context, *protocol.DidChangeWorkspaceFoldersParams)
+	Hover(context.Context, jsonrpc2.ID, *protocol.HoverParams)
+	Definition(context.Context, jsonrpc2.ID, *protocol.DefinitionParams)
+	References(context.Context, jsonrpc2.ID, *protocol.ReferenceParams)
+	DocumentSymbol(context.Context, jsonrpc2.ID, *protocol.DocumentSymbolParams)
+	WorkspaceSymbol(context.Context, jsonrpc2.ID, *protocol.WorkspaceSymbolParams)
+	CodeAction(context.Context, jsonrpc2.ID, *protocol.CodeActionParams)
+	ExecuteCommand(context.Context, jsonrpc2.ID, *protocol.ExecuteCommandParams)
+	PrepareRename(context.Context, jsonrpc2.ID, *protocol.PrepareRenameParams)
+	Rename(context.Context, jsonrpc2.ID, *protocol.RenameParams)
+	Formatting(context.Context, jsonrpc2.ID, *protocol.DocumentFormattingParams)
+	RangeFormatting(context.Context, jsonrpc2.ID, *protocol.DocumentRangeFormattingParams)
+	InlayHint(context.Context, jsonrpc2.ID, *snapshot.InlayHintParams)
+	SemanticTokensFull(context.Context, jsonrpc2.ID, *protocol.SemanticTokensParams)
+	SemanticTokensRange(context.Context, jsonrpc2.ID, *protocol.SemanticTokensRangeParams)
 	Initialize(context.Context, *jsonrpc2.Conn, jsonrpc2.ID, *protocol.InitializeParams)
+	Shutdown(context.Context, jsonrpc2.ID)
+	Exit(context.Context)
 }
 
 // Dispatcher is responsible for routing JSONPPC request events to the
 // appropriate place.
 type Dispatcher struct {
 	log logging.Logger
+
+	// shutdown indicates that the client has requested shutdown, after which
+	// only exit is handled.
+	shutdown bool
 }
 
 // New returns a new Dispatcher.
@@ -69,24 +109,52 @@ func WithLogger(l logging.Logger) Option {
 
 // Dispatch dispatches the given JSONRPC request to the appropriate server function.
 func (d *Dispatcher) Dispatch(ctx context.Context, server Server, conn *jsonrpc2.Conn, r *jsonrpc2.Request) { // nolint:gocyclo
+	if d.shutdown && r.Method != "exit" {
+		// requests received after shutdown are errors, while notifications
+		// are dropped.
+		if !r.Notif {
+			if err := conn.ReplyWithError(ctx, r.ID, &jsonrpc2.Error{
+				Code:    jsonrpc2.CodeInvalidRequest,
+				Message: fmt.Sprintf(errShutdownFmt, r.Method),
+			}); err != nil {
+				d.log.Debug(errReplyShutdown, "error", err)
+			}
+		}
+		return
+	}
+
 	switch r.Method {
 	case "initialize":
 		var params protocol.InitializeParams
 		if err := json.Unmarshal(*r.Params, &params); err != nil {
-			// If we can't understand the initialization parmaters panic because
-			// future operations will not work.
-			panic(err)
+			d.invalidParams(ctx, conn, r, errParseInitializeParams, err)
+			return
 		}
 		server.Initialize(ctx, conn, r.ID, &params)
 		return
 	case "initialized":
 		// NOTE(hasheddan): no need to respond when the client reports initialized.
 		return
+	case "shutdown":
+		d.shutdown = true
+		server.Shutdown(ctx, r.ID)
+		return
+	case "exit":
+		server.Exit(ctx)
+		return
+	case "workspace/didChangeWorkspaceFolders":
+		var params protocol.DidChangeWorkspaceFoldersParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseFoldersParams, err)
+			return
+		}
+		server.DidChangeWorkspaceFolders(ctx, &params)
+		return
 	case "textDocument/didChange":
 		var params protocol.DidChangeTextDocumentParams
 		if err := json.Unmarshal(*r.Params, &params); err != nil {
-			d.log.Debug(errParseChangeParameters)
-			break
+			d.invalidParams(ctx, conn, r, errParseChangeParameters, err)
+			return
 		}
 		server.DidChange(ctx, &params)
 		// publish diagnostics
@@ -94,30 +162,155 @@ func (d *Dispatcher) Dispatch(ctx context.Context, server Server, conn *jsonrpc2
 	case "textDocument/didOpen":
 		var params protocol.DidOpenTextDocumentParams
 		if err := json.Unmarshal(*r.Params, &params); err != nil {
-			d.log.Debug(errParseSaveParameters)
-			break
+			d.invalidParams(ctx, conn, r, errParseSaveParameters, err)
+			return
 		}
 		server.DidOpen(ctx, &params)
 		return
 	case "textDocument/didSave":
 		var params protocol.DidSaveTextDocumentParams
 		if err := json.Unmarshal(*r.Params, &params); err != nil {
-			// If we can't parse the save parameters, log the error and skip
-			// parsing.
-			// TODO(hasheddan): surface this in diagnostics.
-			d.log.Debug(errParseSaveParameters)
-			break
+			d.invalidParams(ctx, conn, r, errParseSaveParameters, err)
+			return
 		}
 		server.DidSave(ctx, &params)
 		return
 	case "workspace/didChangeWatchedFiles":
 		var params protocol.DidChangeWatchedFilesParams
 		if err := json.Unmarshal(*r.Params, &params); err != nil {
-			d.log.Debug(errParseChangeParameters)
-			break
+			d.invalidParams(ctx, conn, r, errParseChangeParameters, err)
+			return
 		}
 
 		server.DidChangeWatchedFiles(ctx, &params)
 		return
+	case "textDocument/hover":
+		var params protocol.HoverParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseHoverParameters, err)
+			return
+		}
+		server.Hover(ctx, r.ID, &params)
+		return
+	case "textDocument/definition":
+		var params protocol.DefinitionParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseDefinitionParams, err)
+			return
+		}
+		server.Definition(ctx, r.ID, &params)
+		return
+	case "textDocument/references":
+		var params protocol.ReferenceParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseReferencesParams, err)
+			return
+		}
+		server.References(ctx, r.ID, &params)
+		return
+	case "textDocument/documentSymbol":
+		var params protocol.DocumentSymbolParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseSymbolParams, err)
+			return
+		}
+		server.DocumentSymbol(ctx, r.ID, &params)
+		return
+	case "workspace/symbol":
+		var params protocol.WorkspaceSymbolParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseSymbolParams, err)
+			return
+		}
+		server.WorkspaceSymbol(ctx, r.ID, &params)
+		return
+	case "textDocument/codeAction":
+		var params protocol.CodeActionParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseCodeActionParams, err)
+			return
+		}
+		server.CodeAction(ctx, r.ID, &params)
+		return
+	case "workspace/executeCommand":
+		var params protocol.ExecuteCommandParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseCommandParams, err)
+			return
+		}
+		server.ExecuteCommand(ctx, r.ID, &params)
+		return
+	case "textDocument/prepareRename":
+		var params protocol.PrepareRenameParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseRenameParams, err)
+			return
+		}
+		server.PrepareRename(ctx, r.ID, &params)
+		return
+	case "textDocument/rename":
+		var params protocol.RenameParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseRenameParams, err)
+			return
+		}
+		server.Rename(ctx, r.ID, &params)
+		return
+	case "textDocument/formatting":
+		var params protocol.DocumentFormattingParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseFormatParams, err)
+			return
+		}
+		server.Formatting(ctx, r.ID, &params)
+		return
+	case "textDocument/rangeFormatting":
+		var params protocol.DocumentRangeFormattingParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseFormatParams, err)
+			return
+		}
+		server.RangeFormatting(ctx, r.ID, &params)
+		return
+	case "textDocument/inlayHint":
+		var params snapshot.InlayHintParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseInlayHintParams, err)
+			return
+		}
+		server.InlayHint(ctx, r.ID, &params)
+		return
+	case "textDocument/semanticTokens/full":
+		var params protocol.SemanticTokensParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseTokensParams, err)
+			return
+		}
+		server.SemanticTokensFull(ctx, r.ID, &params)
+		return
+	case "textDocument/semanticTokens/range":
+		var params protocol.SemanticTokensRangeParams
+		if err := json.Unmarshal(*r.Params, &params); err != nil {
+			d.invalidParams(ctx, conn, r, errParseTokensParams, err)
+			return
+		}
+		server.SemanticTokensRange(ctx, r.ID, &params)
+		return
+	}
+}
+
+// invalidParams logs that the parameters of the supplied request could not be
+// parsed and, if the request expects a response, replies with an error.
+// Notifications are dropped, as they cannot be replied to.
+func (d *Dispatcher) invalidParams(ctx context.Context, conn *jsonrpc2.Conn, r *jsonrpc2.Request, msg string, err error) {
+	d.log.Debug(msg, "method", r.Method, "error", err)
+	if r.Notif {
+		return
+	}
+	if err := conn.ReplyWithError(ctx, r.ID, &jsonrpc2.Error{
+		Code:    jsonrpc2.CodeInvalidParams,
+		Message: fmt.Sprintf("%s: %s", msg, err),
+	}); err != nil {
+		d.log.Debug(errReplyInvalidParams, "error", err)
 	}
 }
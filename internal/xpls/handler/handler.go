@@ -38,6 +38,10 @@ func New(opts ...Option) (*Handler, error) {
 		log: logging.NewNopLogger(),
 	}
 
+	for _, o := range opts {
+		o(h)
+	}
+
 	server, err := server.New(server.WithLogger(h.log))
 	if err != nil {
 		return nil, err
@@ -47,10 +51,6 @@ func New(opts ...Option) (*Handler, error) {
 
 	h.dispatcher = dispatcher.New(dispatcher.WithLogger(h.log))
 
-	for _, o := range opts {
-		o(h)
-	}
-
 	return h, nil
 }
 
@@ -64,7 +64,13 @@ func WithLogger(l logging.Logger) Option {
 	}
 }
 
-// Handle handles LSP requests. It panics if we cannot initialize the workspace.
+// Handle handles LSP requests.
 func (h *Handler) Handle(ctx context.Context, conn *jsonrpc2.Conn, r *jsonrpc2.Request) { // nolint:gocyclo
 	h.dispatcher.Dispatch(ctx, h.server, conn, r)
 }
+
+// ShutdownRequested returns true if the client has requested that the server
+// shut down.
+func (h *Handler) ShutdownRequested() bool {
+	return h.server.ShutdownRequested()
+}
@@ -0,0 +1,372 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/spf13/afero"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+
+	"github.com/upbound/up/internal/xpkg"
+	"github.com/upbound/up/internal/xpkg/dep"
+	"github.com/upbound/up/internal/xpkg/parser/examples"
+	pyaml "github.com/upbound/up/internal/xpkg/parser/yaml"
+)
+
+const (
+	examplesDir = "examples"
+
+	progressTokenFmt = "xpls-%d"
+	progressBegin    = "begin"
+	progressReport   = "report"
+	progressEnd      = "end"
+
+	resolveTitle     = "Resolving dependencies"
+	addDepTitleFmt   = "Adding dependency %s"
+	buildTitle       = "Building package"
+	depAddedMsgFmt   = "Dependency %s added to xpkg cache and crossplane.yaml."
+	pkgBuiltMsgFmt   = "xpkg saved to %s"
+	progressDepFmt   = "%s (%d/%d)"
+	progressFailed   = "Failed"
+	progressComplete = "Done"
+
+	errCreateProgress  = "failed to create work done progress"
+	errProgress        = "failed to report progress"
+	errArgumentFmt     = "invalid argument %d: %s"
+	errMissingArgFmt   = "%s requires argument %d"
+	errFolderRequired  = "a uri is required when serving multiple workspace folders"
+	errAddDepFmt       = "Failed to add dependency %s: %s"
+	errBuildFmt        = "Failed to build package: %s"
+	errGetNameFromMeta = "failed to get name from crossplane.yaml"
+)
+
+// progress reports the progress of a long running command to the client.
+// Reporting is a no-op if the client did not supply a token and does not
+// support server initiated progress.
+type progress struct {
+	s     *Server
+	token protocol.ProgressToken
+}
+
+// beginProgress begins reporting progress with the supplied title. A token is
+// created if the client did not supply one. It must not be called while
+// handling a request, as creating a token requires a response from the
+// client.
+func (s *Server) beginProgress(ctx context.Context, token protocol.ProgressToken, title string) *progress {
+	p := &progress{s: s, token: token}
+	if p.token == nil {
+		if !s.workDoneProgress {
+			return p
+		}
+		token := fmt.Sprintf(progressTokenFmt, atomic.AddUint64(&s.progressID, 1))
+		if err := s.conn.Call(ctx, "window/workDoneProgress/create", &protocol.WorkDoneProgressCreateParams{Token: token}, nil); err != nil {
+			s.log.Debug(errCreateProgress, "error", err)
+			return p
+		}
+		p.token = token
+	}
+	p.notify(ctx, &protocol.WorkDoneProgressBegin{Kind: progressBegin, Title: title})
+	return p
+}
+
+// report reports the supplied message and percentage of work completed.
+func (p *progress) report(ctx context.Context, msg string, pct uint32) {
+	p.notify(ctx, &protocol.WorkDoneProgressReport{Kind: progressReport, Message: msg, Percentage: pct})
+}
+
+// end reports that work has completed with the supplied message.
+func (p *progress) end(ctx context.Context, msg string) {
+	p.notify(ctx, &protocol.WorkDoneProgressEnd{Kind: progressEnd, Message: msg})
+}
+
+func (p *progress) notify(ctx context.Context, value any) {
+	if p.token == nil {
+		return
+	}
+	if err := p.s.conn.Notify(ctx, "$/progress", &protocol.ProgressParams{Token: p.token, Value: value}); err != nil {
+		p.s.log.Debug(errProgress, "error", err)
+	}
+}
+
+// resolveDependencies adds the dependencies declared in the crossplane.yaml
+// of each workspace folder to the cache. Resolution happens in the background
+// and snapshots are refreshed once all packages have been added.
+func (s *Server) resolveDependencies(ctx context.Context, token protocol.ProgressToken) {
+	s.mu.RLock()
+	deps := []v1beta1.Dependency{}
+	for _, f := range s.folders {
+		fd, err := f.snap.Dependencies()
+		if err != nil {
+			s.log.Debug(errDependencies, "error", err)
+			continue
+		}
+		deps = append(deps, fd...)
+	}
+	roots := s.roots()
+	s.mu.RUnlock()
+
+	go func() {
+		p := s.beginProgress(ctx, token, resolveTitle)
+		defer s.scheduleRebuild(ctx, roots...)
+		for i, d := range deps {
+			p.report(ctx, fmt.Sprintf(progressDepFmt, d.Package, i+1, len(deps)), uint32(i*100/len(deps)))
+			if _, _, err := s.m.AddAll(ctx, d); err != nil {
+				p.end(ctx, progressFailed)
+				s.showMessage(ctx, &protocol.ShowMessageParams{
+					Type:    protocol.Error,
+					Message: fmt.Sprintf(errResolveDepFmt, d.Package, err),
+				})
+				return
+			}
+		}
+		p.end(ctx, progressComplete)
+		s.showMessage(ctx, &protocol.ShowMessageParams{
+			Type:    protocol.Info,
+			Message: depsResolvedMsg,
+		})
+	}()
+}
+
+// addDependency adds the supplied package to the cache and to the
+// dependencies declared in the crossplane.yaml of the supplied folder. The
+// package is added in the background and the folder's snapshot is refreshed
+// once it has been added.
+func (s *Server) addDependency(ctx context.Context, token protocol.ProgressToken, pkg string, f *folder) error {
+	if _, err := xpkg.ValidDep(pkg); err != nil {
+		return err
+	}
+
+	go func() {
+		p := s.beginProgress(ctx, token, fmt.Sprintf(addDepTitleFmt, pkg))
+		defer s.scheduleRebuild(ctx, f.root)
+		if err := s.addDependencyToFolder(ctx, pkg, f); err != nil {
+			p.end(ctx, progressFailed)
+			s.showMessage(ctx, &protocol.ShowMessageParams{
+				Type:    protocol.Error,
+				Message: fmt.Sprintf(errAddDepFmt, pkg, err),
+			})
+			return
+		}
+		p.end(ctx, progressComplete)
+		s.showMessage(ctx, &protocol.ShowMessageParams{
+			Type:    protocol.Info,
+			Message: fmt.Sprintf(depAddedMsgFmt, pkg),
+		})
+	}()
+	return nil
+}
+
+// addDependencyToFolder adds the supplied package to the cache and to the
+// crossplane.yaml of the supplied folder.
+func (s *Server) addDependencyToFolder(ctx context.Context, pkg string, f *folder) error {
+	ud, _, err := s.m.AddAll(ctx, dep.New(pkg))
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return f.snap.AddDependency(ud)
+}
+
+// build builds the package in the supplied folder, writing it to the root of
+// the folder. The package is built in the background and the folder's
+// snapshot is refreshed once it has been written.
+func (s *Server) build(ctx context.Context, token protocol.ProgressToken, f *folder) {
+	go func() {
+		p := s.beginProgress(ctx, token, buildTitle)
+		defer s.scheduleRebuild(ctx, f.root)
+		out, err := buildPackage(ctx, afero.NewOsFs(), f.root.Filename())
+		if err != nil {
+			p.end(ctx, progressFailed)
+			s.showMessage(ctx, &protocol.ShowMessageParams{
+				Type:    protocol.Error,
+				Message: fmt.Sprintf(errBuildFmt, err),
+			})
+			return
+		}
+		msg := fmt.Sprintf(pkgBuiltMsgFmt, out)
+		p.end(ctx, msg)
+		s.showMessage(ctx, &protocol.ShowMessageParams{
+			Type:    protocol.Info,
+			Message: msg,
+		})
+	}()
+}
+
+// buildPackage builds the package rooted at the supplied path in the same
+// manner as up xpkg build, returning the path it was written to.
+func buildPackage(ctx context.Context, fs afero.Fs, root string) (string, error) {
+	pp, err := pyaml.New()
+	if err != nil {
+		return "", err
+	}
+	filters := []parser.FilterFn{
+		parser.SkipDirs(),
+		parser.SkipNotYAML(),
+		parser.SkipEmpty(),
+	}
+	b := xpkg.New(
+		parser.NewFsBackend(fs, parser.FsDir(root), parser.FsFilters(append(filters, xpkg.SkipContains(examplesDir+"/"))...)),
+		parser.NewFsBackend(fs, parser.FsDir(filepath.Join(root, examplesDir)), parser.FsFilters(filters...)),
+		pp,
+		examples.New(),
+	)
+
+	img, meta, err := b.Build(ctx)
+	if err != nil {
+		return "", err
+	}
+	hash, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	pkgMeta, ok := meta.(metav1.Object)
+	if !ok {
+		return "", errors.New(errGetNameFromMeta)
+	}
+	out := xpkg.BuildPath(root, xpkg.FriendlyID(pkgMeta.GetName(), hash.Hex))
+
+	f, err := fs.Create(out)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	if err := tarball.Write(nil, img, f); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// executeAddDependency adds the package in the first argument to the folder
+// containing the optional uri in the second argument.
+func (s *Server) executeAddDependency(ctx context.Context, params *protocol.ExecuteCommandParams) error {
+	pkg, err := stringArg(params.Arguments, 0)
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		return fmt.Errorf(errMissingArgFmt, params.Command, 0)
+	}
+	uri, err := uriArg(params.Arguments, 1)
+	if err != nil {
+		return err
+	}
+	s.mu.RLock()
+	f, err := s.commandFolder(uri)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return s.addDependency(ctx, params.WorkDoneToken, pkg, f)
+}
+
+// executeBuild builds the package in the folder containing the optional uri
+// in the first argument.
+func (s *Server) executeBuild(ctx context.Context, params *protocol.ExecuteCommandParams) error {
+	uri, err := uriArg(params.Arguments, 0)
+	if err != nil {
+		return err
+	}
+	s.mu.RLock()
+	f, err := s.commandFolder(uri)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	s.build(ctx, params.WorkDoneToken, f)
+	return nil
+}
+
+// executeRender renders the Composition in the file at the uri in the first
+// argument, using the example in the file at the optional uri in the second
+// argument. The rendered resources are returned as a YAML string.
+func (s *Server) executeRender(ctx context.Context, params *protocol.ExecuteCommandParams) (any, error) {
+	uri, err := uriArg(params.Arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	if uri == "" {
+		return nil, fmt.Errorf(errMissingArgFmt, params.Command, 0)
+	}
+	example, err := uriArg(params.Arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap, ok := s.snapshotFor(uri)
+	if !ok {
+		return nil, fmt.Errorf(errNotInWorkspaceFmt, uri)
+	}
+	out, err := snap.Render(ctx, uri, example)
+	if err != nil {
+		return nil, err
+	}
+	return string(out), nil
+}
+
+// stringArg returns the string command argument at the supplied index. An
+// empty string is returned if the argument was not supplied.
+func stringArg(args []json.RawMessage, i int) (string, error) {
+	if i >= len(args) {
+		return "", nil
+	}
+	var v string
+	if err := json.Unmarshal(args[i], &v); err != nil {
+		return "", fmt.Errorf(errArgumentFmt, i, err)
+	}
+	return v, nil
+}
+
+// uriArg returns the uri command argument at the supplied index. An empty
+// uri is returned if the argument was not supplied.
+func uriArg(args []json.RawMessage, i int) (span.URI, error) {
+	v, err := stringArg(args, i)
+	if err != nil || v == "" {
+		return "", err
+	}
+	return protocol.DocumentURI(v).SpanURI(), nil
+}
+
+// commandFolder returns the folder containing the supplied uri. If no uri is
+// supplied the only folder is returned. Callers must hold a read lock on the
+// server.
+func (s *Server) commandFolder(uri span.URI) (*folder, error) {
+	if uri == "" {
+		if len(s.folders) != 1 {
+			return nil, errors.New(errFolderRequired)
+		}
+		return s.folders[0], nil
+	}
+	f, ok := s.folderFor(uri)
+	if !ok {
+		return nil, fmt.Errorf(errNotInWorkspaceFmt, uri)
+	}
+	return f, nil
+}
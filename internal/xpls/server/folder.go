@@ -0,0 +1,166 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+
+	"github.com/upbound/up/internal/xpkg/snapshot"
+)
+
+// A folder is a workspace folder served by the Server. Each folder is a
+// separate package with its own snapshot.
+type folder struct {
+	root    span.URI
+	factory *snapshot.Factory
+	snap    *snapshot.Snapshot
+}
+
+// newFolder constructs a folder for the workspace rooted at the supplied uri
+// and takes its initial snapshot.
+func (s *Server) newFolder(root span.URI) (*folder, error) {
+	factory, err := snapshot.NewFactory(
+		root.Filename(),
+		snapshot.WithLogger(s.log),
+		snapshot.WithDepManager(s.m),
+	)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := factory.New()
+	if err != nil {
+		return nil, err
+	}
+	return &folder{
+		root:    root,
+		factory: factory,
+		snap:    snap,
+	}, nil
+}
+
+// addFolder adds a folder for the workspace rooted at the supplied uri,
+// replacing any existing folder with the same root. Callers must hold a write
+// lock on the server.
+func (s *Server) addFolder(root span.URI) (*folder, error) {
+	f, err := s.newFolder(root)
+	if err != nil {
+		return nil, err
+	}
+	s.removeFolder(root)
+	s.folders = append(s.folders, f)
+	return f, nil
+}
+
+// removeFolder removes the folder rooted at the supplied uri, returning it if
+// it existed. Callers must hold a write lock on the server.
+func (s *Server) removeFolder(root span.URI) (*folder, bool) {
+	for i, f := range s.folders {
+		if f.root == root {
+			s.folders = append(s.folders[:i], s.folders[i+1:]...)
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// folderFor returns the folder containing the supplied uri. If folders are
+// nested, the innermost folder is returned. Callers must hold a read lock on
+// the server.
+func (s *Server) folderFor(uri span.URI) (*folder, bool) {
+	var match *folder
+	for _, f := range s.folders {
+		if !contains(f.root, uri) {
+			continue
+		}
+		if match == nil || len(f.root) > len(match.root) {
+			match = f
+		}
+	}
+	return match, match != nil
+}
+
+// snapshotFor returns the snapshot of the folder containing the supplied uri.
+// Callers must hold a read lock on the server.
+func (s *Server) snapshotFor(uri span.URI) (*snapshot.Snapshot, bool) {
+	f, ok := s.folderFor(uri)
+	if !ok {
+		return nil, false
+	}
+	return f.snap, true
+}
+
+// roots returns the roots of all folders. Callers must hold a read lock on the
+// server.
+func (s *Server) roots() []span.URI {
+	roots := make([]span.URI, len(s.folders))
+	for i, f := range s.folders {
+		roots[i] = f.root
+	}
+	return roots
+}
+
+// publishFolder publishes diagnostics for every file in the supplied folder.
+func (s *Server) publishFolder(ctx context.Context, f *folder) {
+	validations, err := f.snap.ValidateAllFiles()
+	if err != nil {
+		s.log.Debug(errValidateNodes, "error", err)
+		return
+	}
+	for uri, diags := range validations {
+		s.publishDiagnostics(ctx, &protocol.PublishDiagnosticsParams{
+			URI:         protocol.URIFromSpanURI(uri),
+			Diagnostics: diags,
+		})
+	}
+}
+
+// clearFolder clears any diagnostics published for files in the supplied
+// folder.
+func (s *Server) clearFolder(ctx context.Context, f *folder) {
+	for _, uri := range f.snap.Files() {
+		s.publishDiagnostics(ctx, &protocol.PublishDiagnosticsParams{
+			URI:         protocol.URIFromSpanURI(uri),
+			Diagnostics: []protocol.Diagnostic{},
+		})
+	}
+}
+
+// workspaceRoots returns the roots of the workspace folders supplied by the
+// client. The root uri is used if the client does not support workspace
+// folders.
+func workspaceRoots(params *protocol.InitializeParams) []span.URI {
+	if len(params.WorkspaceFolders) == 0 {
+		if params.RootURI == "" {
+			return nil
+		}
+		return []span.URI{params.RootURI.SpanURI()}
+	}
+	roots := make([]span.URI, len(params.WorkspaceFolders))
+	for i, f := range params.WorkspaceFolders {
+		roots[i] = protocol.DocumentURI(f.URI).SpanURI()
+	}
+	return roots
+}
+
+// contains returns true if the supplied uri is within the supplied root.
+func contains(root, uri span.URI) bool {
+	r, u := root.Filename(), uri.Filename()
+	return u == r || strings.HasPrefix(u, strings.TrimSuffix(r, string(os.PathSeparator))+string(os.PathSeparator))
+}
@@ -17,18 +17,21 @@ package server
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/golang/tools/lsp/protocol"
 	"github.com/golang/tools/span"
+	"github.com/pkg/errors"
 	"github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/jsonrpc2"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 
 	"github.com/upbound/up/internal/version"
+	"github.com/upbound/up/internal/xpkg"
 	"github.com/upbound/up/internal/xpkg/dep/manager"
 	"github.com/upbound/up/internal/xpkg/snapshot"
 )
@@ -40,19 +43,71 @@ var (
 
 const (
 	defaultWatchInterval = "100ms"
-	fileProtocol         = "file://"
-	fileWatchGlob        = "**/*.yaml"
-	newVersionMsgFmt     = `Version %s of up is now available. Current version is %s.
+	// defaultDebounceInterval is the default interval to wait for further
+	// edits before revalidating.
+	defaultDebounceInterval = "200ms"
+	fileProtocol            = "file://"
+	fileWatchGlob           = "**/*.yaml"
+	newVersionMsgFmt        = `Version %s of up is now available. Current version is %s.
 	Update for the latest features!`
 
 	errParseWorkspace     = "failed to parse workspace"
 	errPublishDiagnostics = "failed to publish diagnostics"
 	errRegisteringWatches = "failed to register workspace watchers"
+	errReply              = "failed to reply to request"
 	errValidateMeta       = "failed to validate crossplane.yaml file in workspace"
 	errShowMessage        = "failed to show message"
 	errValidateNodes      = "failed to validate nodes in workspace"
+	errHover              = "failed to get hover details"
+	errDefinition         = "failed to get definition locations"
+	errReferences         = "failed to get reference locations"
+	errCodeActions        = "failed to get code actions"
+	errDocumentSymbols    = "failed to get document symbols"
+	errWorkspaceSymbols   = "failed to get workspace symbols"
+	errDependencies       = "failed to get workspace dependencies"
+	errResolveDepFmt      = "Failed to resolve dependency %s: %s"
+	errUnknownCommandFmt  = "unknown command %s"
+	errPrepareRename      = "failed to prepare rename"
+	errInlayHints         = "failed to get inlay hints"
+	errSemanticTokens     = "failed to get semantic tokens"
+	errCloseConn          = "failed to close connection"
+	errNotInWorkspaceFmt  = "%s is not within a workspace folder"
+	errAddFolderFmt       = "failed to add workspace folder %s"
+
+	depsResolvedMsg = "Dependencies added to xpkg cache."
 )
 
+// initializeResult is the result of an initialize request.
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+}
+
+// serverCapabilities extends the capabilities supported by lsp with those
+// that require options the lsp package does not model.
+type serverCapabilities struct {
+	lsp.ServerCapabilities
+
+	// RenameProvider shadows the boolean rename provider so that prepare
+	// rename support can be advertised.
+	RenameProvider         *protocol.RenameOptions         `json:"renameProvider,omitempty"`
+	InlayHintProvider      bool                            `json:"inlayHintProvider,omitempty"`
+	SemanticTokensProvider *protocol.SemanticTokensOptions `json:"semanticTokensProvider,omitempty"`
+	Workspace              *workspaceCapabilities          `json:"workspace,omitempty"`
+}
+
+// workspaceCapabilities are the workspace specific capabilities of the
+// server.
+type workspaceCapabilities struct {
+	WorkspaceFolders workspaceFoldersCapabilities `json:"workspaceFolders"`
+}
+
+// workspaceFoldersCapabilities describe support for multiple workspace
+// folders.
+type workspaceFoldersCapabilities struct {
+	Supported           bool `json:"supported"`
+	ChangeNotifications bool `json:"changeNotifications"`
+}
+
 // Server services incoming LSP requests.
 type Server struct {
 	conn *jsonrpc2.Conn
@@ -60,18 +115,38 @@ type Server struct {
 	i   *version.Informer
 	log logging.Logger
 	m   *manager.Manager
-	mu  sync.RWMutex
-
-	root span.URI
-
-	snapFactory *snapshot.Factory
-	snap        *snapshot.Snapshot
+	// mu guards folders and their snapshots.
+	mu sync.RWMutex
+
+	// folders are the workspace folders served by the Server, each of which
+	// has its own snapshot.
+	folders []*folder
+
+	// workDoneProgress indicates whether the client supports server
+	// initiated progress, while progressID is used to generate unique
+	// progress tokens.
+	workDoneProgress bool
+	progressID       uint64
+
+	// debounce is the interval to wait for further changes before
+	// processing scheduled work.
+	debounce time.Duration
+
+	// pmu guards the scheduled work below, as well as whether shutdown has
+	// been requested.
+	pmu      sync.Mutex
+	pending  map[span.URI]struct{}
+	rebuild  map[span.URI]struct{}
+	timer    *time.Timer
+	shutdown bool
 }
 
 // New returns a new Server.
 func New(opts ...Option) (*Server, error) {
 	s := &Server{
-		log: logging.NewNopLogger(),
+		log:     logging.NewNopLogger(),
+		pending: make(map[span.URI]struct{}),
+		rebuild: make(map[span.URI]struct{}),
 	}
 
 	interval, err := time.ParseDuration(defaultWatchInterval)
@@ -79,6 +154,16 @@ func New(opts ...Option) (*Server, error) {
 		return nil, err
 	}
 
+	debounce, err := time.ParseDuration(defaultDebounceInterval)
+	if err != nil {
+		return nil, err
+	}
+	s.debounce = debounce
+
+	for _, o := range opts {
+		o(s)
+	}
+
 	// TODO(@tnthornton) supply cache root from Config here.
 	m, err := manager.New(
 		manager.WithLogger(s.log),
@@ -106,6 +191,14 @@ func WithLogger(l logging.Logger) Option {
 	}
 }
 
+// WithDebounce overrides the default interval the Server waits for further
+// changes before revalidating changed files.
+func WithDebounce(d time.Duration) Option {
+	return func(s *Server) {
+		s.debounce = d
+	}
+}
+
 // Initialize handles calls to Initialize.
 func (s *Server) Initialize(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc2.ID, params *protocol.InitializeParams) {
 
@@ -115,41 +208,70 @@ func (s *Server) Initialize(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc
 	// It will make testing easier if we are in control of it versus relying on
 	// the handler to pass it down.
 	s.conn = conn
-	s.root = params.RootURI.SpanURI()
-
-	factory, err := snapshot.NewFactory(
-		s.root.Filename(),
-		snapshot.WithLogger(s.log),
-		snapshot.WithDepManager(s.m),
-	)
-	if err != nil {
-		panic(err)
-	}
+	s.workDoneProgress = params.Capabilities.Window.WorkDoneProgress
 
-	s.snapFactory = factory
-
-	snap, err := s.snapFactory.New()
-	if err != nil {
-		panic(err)
+	s.mu.Lock()
+	for _, root := range workspaceRoots(params) {
+		if _, err := s.addFolder(root); err != nil {
+			s.mu.Unlock()
+			s.replyWithError(ctx, id, errors.Wrapf(err, errAddFolderFmt, root.Filename()))
+			return
+		}
 	}
-
-	s.snap = snap
+	s.mu.Unlock()
 
 	s.watchSnapshot(context.Background()) //nolint:contextcheck  // TODO(epk) thread through top level context
 
 	// TODO (@tnthornton) move to using protocol.InitializeResult
-	reply := &lsp.InitializeResult{
-		Capabilities: lsp.ServerCapabilities{
-			TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
-				Kind: &kind,
+	reply := &initializeResult{
+		Capabilities: serverCapabilities{
+			ServerCapabilities: lsp.ServerCapabilities{
+				TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
+					Kind: &kind,
+				},
+				HoverProvider:                   true,
+				DefinitionProvider:              true,
+				ReferencesProvider:              true,
+				CodeActionProvider:              true,
+				DocumentSymbolProvider:          true,
+				WorkspaceSymbolProvider:         true,
+				DocumentFormattingProvider:      true,
+				DocumentRangeFormattingProvider: true,
+				ExecuteCommandProvider: &lsp.ExecuteCommandOptions{
+					Commands: []string{
+						snapshot.CommandResolveDependencies,
+						snapshot.CommandAddDependency,
+						snapshot.CommandBuild,
+						snapshot.CommandRender,
+					},
+				},
+			},
+			RenameProvider: &protocol.RenameOptions{
+				PrepareProvider: true,
+			},
+			InlayHintProvider: true,
+			SemanticTokensProvider: &protocol.SemanticTokensOptions{
+				Legend: protocol.SemanticTokensLegend{
+					TokenTypes:     snapshot.SemanticTokenTypes,
+					TokenModifiers: snapshot.SemanticTokenModifiers,
+				},
+				Range: true,
+				Full:  true,
+			},
+			Workspace: &workspaceCapabilities{
+				WorkspaceFolders: workspaceFoldersCapabilities{
+					Supported:           true,
+					ChangeNotifications: true,
+				},
 			},
 		},
 	}
 
 	if err := s.conn.Reply(ctx, id, reply); err != nil {
-		// If we fail to initialize the workspace we won't receive future
-		// messages so we panic and try again on restart.
-		panic(err)
+		// If the client never receives the initialize result it will not
+		// send further messages, so there is nothing left to set up.
+		s.log.Debug(errReply, "error", err)
+		return
 	}
 
 	s.registerWatchFilesCapability(context.Background()) //nolint:contextcheck // TODO(epk) thread through top level context
@@ -157,106 +279,543 @@ func (s *Server) Initialize(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc
 	s.checkForUpdates(context.Background())              //nolint:contextcheck // TODO(epk) thread through top level context
 }
 
+// Shutdown handles calls to Shutdown. Scheduled work is discarded and no
+// further work is scheduled.
+func (s *Server) Shutdown(ctx context.Context, id jsonrpc2.ID) {
+	s.pmu.Lock()
+	s.shutdown = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.pending = make(map[span.URI]struct{})
+	s.rebuild = make(map[span.URI]struct{})
+	s.pmu.Unlock()
+
+	s.reply(ctx, id, nil)
+}
+
+// Exit handles calls to Exit by closing the connection to the client.
+func (s *Server) Exit(_ context.Context) {
+	if s.conn == nil {
+		return
+	}
+	if err := s.conn.Close(); err != nil {
+		s.log.Debug(errCloseConn, "error", err)
+	}
+}
+
+// ShutdownRequested returns true if the client has requested that the Server
+// shut down.
+func (s *Server) ShutdownRequested() bool {
+	s.pmu.Lock()
+	defer s.pmu.Unlock()
+	return s.shutdown
+}
+
+// DidChangeWorkspaceFolders handles calls to DidChangeWorkspaceFolders.
+func (s *Server) DidChangeWorkspaceFolders(ctx context.Context, params *protocol.DidChangeWorkspaceFoldersParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, wf := range params.Event.Removed {
+		if f, ok := s.removeFolder(protocol.DocumentURI(wf.URI).SpanURI()); ok {
+			s.clearFolder(ctx, f)
+		}
+	}
+	for _, wf := range params.Event.Added {
+		f, err := s.addFolder(protocol.DocumentURI(wf.URI).SpanURI())
+		if err != nil {
+			s.log.Debug(errParseWorkspace, "error", err)
+			continue
+		}
+		s.publishFolder(ctx, f)
+	}
+}
+
 // DidChange handles calls to DidChange.
 func (s *Server) DidChange(ctx context.Context, params *protocol.DidChangeTextDocumentParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	uri := params.TextDocument.URI.SpanURI()
-	filename := uri.Filename()
+	snap, ok := s.snapshotFor(uri)
+	if !ok {
+		s.log.Debug(fmt.Sprintf(errNotInWorkspaceFmt, uri))
+		return
+	}
 
 	// update snapshot for changes seen
-	err := s.snap.UpdateContent(ctx, uri, params.ContentChanges)
-	if err != nil {
+	if err := snap.UpdateContent(ctx, uri, params.ContentChanges); err != nil {
 		s.log.Debug(err.Error())
 		return
 	}
 
-	if err := s.snap.ReParseFile(filename); err != nil {
+	uris, err := snap.ReParseFile(uri.Filename())
+	if err != nil {
 		s.log.Debug(err.Error())
 		return
 	}
+	s.schedule(ctx, uris...)
+}
+
+// DidOpen handles calls to DidOpen.
+func (s *Server) DidOpen(ctx context.Context, params *protocol.DidOpenTextDocumentParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.publishFile(ctx, params.TextDocument.URI.SpanURI())
+}
+
+// DidSave handles calls to DidSave.
+func (s *Server) DidSave(ctx context.Context, params *protocol.DidSaveTextDocumentParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	uri := params.TextDocument.URI.SpanURI()
+	f, ok := s.folderFor(uri)
+	if !ok {
+		return
+	}
+	// changes to the meta file may change the dependencies of the workspace,
+	// which requires a new snapshot.
+	if isMetaFile(uri) {
+		s.scheduleRebuild(ctx, f.root)
+		return
+	}
+
+	// the in-memory content of the file is already up to date, so cached
+	// diagnostics remain valid.
+	s.publishFile(ctx, uri)
+}
+
+// DidChangeWatchedFiles handles calls to DidChangeWatchedFiles.
+func (s *Server) DidChangeWatchedFiles(ctx context.Context, params *protocol.DidChangeWatchedFilesParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, c := range params.Changes {
+		// only attempt to handle changes for files
+		if !strings.HasPrefix(string(c.URI), fileProtocol) {
+			continue
+		}
+		uri := c.URI.SpanURI()
+		f, ok := s.folderFor(uri)
+		if !ok {
+			continue
+		}
+		if isMetaFile(uri) {
+			s.scheduleRebuild(ctx, f.root)
+			continue
+		}
+
+		var uris []span.URI
+		var err error
+		switch c.Type {
+		case protocol.Deleted:
+			uris, err = f.snap.RemoveFile(uri.Filename())
+			s.publishDiagnostics(ctx, &protocol.PublishDiagnosticsParams{
+				URI:         c.URI,
+				Diagnostics: []protocol.Diagnostic{},
+			})
+		default:
+			uris, err = f.snap.LoadFile(uri.Filename())
+		}
+		if err != nil {
+			s.log.Debug(errParseWorkspace, "error", err)
+			continue
+		}
+		s.schedule(ctx, uris...)
+	}
+}
+
+// Hover handles calls to Hover.
+func (s *Server) Hover(ctx context.Context, id jsonrpc2.ID, params *protocol.HoverParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	// TODO(hasheddan): diagnostics should be cached and validation should
-	// be performed selectively.
-	diags, err := s.snap.Validate(params.TextDocument.URI.SpanURI())
+	snap, ok := s.snapshotFor(params.TextDocument.URI.SpanURI())
+	if !ok {
+		s.reply(ctx, id, nil)
+		return
+	}
+	hover, err := snap.Hover(params.TextDocument.URI.SpanURI(), params.Position)
 	if err != nil {
-		s.log.Debug(errValidateNodes, "error", err)
+		s.log.Debug(errHover, "error", err)
+	}
+	s.reply(ctx, id, hover)
+}
+
+// Definition handles calls to Definition.
+func (s *Server) Definition(ctx context.Context, id jsonrpc2.ID, params *protocol.DefinitionParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap, ok := s.snapshotFor(params.TextDocument.URI.SpanURI())
+	if !ok {
+		s.reply(ctx, id, nil)
 		return
 	}
-	reply := &protocol.PublishDiagnosticsParams{
-		URI:         params.TextDocument.URI,
-		Diagnostics: diags,
+	locs, err := snap.Definition(params.TextDocument.URI.SpanURI(), params.Position)
+	if err != nil {
+		s.log.Debug(errDefinition, "error", err)
 	}
-	s.publishDiagnostics(ctx, reply)
+	s.reply(ctx, id, locs)
 }
 
-// DidOpen handles calls to DidOpen.
-func (s *Server) DidOpen(ctx context.Context, params *protocol.DidOpenTextDocumentParams) {
-	diags, err := s.snap.Validate(params.TextDocument.URI.SpanURI())
+// References handles calls to References.
+func (s *Server) References(ctx context.Context, id jsonrpc2.ID, params *protocol.ReferenceParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap, ok := s.snapshotFor(params.TextDocument.URI.SpanURI())
+	if !ok {
+		s.reply(ctx, id, nil)
+		return
+	}
+	locs, err := snap.References(params.TextDocument.URI.SpanURI(), params.Position, params.Context.IncludeDeclaration)
 	if err != nil {
-		s.log.Debug(errValidateNodes, "error", err)
+		s.log.Debug(errReferences, "error", err)
+	}
+	s.reply(ctx, id, locs)
+}
+
+// DocumentSymbol handles calls to DocumentSymbol.
+func (s *Server) DocumentSymbol(ctx context.Context, id jsonrpc2.ID, params *protocol.DocumentSymbolParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap, ok := s.snapshotFor(params.TextDocument.URI.SpanURI())
+	if !ok {
+		s.reply(ctx, id, nil)
 		return
 	}
-	reply := &protocol.PublishDiagnosticsParams{
-		URI:         params.TextDocument.URI,
-		Diagnostics: diags,
+	syms, err := snap.DocumentSymbols(params.TextDocument.URI.SpanURI())
+	if err != nil {
+		s.log.Debug(errDocumentSymbols, "error", err)
 	}
-	s.publishDiagnostics(ctx, reply)
+	s.reply(ctx, id, syms)
 }
 
-// DidSave handles calls to DidSave.
-func (s *Server) DidSave(ctx context.Context, params *protocol.DidSaveTextDocumentParams) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	// create new snapshot here
-	snap, err := s.snapFactory.New()
+// WorkspaceSymbol handles calls to WorkspaceSymbol. Symbols are returned from
+// all workspace folders.
+func (s *Server) WorkspaceSymbol(ctx context.Context, id jsonrpc2.ID, params *protocol.WorkspaceSymbolParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	syms := []protocol.SymbolInformation{}
+	for _, f := range s.folders {
+		fs, err := f.snap.WorkspaceSymbols(params.Query)
+		if err != nil {
+			s.log.Debug(errWorkspaceSymbols, "error", err)
+			continue
+		}
+		syms = append(syms, fs...)
+	}
+	s.reply(ctx, id, syms)
+}
+
+// CodeAction handles calls to CodeAction.
+func (s *Server) CodeAction(ctx context.Context, id jsonrpc2.ID, params *protocol.CodeActionParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// we only offer quick fixes.
+	snap, ok := s.snapshotFor(params.TextDocument.URI.SpanURI())
+	if !ok || !kindRequested(protocol.QuickFix, params.Context.Only) {
+		s.reply(ctx, id, []protocol.CodeAction{})
+		return
+	}
+
+	actions, err := snap.CodeActions(ctx, params.TextDocument.URI.SpanURI(), params.Range)
 	if err != nil {
-		s.log.Debug(errParseWorkspace, "error", err)
+		s.log.Debug(errCodeActions, "error", err)
+	}
+	s.reply(ctx, id, actions)
+}
+
+// ExecuteCommand handles calls to ExecuteCommand. Long running commands are
+// executed in the background, reporting their progress to the client, and
+// refresh the affected snapshots when they finish.
+func (s *Server) ExecuteCommand(ctx context.Context, id jsonrpc2.ID, params *protocol.ExecuteCommandParams) {
+	var (
+		result any
+		err    error
+	)
+	// background commands outlive the request.
+	bg := context.Background()
+	switch params.Command {
+	case snapshot.CommandResolveDependencies:
+		s.resolveDependencies(bg, params.WorkDoneToken) //nolint:contextcheck // resolution outlives the request
+	case snapshot.CommandAddDependency:
+		err = s.executeAddDependency(bg, params) //nolint:contextcheck // resolution outlives the request
+	case snapshot.CommandBuild:
+		err = s.executeBuild(bg, params) //nolint:contextcheck // building outlives the request
+	case snapshot.CommandRender:
+		result, err = s.executeRender(ctx, params)
+	default:
+		if err := s.conn.ReplyWithError(ctx, id, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf(errUnknownCommandFmt, params.Command),
+		}); err != nil {
+			s.log.Debug(errReply, "error", err)
+		}
+		return
+	}
+	if err != nil {
+		s.replyWithError(ctx, id, err)
 		return
 	}
-	s.snap = snap
+	s.reply(ctx, id, result)
+}
+
+// PrepareRename handles calls to PrepareRename.
+func (s *Server) PrepareRename(ctx context.Context, id jsonrpc2.ID, params *protocol.PrepareRenameParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	diags, err := s.snap.Validate(params.TextDocument.URI.SpanURI())
+	snap, ok := s.snapshotFor(params.TextDocument.URI.SpanURI())
+	if !ok {
+		s.reply(ctx, id, nil)
+		return
+	}
+	rng, err := snap.PrepareRename(params.TextDocument.URI.SpanURI(), params.Position)
 	if err != nil {
-		s.log.Debug(errValidateNodes, "error", err)
+		s.log.Debug(errPrepareRename, "error", err)
+	}
+	s.reply(ctx, id, rng)
+}
+
+// Rename handles calls to Rename.
+func (s *Server) Rename(ctx context.Context, id jsonrpc2.ID, params *protocol.RenameParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	uri := params.TextDocument.URI.SpanURI()
+	snap, ok := s.snapshotFor(uri)
+	if !ok {
+		s.replyWithError(ctx, id, errors.Errorf(errNotInWorkspaceFmt, uri))
 		return
 	}
-	reply := &protocol.PublishDiagnosticsParams{
-		URI:         params.TextDocument.URI,
-		Diagnostics: diags,
+	edit, err := snap.Rename(uri, params.Position, params.NewName)
+	if err != nil {
+		s.replyWithError(ctx, id, err)
+		return
 	}
-	s.publishDiagnostics(ctx, reply)
+	s.reply(ctx, id, edit)
 }
 
-// DidChangeWatchedFiles handles calls to DidChangeWatchedFiles.
-func (s *Server) DidChangeWatchedFiles(ctx context.Context, params *protocol.DidChangeWatchedFilesParams) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Formatting handles calls to Formatting.
+func (s *Server) Formatting(ctx context.Context, id jsonrpc2.ID, params *protocol.DocumentFormattingParams) {
+	s.format(ctx, id, params.TextDocument.URI.SpanURI(), nil)
+}
+
+// RangeFormatting handles calls to RangeFormatting.
+func (s *Server) RangeFormatting(ctx context.Context, id jsonrpc2.ID, params *protocol.DocumentRangeFormattingParams) {
+	s.format(ctx, id, params.TextDocument.URI.SpanURI(), &params.Range)
+}
+
+// format replies with the edits required to format the documents in the
+// supplied file that intersect the supplied range, or all documents if no
+// range is supplied.
+func (s *Server) format(ctx context.Context, id jsonrpc2.ID, uri span.URI, rng *protocol.Range) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	snap, err := s.snapFactory.New()
+	snap, ok := s.snapshotFor(uri)
+	if !ok {
+		s.reply(ctx, id, []protocol.TextEdit{})
+		return
+	}
+	edits, err := snap.Format(uri, rng)
 	if err != nil {
-		s.log.Debug(errParseWorkspace, "error", err)
+		s.replyWithError(ctx, id, err)
 		return
 	}
-	s.snap = snap
+	s.reply(ctx, id, edits)
+}
 
-	accDiags := make([]*protocol.PublishDiagnosticsParams, 0)
-	for _, c := range params.Changes {
-		// only attempt to handle changes for files
-		if strings.HasPrefix(c.URI.SpanURI().Filename(), fileProtocol) {
-			diags, err := s.snap.Validate(c.URI.SpanURI())
-			if err != nil {
-				s.log.Debug(errValidateNodes, "error", err)
-				return
+// InlayHint handles calls to InlayHint.
+func (s *Server) InlayHint(ctx context.Context, id jsonrpc2.ID, params *snapshot.InlayHintParams) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	uri := params.TextDocument.URI.SpanURI()
+	snap, ok := s.snapshotFor(uri)
+	if !ok {
+		s.reply(ctx, id, []snapshot.InlayHint{})
+		return
+	}
+	hints, err := snap.InlayHints(uri, params.Range)
+	if err != nil {
+		s.log.Debug(errInlayHints, "error", err)
+		hints = []snapshot.InlayHint{}
+	}
+	s.reply(ctx, id, hints)
+}
+
+// SemanticTokensFull handles calls to SemanticTokensFull.
+func (s *Server) SemanticTokensFull(ctx context.Context, id jsonrpc2.ID, params *protocol.SemanticTokensParams) {
+	s.semanticTokens(ctx, id, params.TextDocument.URI.SpanURI(), nil)
+}
+
+// SemanticTokensRange handles calls to SemanticTokensRange.
+func (s *Server) SemanticTokensRange(ctx context.Context, id jsonrpc2.ID, params *protocol.SemanticTokensRangeParams) {
+	s.semanticTokens(ctx, id, params.TextDocument.URI.SpanURI(), &params.Range)
+}
+
+// semanticTokens replies with the semantic tokens in the supplied file that
+// are within the supplied range, or the entire file if no range is supplied.
+func (s *Server) semanticTokens(ctx context.Context, id jsonrpc2.ID, uri span.URI, rng *protocol.Range) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	empty := &protocol.SemanticTokens{Data: []uint32{}}
+	snap, ok := s.snapshotFor(uri)
+	if !ok {
+		s.reply(ctx, id, empty)
+		return
+	}
+	toks, err := snap.SemanticTokens(uri, rng)
+	if err != nil {
+		s.log.Debug(errSemanticTokens, "error", err)
+		toks = empty
+	}
+	s.reply(ctx, id, toks)
+}
+
+// kindRequested returns true if the supplied code action kind was requested.
+// All kinds are requested if none are supplied.
+func kindRequested(kind protocol.CodeActionKind, only []protocol.CodeActionKind) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, k := range only {
+		if k == kind || strings.HasPrefix(string(kind), string(k)+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// schedule queues the supplied files for revalidation. The queue is processed
+// once nothing further has been scheduled for the debounce interval, such that
+// a burst of edits results in a single round of validation.
+func (s *Server) schedule(ctx context.Context, uris ...span.URI) {
+	s.pmu.Lock()
+	defer s.pmu.Unlock()
+
+	for _, u := range uris {
+		s.pending[u] = struct{}{}
+	}
+	s.resetTimer(ctx)
+}
+
+// scheduleRebuild queues the snapshots of the folders with the supplied roots
+// for rebuilding.
+func (s *Server) scheduleRebuild(ctx context.Context, roots ...span.URI) {
+	s.pmu.Lock()
+	defer s.pmu.Unlock()
+
+	for _, r := range roots {
+		s.rebuild[r] = struct{}{}
+	}
+	s.resetTimer(ctx)
+}
+
+// resetTimer restarts the debounce interval. Callers must hold the lock on
+// scheduled work.
+func (s *Server) resetTimer(ctx context.Context) {
+	if s.shutdown {
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(s.debounce, func() {
+		s.flush(ctx)
+	})
+}
+
+// flush processes the queue of scheduled work, rebuilding snapshots and
+// publishing diagnostics for each file that has been invalidated.
+func (s *Server) flush(ctx context.Context) {
+	s.pmu.Lock()
+	rebuild, pending := s.rebuild, s.pending
+	s.rebuild, s.pending = make(map[span.URI]struct{}), make(map[span.URI]struct{})
+	s.pmu.Unlock()
+
+	for root := range rebuild {
+		s.rebuildSnapshot(ctx, root)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for uri := range pending {
+		if f, ok := s.folderFor(uri); ok {
+			if _, ok := rebuild[f.root]; ok {
+				// diagnostics have already been published.
+				continue
 			}
-			accDiags = append(accDiags, &protocol.PublishDiagnosticsParams{
-				URI:         c.URI,
-				Diagnostics: diags,
-			})
 		}
+		s.publishFile(ctx, uri)
 	}
+}
 
-	// iterate over the list of diagnostics and return individual reports
-	for _, d := range accDiags {
-		s.publishDiagnostics(ctx, d)
+// rebuildSnapshot replaces the snapshot of the folder with the supplied root
+// with a newly parsed one and publishes diagnostics for every file in it.
+func (s *Server) rebuildSnapshot(ctx context.Context, root span.URI) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.folders {
+		if f.root != root {
+			continue
+		}
+		snap, err := f.factory.New()
+		if err != nil {
+			s.log.Debug(errParseWorkspace, "error", err)
+			return
+		}
+		f.snap = snap
+		s.publishFolder(ctx, f)
+	}
+}
+
+// publishFile publishes the diagnostics for the file at the supplied uri.
+// Callers must hold a read lock on the server.
+func (s *Server) publishFile(ctx context.Context, uri span.URI) {
+	snap, ok := s.snapshotFor(uri)
+	if !ok {
+		return
+	}
+	diags, err := snap.Diagnostics(uri)
+	if err != nil {
+		s.log.Debug(errValidateNodes, "error", err)
+		return
+	}
+	s.publishDiagnostics(ctx, &protocol.PublishDiagnosticsParams{
+		URI:         protocol.URIFromSpanURI(uri),
+		Diagnostics: diags,
+	})
+}
+
+// isMetaFile returns true if the supplied uri refers to a package meta file.
+func isMetaFile(uri span.URI) bool {
+	return filepath.Base(uri.Filename()) == xpkg.MetaFile
+}
+
+func (s *Server) reply(ctx context.Context, id jsonrpc2.ID, result any) {
+	if err := s.conn.Reply(ctx, id, result); err != nil {
+		s.log.Debug(errReply, "error", err)
+	}
+}
+
+func (s *Server) replyWithError(ctx context.Context, id jsonrpc2.ID, err error) {
+	if err := s.conn.ReplyWithError(ctx, id, &jsonrpc2.Error{
+		Code:    jsonrpc2.CodeInvalidParams,
+		Message: err.Error(),
+	}); err != nil {
+		s.log.Debug(errReply, "error", err)
 	}
 }
 
@@ -312,57 +871,38 @@ func (s *Server) checkForUpdates(ctx context.Context) {
 
 func (s *Server) checkMetaFile(ctx context.Context) {
 	go func() {
-		uri, diags, err := s.snap.ValidateMeta()
-		if err != nil {
-			s.log.Debug(errValidateMeta, "error", err)
-			return
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		for _, f := range s.folders {
+			uri, diags, err := f.snap.ValidateMeta()
+			if err != nil {
+				s.log.Debug(errValidateMeta, "error", err)
+				continue
+			}
+			s.publishDiagnostics(ctx, &protocol.PublishDiagnosticsParams{
+				URI:         protocol.URIFromSpanURI(uri),
+				Diagnostics: diags,
+			})
 		}
-		s.publishDiagnostics(ctx, &protocol.PublishDiagnosticsParams{
-			URI:         protocol.URIFromSpanURI(uri),
-			Diagnostics: diags,
-		})
 	}()
 }
 
-// // watchSnapshot watches the cache for changes.
-func (s *Server) watchSnapshot(ctx context.Context) { // nolint:gocyclo
-	watch := s.snapFactory.WatchExt()
+// watchSnapshot watches the cache for changes. Bursts of changes, such as
+// those seen when resolving dependencies, result in a single new snapshot for
+// each folder.
+func (s *Server) watchSnapshot(ctx context.Context) {
+	watch := s.m.Watch()
 
 	go func() {
 		for {
 			// TODO(@tnthornton) handle error/close case from cache
 			<-watch
 			s.log.Debug("change seen at cache, processing...")
-			go func() {
-				s.mu.Lock()
-				defer s.mu.Unlock()
-				snap, err := s.snapFactory.New()
-				if err != nil {
-					s.log.Debug(err.Error())
-				}
-				s.snap = snap
-
-				params := make([]*protocol.PublishDiagnosticsParams, 0)
-
-				validations, err := snap.ValidateAllFiles()
-				if err != nil {
-					s.log.Debug(err.Error())
-					return
-				}
-
-				for uri, diags := range validations {
-					params = append(params, &protocol.PublishDiagnosticsParams{
-						URI:         protocol.URIFromSpanURI(uri),
-						Diagnostics: diags,
-					})
-				}
-
-				// TODO(@tnthornton) do we really need to iterate through
-				// this separately from the above loop?
-				for _, p := range params {
-					s.publishDiagnostics(ctx, p)
-				}
-			}()
+			s.mu.RLock()
+			roots := s.roots()
+			s.mu.RUnlock()
+			s.scheduleRebuild(ctx, roots...)
 		}
 	}()
 }
@@ -0,0 +1,392 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/tools/lsp/protocol"
+	"github.com/golang/tools/span"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/upbound/up/internal/xpls/dispatcher"
+)
+
+const testDebounce = 50 * time.Millisecond
+
+var (
+	testXRD = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xnetworks.acme.io
+spec:
+  group: acme.io
+  names:
+    kind: XNetwork
+    plural: xnetworks
+  claimNames:
+    kind: Network
+    plural: networks
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              region:
+                type: string
+`
+
+	testClaim = `apiVersion: acme.io/v1alpha1
+kind: Network
+metadata:
+  name: example
+spec:
+  region: us-west-2
+`
+)
+
+// testClient is a client that records the diagnostics published by the
+// server.
+type testClient struct {
+	conn *jsonrpc2.Conn
+
+	mu    sync.Mutex
+	diags map[span.URI]int
+}
+
+func (c *testClient) Handle(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	if req.Method != "textDocument/publishDiagnostics" || req.Params == nil {
+		return
+	}
+	var params protocol.PublishDiagnosticsParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diags[params.URI.SpanURI()]++
+}
+
+// published returns the number of times diagnostics were published for each
+// file.
+func (c *testClient) published() map[span.URI]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[span.URI]int, len(c.diags))
+	for u, n := range c.diags {
+		out[u] = n
+	}
+	return out
+}
+
+// dispatchHandler handles requests received by the server by dispatching
+// them to it.
+type dispatchHandler struct {
+	d *dispatcher.Dispatcher
+	s *Server
+}
+
+func (h *dispatchHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	h.d.Dispatch(ctx, h.s, conn, req)
+}
+
+// newTestServer returns a server connected to a test client. Each supplied
+// root is a directory holding the supplied files, keyed by path relative to
+// the root, and is added to the server as a workspace folder.
+func newTestServer(t *testing.T, files map[string]string, roots ...string) (*Server, *testClient) {
+	t.Helper()
+
+	s, err := New(WithDebounce(testDebounce))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, root := range roots {
+		writeTestFiles(t, root, files)
+	}
+
+	c := &testClient{diags: map[span.URI]int{}}
+	sc, cc := net.Pipe()
+	s.conn = jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(sc, jsonrpc2.VSCodeObjectCodec{}), &dispatchHandler{d: dispatcher.New(), s: s})
+	c.conn = jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(cc, jsonrpc2.VSCodeObjectCodec{}), c)
+	t.Cleanup(func() {
+		_ = c.conn.Close()
+		_ = s.conn.Close()
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, root := range roots {
+		if _, err := s.addFolder(span.URIFromPath(root)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s, c
+}
+
+// writeTestFiles writes the supplied files, keyed by path relative to the
+// supplied root.
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, body := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// settle waits until scheduled work has been processed.
+func settle() {
+	time.Sleep(5 * testDebounce)
+}
+
+func TestSchedule(t *testing.T) {
+	root := t.TempDir()
+	xrd, claim := span.URIFromPath(filepath.Join(root, "xrd.yaml")), span.URIFromPath(filepath.Join(root, "examples", "claim.yaml"))
+
+	type args struct {
+		burst func(ctx context.Context, s *Server)
+	}
+	type want struct {
+		published map[span.URI]int
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DidChange": {
+			reason: "A burst of changes to a file should result in a single round of validation.",
+			args: args{
+				burst: func(ctx context.Context, s *Server) {
+					for i := 0; i < 5; i++ {
+						s.DidChange(ctx, &protocol.DidChangeTextDocumentParams{
+							TextDocument: protocol.VersionedTextDocumentIdentifier{
+								Version:                int32(i + 1),
+								TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: protocol.URIFromSpanURI(claim)},
+							},
+							ContentChanges: []protocol.TextDocumentContentChangeEvent{{Text: testClaim}},
+						})
+					}
+				},
+			},
+			want: want{
+				published: map[span.URI]int{claim: 1},
+			},
+		},
+		"Rebuild": {
+			reason: "A burst of rebuilds of a folder should result in a single new snapshot.",
+			args: args{
+				burst: func(ctx context.Context, s *Server) {
+					for i := 0; i < 5; i++ {
+						s.scheduleRebuild(ctx, span.URIFromPath(root))
+					}
+				},
+			},
+			want: want{
+				published: map[span.URI]int{xrd: 1, claim: 1},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, c := newTestServer(t, map[string]string{
+				"xrd.yaml":            testXRD,
+				"examples/claim.yaml": testClaim,
+			}, root)
+
+			tc.args.burst(context.Background(), s)
+			settle()
+
+			if diff := cmp.Diff(tc.want.published, c.published()); diff != "" {
+				t.Errorf("\n%s\nschedule(...): -want published, +got published:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInitializeMissingFolder(t *testing.T) {
+	_, c := newTestServer(t, nil)
+
+	params := &protocol.InitializeParams{
+		RootURI: protocol.URIFromSpanURI(span.URIFromPath(filepath.Join(t.TempDir(), "missing"))),
+	}
+	var res any
+	err := c.conn.Call(context.Background(), "initialize", params, &res)
+
+	var rpcErr *jsonrpc2.Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Initialize(...): -want error reply, +got: %v", err)
+	}
+	if diff := cmp.Diff(int64(jsonrpc2.CodeInvalidParams), rpcErr.Code); diff != "" {
+		t.Errorf("\nInitialize(...): -want code, +got code:\n%s", diff)
+	}
+}
+
+func TestDidChangeWorkspaceFolders(t *testing.T) {
+	type args struct {
+		added   []string
+		removed []string
+	}
+	type want struct {
+		roots     []string
+		published []string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Added": {
+			reason: "Adding a folder should serve it and publish diagnostics for its files.",
+			args: args{
+				added: []string{"b"},
+			},
+			want: want{
+				roots:     []string{"a", "b"},
+				published: []string{"b/xrd.yaml", "b/examples/claim.yaml"},
+			},
+		},
+		"Removed": {
+			reason: "Removing a folder should stop serving it and clear diagnostics for its files.",
+			args: args{
+				removed: []string{"a"},
+			},
+			want: want{
+				roots:     []string{},
+				published: []string{"a/xrd.yaml", "a/examples/claim.yaml"},
+			},
+		},
+		"AddedMissing": {
+			reason: "Adding a folder that cannot be parsed should leave the served folders unchanged.",
+			args: args{
+				added: []string{"missing"},
+			},
+			want: want{
+				roots:     []string{"a"},
+				published: []string{},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{
+				"xrd.yaml":            testXRD,
+				"examples/claim.yaml": testClaim,
+			}
+			writeTestFiles(t, filepath.Join(dir, "b"), files)
+			s, c := newTestServer(t, files, filepath.Join(dir, "a"))
+
+			folders := func(paths []string) []protocol.WorkspaceFolder {
+				wfs := make([]protocol.WorkspaceFolder, len(paths))
+				for i, p := range paths {
+					wfs[i] = protocol.WorkspaceFolder{URI: string(span.URIFromPath(filepath.Join(dir, p))), Name: p}
+				}
+				return wfs
+			}
+			s.DidChangeWorkspaceFolders(context.Background(), &protocol.DidChangeWorkspaceFoldersParams{
+				Event: protocol.WorkspaceFoldersChangeEvent{
+					Added:   folders(tc.args.added),
+					Removed: folders(tc.args.removed),
+				},
+			})
+			settle()
+
+			roots := []string{}
+			s.mu.RLock()
+			for _, r := range s.roots() {
+				rel, _ := filepath.Rel(dir, r.Filename())
+				roots = append(roots, rel)
+			}
+			s.mu.RUnlock()
+			if diff := cmp.Diff(tc.want.roots, roots); diff != "" {
+				t.Errorf("\n%s\nDidChangeWorkspaceFolders(...): -want roots, +got roots:\n%s", tc.reason, diff)
+			}
+
+			published := []string{}
+			for u := range c.published() {
+				rel, _ := filepath.Rel(dir, u.Filename())
+				published = append(published, rel)
+			}
+			if diff := cmp.Diff(tc.want.published, published, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("\n%s\nDidChangeWorkspaceFolders(...): -want published, +got published:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExitAfterShutdown(t *testing.T) {
+	s, c := newTestServer(t, nil)
+	ctx := context.Background()
+
+	var res any
+	if err := c.conn.Call(ctx, "shutdown", nil, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !s.ShutdownRequested() {
+		t.Errorf("ShutdownRequested(): -want true, +got false")
+	}
+
+	// requests other than exit are rejected once shutdown is requested.
+	err := c.conn.Call(ctx, "textDocument/hover", &protocol.HoverParams{}, &res)
+	var rpcErr *jsonrpc2.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc2.CodeInvalidRequest {
+		t.Errorf("Hover(...): -want invalid request error, +got: %v", err)
+	}
+
+	if err := c.conn.Notify(ctx, "exit", nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.conn.DisconnectNotify():
+	case <-time.After(time.Second):
+		t.Errorf("Exit(...): -want connection closed, +got connection open")
+	}
+}
+
+func TestInvalidParams(t *testing.T) {
+	_, c := newTestServer(t, nil)
+	ctx := context.Background()
+
+	// requests with parameters that cannot be parsed are replied to with an
+	// error rather than left unanswered.
+	var res any
+	err := c.conn.Call(ctx, "textDocument/hover", "not hover parameters", &res)
+	var rpcErr *jsonrpc2.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc2.CodeInvalidParams {
+		t.Errorf("Hover(...): -want invalid params error, +got: %v", err)
+	}
+}
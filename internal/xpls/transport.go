@@ -14,7 +14,26 @@
 
 package xpls
 
-import "os"
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	tcpPrefix  = "tcp://"
+	unixPrefix = "unix://"
+
+	// loopbackHost is the host that TCP addresses without a host listen on.
+	loopbackHost = "127.0.0.1"
+
+	errSocketExists = "file at socket path exists and is not a socket"
+	errRemoveSocket = "failed to remove stale socket"
+	errParseAddress = "failed to parse listen address"
+	errListenFmt    = "failed to listen on %s"
+)
 
 // StdRWC is a readwritecloser on stdio, which can be used as a JSON-RPC
 // transport.
@@ -37,3 +56,35 @@ func (StdRWC) Close() error {
 	}
 	return os.Stdout.Close()
 }
+
+// Listen announces on the supplied address, which can be used to serve
+// multiple clients. Addresses prefixed with unix:// are unix socket paths, and
+// all others are TCP addresses, optionally prefixed with tcp://. Clients are
+// not authenticated, so TCP addresses without a host, such as :9999, listen on
+// the loopback interface only. A stale unix socket left by a previous server
+// is removed.
+func Listen(address string) (net.Listener, error) {
+	if !strings.HasPrefix(address, unixPrefix) {
+		host, port, err := net.SplitHostPort(strings.TrimPrefix(address, tcpPrefix))
+		if err != nil {
+			return nil, errors.Wrap(err, errParseAddress)
+		}
+		if host == "" {
+			host = loopbackHost
+		}
+		address = net.JoinHostPort(host, port)
+		l, err := net.Listen("tcp", address)
+		return l, errors.Wrapf(err, errListenFmt, address)
+	}
+	path := strings.TrimPrefix(address, unixPrefix)
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, errors.New(errSocketExists)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, errors.Wrap(err, errRemoveSocket)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	return l, errors.Wrapf(err, errListenFmt, address)
+}
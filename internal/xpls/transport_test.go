@@ -0,0 +1,137 @@
+// Copyright 2022 Upbound Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xpls
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestListen(t *testing.T) {
+	type args struct {
+		setup func(t *testing.T, path string)
+	}
+	type want struct {
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoSocket": {
+			reason: "Listening on an unused socket path should succeed.",
+			args: args{
+				setup: func(t *testing.T, path string) {},
+			},
+		},
+		"StaleSocket": {
+			reason: "A stale socket left by a previous server should be removed.",
+			args: args{
+				setup: func(t *testing.T, path string) {
+					l, err := net.Listen("unix", path)
+					if err != nil {
+						t.Fatal(err)
+					}
+					l.(*net.UnixListener).SetUnlinkOnClose(false)
+					if err := l.Close(); err != nil {
+						t.Fatal(err)
+					}
+				},
+			},
+		},
+		"NotASocket": {
+			reason: "A file at the socket path that is not a socket should not be removed.",
+			args: args{
+				setup: func(t *testing.T, path string) {
+					if err := os.WriteFile(path, []byte("keep"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "xpls.sock")
+			tc.args.setup(t, path)
+
+			l, err := Listen(unixPrefix + path)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nListen(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if l != nil {
+				_ = l.Close()
+			}
+		})
+	}
+}
+
+func TestListenTCP(t *testing.T) {
+	type want struct {
+		ip  net.IP
+		err error
+	}
+	cases := map[string]struct {
+		reason  string
+		address string
+		want    want
+	}{
+		"NoHost": {
+			reason:  "A TCP address without a host should only listen on the loopback interface.",
+			address: ":0",
+			want: want{
+				ip: net.ParseIP(loopbackHost),
+			},
+		},
+		"TCPPrefix": {
+			reason:  "A TCP address prefixed with tcp:// without a host should only listen on the loopback interface.",
+			address: tcpPrefix + ":0",
+			want: want{
+				ip: net.ParseIP(loopbackHost),
+			},
+		},
+		"NoPort": {
+			reason:  "A TCP address without a port should be rejected.",
+			address: "localhost",
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l, err := Listen(tc.address)
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nListen(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if l == nil {
+				return
+			}
+			defer l.Close() //nolint:errcheck
+			if diff := cmp.Diff(tc.want.ip.String(), l.Addr().(*net.TCPAddr).IP.String()); diff != "" {
+				t.Errorf("\n%s\nListen(...): -want IP, +got IP:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}